@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+)
+
+func init() {
+	commands["duplicates"] = command{
+		usage: "duplicates [-entity author|work] [-threshold similarity]",
+		run:   duplicatesCommand,
+	}
+}
+
+// duplicatesCommand lists the pairs of authors or works that may be duplicates, most similar first, so that they
+// can be reviewed and merged through the API. It is meant to be run periodically, e.g. from cron.
+func duplicatesCommand(s *services, args []string) error {
+	flags := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	entity := flags.String("entity", "author", "entries to compare: author or work")
+	threshold := flags.Float64("threshold", dedup.DefaultThreshold, "similarity, between 0 and 1, above which to list pairs")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("duplicates takes no arguments, got %d", flags.NArg())
+	}
+	if *threshold <= 0 || *threshold > 1 {
+		return fmt.Errorf("-threshold must be between 0 and 1, got %g", *threshold)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	switch *entity {
+	case "author":
+		stat, candidates := s.dedup.FindAuthorDuplicates(*threshold)
+		if stat.Err() != nil {
+			return stat.Err()
+		}
+		fmt.Fprintln(tw, "SCORE\tID\tAUTHOR\tBORN\tID\tAUTHOR\tBORN")
+		for _, c := range candidates {
+			a, b := c.Authors[0], c.Authors[1]
+			fmt.Fprintf(tw, "%.3f\t%d\t%s %s\t%s\t%d\t%s %s\t%s\n", c.Score,
+				a.ID, a.FirstName, a.LastName, a.DateOfBirth, b.ID, b.FirstName, b.LastName, b.DateOfBirth)
+		}
+	case "work":
+		stat, candidates := s.dedup.FindWorkDuplicates(*threshold)
+		if stat.Err() != nil {
+			return stat.Err()
+		}
+		fmt.Fprintln(tw, "SCORE\tAUTHOR\tID\tTITLE\tID\tTITLE")
+		for _, c := range candidates {
+			a, b := c.Works[0], c.Works[1]
+			fmt.Fprintf(tw, "%.3f\t%d\t%d\t%s\t%d\t%s\n", c.Score, a.Author.ID, a.ID, a.Title, b.ID, b.Title)
+		}
+	default:
+		return fmt.Errorf("-entity must be author or work, got %q", *entity)
+	}
+	return tw.Flush()
+}
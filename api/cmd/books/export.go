@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andrewzulaybar/books/api/pkg/export"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+func init() {
+	commands["export"] = command{
+		usage: "export [-as csv|ndjson|goodreads] [-user id] [-o file]",
+		run:   exportCommand,
+	}
+}
+
+// exportCommand streams every publication, or the shelves of -user, to standard output or -o.
+func exportCommand(s *services, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("as", export.CSV, "format to export in: csv, ndjson or goodreads")
+	userID := flags.Int("user", 0, "export the shelves of the user with this id instead of the publications")
+	out := flags.String("o", "", "file to write to instead of standard output")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("export takes no arguments, got %d", flags.NArg())
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	enc, err := export.NewEncoder(w, *format, *userID != 0)
+	if err != nil {
+		return err
+	}
+	if *userID != 0 {
+		if stat := s.shelf.StreamShelf(*userID, enc.EncodeEntry); stat.Err() != nil {
+			return stat.Err()
+		}
+	} else {
+		if stat := s.publication.StreamPublications(&publication.Filter{}, enc.EncodePublication); stat.Err() != nil {
+			return stat.Err()
+		}
+	}
+	return enc.Flush()
+}
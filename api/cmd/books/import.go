@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/calibre"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/marc"
+	"github.com/andrewzulaybar/books/api/pkg/onix"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+func init() {
+	commands["import-csv"] = command{
+		usage: "import-csv [-commit] [-map header:field ...] <file.csv>",
+		run:   importCSV,
+	}
+	commands["import-goodreads"] = command{
+		usage: "import-goodreads -user id [-commit] <goodreads_library_export.csv>",
+		run:   importGoodreads,
+	}
+	commands["import-calibre"] = command{
+		usage: "import-calibre [-commit] <library directory>",
+		run:   importCalibre,
+	}
+	commands["import-marc"] = command{
+		usage: "import-marc [-commit] <file.mrc|file.xml>",
+		run:   importMARC,
+	}
+	commands["import-onix"] = command{
+		usage: "import-onix [-commit] <feed.xml|feed directory>",
+		run:   importONIX,
+	}
+}
+
+// mappings collects repeated -map flags.
+type mappings []string
+
+func (m *mappings) String() string     { return strings.Join(*m, ",") }
+func (m *mappings) Set(v string) error { *m = append(*m, v); return nil }
+
+func importCSV(s *services, args []string) error {
+	var pairs mappings
+	flags := flag.NewFlagSet("import-csv", flag.ExitOnError)
+	commit := flags.Bool("commit", false, "commit the import instead of performing a dry run")
+	flags.Var(&pairs, "map", "map a CSV header to a field, as header:field (repeatable)")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import-csv expects exactly one file, got %d", flags.NArg())
+	}
+
+	mapping, err := importer.ParseMapping(pairs)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := importer.ParseCSV(file, mapping)
+	if err != nil {
+		return err
+	}
+	return printReport(s.importer.Import(rows, *commit))
+}
+
+func importGoodreads(s *services, args []string) error {
+	flags := flag.NewFlagSet("import-goodreads", flag.ExitOnError)
+	commit := flags.Bool("commit", false, "commit the import instead of performing a dry run")
+	userID := flags.Int("user", 0, "id of the user whose shelves are populated")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import-goodreads expects exactly one file, got %d", flags.NArg())
+	}
+	if *userID == 0 {
+		return fmt.Errorf("import-goodreads requires -user")
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rows, err := importer.ParseGoodreads(file, *userID)
+	if err != nil {
+		return err
+	}
+	return printReport(s.importer.Import(rows, *commit))
+}
+
+func importCalibre(s *services, args []string) error {
+	flags := flag.NewFlagSet("import-calibre", flag.ExitOnError)
+	commit := flags.Bool("commit", false, "commit the import instead of performing a dry run")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import-calibre expects exactly one library directory, got %d", flags.NArg())
+	}
+
+	rows, err := calibre.Read(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	return printReport(s.importer.Import(rows, *commit))
+}
+
+func importMARC(s *services, args []string) error {
+	flags := flag.NewFlagSet("import-marc", flag.ExitOnError)
+	commit := flags.Bool("commit", false, "commit the import instead of performing a dry run")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import-marc expects exactly one file, got %d", flags.NArg())
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := marc.Read(file)
+	if err != nil {
+		return err
+	}
+	return printReport(s.importer.Import(marc.ToRows(records), *commit))
+}
+
+func importONIX(s *services, args []string) error {
+	flags := flag.NewFlagSet("import-onix", flag.ExitOnError)
+	commit := flags.Bool("commit", false, "commit the import instead of performing a dry run")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import-onix expects exactly one file or directory, got %d", flags.NArg())
+	}
+
+	path := flags.Arg(0)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		rows, err := onix.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		return printReport(s.importer.Import(rows, *commit))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	products, err := onix.Read(file)
+	if err != nil {
+		return err
+	}
+	return printReport(s.importer.Import(onix.ToRows(products, filepath.Base(path)), *commit))
+}
+
+func printReport(s *status.Status, report *importer.Report) error {
+	if report == nil {
+		return s.Err()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	return s.Err()
+}
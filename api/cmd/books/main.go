@@ -0,0 +1,112 @@
+// Command books runs imports, exports and other maintenance tasks against the catalogue
+// database configured in config/.env. Unlike the API server, it never re-initializes the database.
+//
+// Usage:
+//
+//	books <command> [flags] [arguments]
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/andrewzulaybar/books/api/config"
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// services contains one instance of each service a command may need.
+type services struct {
+	location    *location.Service
+	author      *author.Service
+	work        *work.Service
+	publication *publication.Service
+	genre       *genre.Service
+	shelf       *shelf.Service
+	importer    *importer.Service
+	idempotency *idempotency.Service
+	dedup       *dedup.Service
+}
+
+// command is a subcommand of books.
+type command struct {
+	usage string
+	run   func(s *services, args []string) error
+}
+
+var commands = map[string]command{}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "books: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	conf, err := config.Load(".env")
+	if err != nil {
+		fatal(err)
+	}
+
+	db := new(postgres.DB)
+	if err := db.Connect(conf.ConnectionString); err != nil {
+		fatal(err)
+	}
+	defer db.Disconnect()
+
+	l := &location.Service{DB: *db}
+	a := &author.Service{DB: *db, LocationService: *l}
+	w := &work.Service{DB: *db, AuthorService: *a}
+	p := &publication.Service{DB: *db, WorkService: *w}
+	g := &genre.Service{DB: *db}
+	sh := &shelf.Service{DB: *db}
+	s := &services{
+		location:    l,
+		author:      a,
+		work:        w,
+		publication: p,
+		genre:       g,
+		shelf:       sh,
+		importer:    &importer.Service{DB: *db, PublicationService: *p, GenreService: *g, ShelfService: *sh},
+		idempotency: &idempotency.Service{DB: *db},
+		dedup:       &dedup.Service{DB: *db, PublicationService: *p, RedirectService: redirect.Service{DB: *db}},
+	}
+
+	if err := cmd.run(s, os.Args[2:]); err != nil {
+		fatal(err)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: books <command> [flags] [arguments]\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", commands[name].usage)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "books: %s\n", err)
+	os.Exit(1)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+)
+
+func init() {
+	commands["purge"] = command{
+		usage: "purge [-older-than duration]",
+		run:   purgeCommand,
+	}
+}
+
+// defaultRetention is how long deleted entries are kept, so that they can be restored, before they are purged.
+const defaultRetention = 30 * 24 * time.Hour
+
+// purgeCommand permanently removes the publications, works and authors deleted longer ago than -older-than, along
+// with the idempotency keys that have expired. It is meant to be run periodically, e.g. from cron.
+func purgeCommand(s *services, args []string) error {
+	flags := flag.NewFlagSet("purge", flag.ExitOnError)
+	retention := flags.Duration("older-than", defaultRetention, "purge entries deleted longer ago than this")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("purge takes no arguments, got %d", flags.NArg())
+	}
+	if *retention < 0 {
+		return fmt.Errorf("-older-than must not be negative, got %s", *retention)
+	}
+	before := time.Now().Add(-*retention)
+
+	// Publications go first and authors last, since each refers to the one after it.
+	stat, pubs := s.publication.PurgePublications(before)
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+	stat, works := s.work.PurgeWorks(before)
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+	stat, authors := s.author.PurgeAuthors(before)
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+
+	stat, keys := s.idempotency.PurgeKeys(time.Now().Add(-idempotency.TTL))
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+
+	fmt.Printf("Purged %d publications, %d works and %d authors deleted before %s, and %d expired idempotency keys\n",
+		pubs, works, authors, before.Format(time.RFC3339), keys)
+	return nil
+}
@@ -11,6 +11,17 @@ import (
 type Config struct {
 	ConnectionString string
 	Address          string
+
+	// GRPCAddress is where the gRPC API is served, alongside the REST API; it is not served when empty.
+	GRPCAddress string
+
+	// Base URLs of the metadata providers, which default to the public APIs when empty.
+	OpenLibraryURL string
+	GoogleBooksURL string
+	GoogleBooksKey string
+
+	// AdminToken is the bearer token required by the admin routes, which are disabled when it is empty.
+	AdminToken string
 }
 
 // Load returns the environment variables set in the given file.
@@ -26,5 +37,10 @@ func Load(fileName string) (*Config, error) {
 	return &Config{
 		ConnectionString: env["CONNECTION_STRING"],
 		Address:          env["ADDRESS"],
+		GRPCAddress:      env["GRPC_ADDRESS"],
+		OpenLibraryURL:   env["OPENLIBRARY_URL"],
+		GoogleBooksURL:   env["GOOGLE_BOOKS_URL"],
+		GoogleBooksKey:   env["GOOGLE_BOOKS_KEY"],
+		AdminToken:       env["ADMIN_TOKEN"],
 	}, nil
 }
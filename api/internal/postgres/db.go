@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"database/sql"
+	"fmt"
 	"io/ioutil"
 	"path"
 	"runtime"
@@ -13,16 +14,40 @@ var tables []string = []string{
 	"location",
 	"account_user",
 	"author",
+	"author_alias",
 	"work",
 	"publication",
+	"shelf",
+	"genre",
+	"revision",
+	"audit",
+	"idempotency",
+	"redirect",
 }
 
 // DB wraps our SQL database.
-type DB struct{ *sql.DB }
+type DB struct {
+	*sql.DB
+
+	tx    *sql.Tx
+	actor string
+}
 
 // Query is used together with the Service.Query method to retrieve pre-defined queries.
 type Query int
 
+// Page appends a LIMIT and OFFSET to the given query, which should be ordered, so that it skips the first offset of
+// its rows and returns at most limit of the others, or all of them if limit is negative.
+func Page(query string, limit int, offset int) string {
+	if limit >= 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return query
+}
+
 // Connect creates a pool of connections to the database and initializes the db on the receiver.
 func (db *DB) Connect(params string) error {
 	database, err := sql.Open("postgres", params)
@@ -43,6 +68,80 @@ func (db *DB) Disconnect() error {
 	return db.Close()
 }
 
+// WithTx returns a copy of the receiver whose statements are executed inside the given transaction.
+func (db DB) WithTx(tx *sql.Tx) DB {
+	db.tx = tx
+	return db
+}
+
+// InTx returns whether the receiver's statements are executed inside a transaction.
+func (db DB) InTx() bool {
+	return db.tx != nil
+}
+
+// Transaction calls fn with a transaction that is committed if fn succeeds and rolled back otherwise. If the receiver
+// is already inside a transaction, fn is given that one instead, and only what fn did is rolled back if it fails, so
+// that the transaction remains usable, e.g. by a caller that handles a conflict.
+func (db DB) Transaction(fn func(*sql.Tx) error) error {
+	if db.tx != nil {
+		if _, err := db.tx.Exec("SAVEPOINT nested"); err != nil {
+			return err
+		}
+		if err := fn(db.tx); err != nil {
+			db.tx.Exec("ROLLBACK TO SAVEPOINT nested")
+			return err
+		}
+		_, err := db.tx.Exec("RELEASE SAVEPOINT nested")
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// WithActor returns a copy of the receiver whose changes are recorded in the history as made by actor.
+func (db DB) WithActor(actor string) DB {
+	db.actor = actor
+	return db
+}
+
+// Actor returns who the changes made through the receiver are made by, if known.
+func (db DB) Actor() string {
+	return db.actor
+}
+
+// Exec executes a query without returning any rows, inside the receiver's transaction if it has one.
+func (db DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if db.tx != nil {
+		return db.tx.Exec(query, args...)
+	}
+	return db.DB.Exec(query, args...)
+}
+
+// Query executes a query that returns rows, inside the receiver's transaction if it has one.
+func (db DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	if db.tx != nil {
+		return db.tx.Query(query, args...)
+	}
+	return db.DB.Query(query, args...)
+}
+
+// QueryRow executes a query that returns at most one row, inside the receiver's transaction if it has one.
+func (db DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	if db.tx != nil {
+		return db.tx.QueryRow(query, args...)
+	}
+	return db.DB.QueryRow(query, args...)
+}
+
 // Init creates tables and populates them with data.
 func (db *DB) Init() error {
 	for _, filePath := range getFilePaths() {
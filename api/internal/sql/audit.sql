@@ -0,0 +1,28 @@
+-- The audit log is kept across restarts, unlike the tables that init.sql drops.
+CREATE TABLE IF NOT EXISTS audit
+(
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor VARCHAR (100) NOT NULL,
+    ip VARCHAR (45) NOT NULL,
+    method VARCHAR (10) NOT NULL,
+    route VARCHAR (2000) NOT NULL,
+    entity VARCHAR (20) NOT NULL,
+    entity_ids INTEGER[] NOT NULL,
+    status INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_created_at_idx ON audit (created_at);
+CREATE INDEX IF NOT EXISTS audit_entity_ids_idx ON audit USING GIN (entity_ids);
+
+-- The audit log is append-only: its entries can be neither changed nor removed.
+CREATE OR REPLACE FUNCTION audit_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'the audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_append_only ON audit;
+CREATE TRIGGER audit_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_append_only();
@@ -6,6 +6,12 @@ CREATE TABLE author
     gender VARCHAR (1) NOT NULL,
     date_of_birth DATE NOT NULL,
     place_of_birth INTEGER NOT NULL,
-    UNIQUE (first_name, last_name, date_of_birth),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ,
     FOREIGN KEY (place_of_birth) REFERENCES location (id)
 );
+
+-- Deleted authors are kept until they are purged, but do not stop others from taking their place.
+CREATE UNIQUE INDEX author_first_name_last_name_date_of_birth_key ON author (first_name, last_name, date_of_birth)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE author_alias
+(
+    id SERIAL PRIMARY KEY,
+    author_id INTEGER NOT NULL,
+    first_name VARCHAR (100) NOT NULL,
+    last_name VARCHAR (100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (first_name, last_name),
+    FOREIGN KEY (author_id) REFERENCES author (id) ON DELETE CASCADE
+);
+
+CREATE INDEX author_alias_author_id_idx ON author_alias (author_id);
@@ -0,0 +1,8 @@
+CREATE TABLE genre
+(
+    id SERIAL PRIMARY KEY,
+    work_id INTEGER NOT NULL,
+    name VARCHAR (100) NOT NULL,
+    UNIQUE (work_id, name),
+    FOREIGN KEY (work_id) REFERENCES work (id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+CREATE TABLE idempotency
+(
+    actor VARCHAR (100) NOT NULL,
+    key VARCHAR (255) NOT NULL,
+    fingerprint CHAR (64) NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    content_type VARCHAR (100) NOT NULL DEFAULT '',
+    etag VARCHAR (100) NOT NULL DEFAULT '',
+    location VARCHAR (2000) NOT NULL DEFAULT '',
+    body BYTEA NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (actor, key)
+);
+
+CREATE INDEX idempotency_created_at_idx ON idempotency (created_at);
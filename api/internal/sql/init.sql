@@ -1 +1 @@
-DROP TABLE IF EXISTS location, account_user, author, publication, work;
+DROP TABLE IF EXISTS location, account_user, author, author_alias, publication, work, shelf, genre, revision, idempotency, redirect;
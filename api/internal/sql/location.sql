@@ -4,6 +4,7 @@ CREATE TABLE location
     city VARCHAR (100) NOT NULL,
     country VARCHAR (100) NOT NULL,
     region VARCHAR (100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     UNIQUE (city, country)
 );
-
@@ -3,12 +3,22 @@ CREATE TABLE publication
     id SERIAL PRIMARY KEY,
     edition_pub_date DATE NOT NULL,
     format VARCHAR (30) NOT NULL,
-    image_url VARCHAR (300) UNIQUE NOT NULL,
-    isbn VARCHAR (10) UNIQUE NOT NULL,
-    isbn13 VARCHAR (13) UNIQUE NOT NULL,
+    image_url VARCHAR (300) NOT NULL,
+    isbn VARCHAR (10) NOT NULL,
+    isbn13 VARCHAR (13) NOT NULL,
     language VARCHAR (100) NOT NULL,
     num_pages INTEGER NOT NULL,
     publisher VARCHAR (100) NOT NULL,
+    edition VARCHAR (100) NOT NULL DEFAULT '',
     work_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ,
     FOREIGN KEY (work_id) REFERENCES work (id) ON DELETE CASCADE
 );
+
+-- Deleted publications are kept until they are purged, but do not stop others from taking their place.
+CREATE UNIQUE INDEX publication_image_url_key ON publication (image_url) WHERE deleted_at IS NULL;
+-- Publications whose ISBN-13 has no ISBN-10 equivalent have an empty isbn.
+CREATE UNIQUE INDEX publication_isbn_key ON publication (isbn) WHERE deleted_at IS NULL AND isbn <> '';
+CREATE UNIQUE INDEX publication_isbn13_key ON publication (isbn13) WHERE deleted_at IS NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE redirect
+(
+    entity VARCHAR (20) NOT NULL,
+    old_id INTEGER NOT NULL,
+    new_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (entity, old_id)
+);
+
+-- Redirects outlive the entries merged away, so neither id references them.
+CREATE INDEX redirect_entity_new_id_idx ON redirect (entity, new_id);
@@ -0,0 +1,14 @@
+CREATE TABLE revision
+(
+    id SERIAL PRIMARY KEY,
+    entity VARCHAR (20) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    action VARCHAR (10) NOT NULL,
+    actor VARCHAR (100) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    before JSONB,
+    after JSONB
+);
+
+-- Revisions outlive the entries they describe, so entity_id does not reference them.
+CREATE INDEX revision_entity_entity_id_idx ON revision (entity, entity_id);
@@ -0,0 +1,14 @@
+CREATE TABLE shelf
+(
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    publication_id INTEGER NOT NULL,
+    exclusive_shelf VARCHAR (30) NOT NULL,
+    rating INTEGER NOT NULL DEFAULT 0,
+    date_read DATE,
+    bookshelves VARCHAR (100)[] NOT NULL DEFAULT '{}',
+    UNIQUE (user_id, publication_id),
+    CHECK (rating BETWEEN 0 AND 5),
+    FOREIGN KEY (user_id) REFERENCES account_user (id) ON DELETE CASCADE,
+    FOREIGN KEY (publication_id) REFERENCES publication (id) ON DELETE CASCADE
+);
@@ -6,6 +6,13 @@ CREATE TABLE work
     initial_pub_date DATE NOT NULL,
     original_language VARCHAR (100) NOT NULL,
     title VARCHAR (200) NOT NULL,
-    UNIQUE (author_id, title),
+    series VARCHAR (200) NOT NULL DEFAULT '',
+    series_index REAL NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at TIMESTAMPTZ,
     FOREIGN KEY (author_id) REFERENCES author (id)
 );
+
+-- Deleted works are kept until they are purged, but do not stop others from taking their place.
+CREATE UNIQUE INDEX work_author_id_title_key ON work (author_id, title) WHERE deleted_at IS NULL;
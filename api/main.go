@@ -2,19 +2,32 @@ package main
 
 import (
 	"log"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/andrewzulaybar/books/api/config"
 	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/audit"
 	"github.com/andrewzulaybar/books/api/pkg/author"
-	"github.com/andrewzulaybar/books/api/pkg/handlers"
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/catalog"
+	"github.com/andrewzulaybar/books/api/pkg/catalog/catalogpb"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
 	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
 	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/user"
 	"github.com/andrewzulaybar/books/api/pkg/work"
 	"github.com/andrewzulaybar/books/api/test/data"
 	h "github.com/gorilla/handlers"
-	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -30,23 +43,51 @@ func main() {
 	a := &author.Service{DB: *db, LocationService: *l}
 	w := &work.Service{DB: *db, AuthorService: *a}
 	p := &publication.Service{DB: *db, WorkService: *w}
+	g := &genre.Service{DB: *db}
+	sh := &shelf.Service{DB: *db}
+	i := &importer.Service{DB: *db, PublicationService: *p, GenreService: *g, ShelfService: *sh}
+	gq := &graph.Service{AuthorService: *a, WorkService: *w, PublicationService: *p}
+	m := &metadata.Service{Providers: []metadata.Provider{
+		&metadata.OpenLibrary{BaseURL: conf.OpenLibraryURL},
+		&metadata.GoogleBooks{BaseURL: conf.GoogleBooksURL, Key: conf.GoogleBooksKey},
+	}}
+	au := &audit.Service{DB: *db}
+	id := &idempotency.Service{DB: *db}
+	rd := &redirect.Service{DB: *db}
+	us := &user.Service{DB: *db}
+	d := &dedup.Service{DB: *db, PublicationService: *p, RedirectService: *rd}
 	data.LoadPublications(p)
 
-	r := mux.NewRouter()
-	API := r.PathPrefix("/api").Subrouter()
-
-	API.HandleFunc("/publication", handlers.Publications(p)).
-		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
-	API.HandleFunc("/publication/{id:[0-9]+}", handlers.Publication(p)).
-		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
-	API.HandleFunc("/work", handlers.Works(w)).
-		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
-	API.HandleFunc("/work/{id:[0-9]+}", handlers.Work(w)).
-		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
-	API.HandleFunc("/author", handlers.Authors(a)).
-		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
-	API.HandleFunc("/author/{id:[0-9]+}", handlers.Author(a)).
-		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
+	r := newRouter(&services{
+		author:       a,
+		work:         w,
+		publication:  p,
+		genre:        g,
+		shelf:        sh,
+		importer:     i,
+		graph:        gq,
+		metadata:     m,
+		audit:        au,
+		idempotency:  id,
+		dedup:        d,
+		redirect:     rd,
+		bibliography: &bibliography.Service{DB: *db, WorkService: *w},
+		user:         us,
+		adminToken:   conf.AdminToken,
+	})
+
+	if conf.GRPCAddress != "" {
+		lis, err := net.Listen("tcp", conf.GRPCAddress)
+		if err != nil {
+			panic(err)
+		}
+		gs := grpc.NewServer(
+			grpc.ChainUnaryInterceptor(catalog.UnaryAuthenticator(us, conf.AdminToken), catalog.UnaryAudited(au)),
+			grpc.StreamInterceptor(catalog.StreamAuthenticator(us, conf.AdminToken)),
+		)
+		catalogpb.RegisterCatalogServiceServer(gs, &catalog.Server{AuthorService: *a, WorkService: *w, PublicationService: *p})
+		go func() { log.Fatal(gs.Serve(lis)) }()
+	}
 
 	srv := &http.Server{
 		Handler:      h.CORS()(r),
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/lib/pq"
+)
+
+// Columns is the comma-separated list of columns found in the audit table.
+const Columns string = "created_at, actor, ip, method, route, entity, entity_ids, status"
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	GetEntries
+	PostEntry
+)
+
+// An Entry represents a request that may have changed the catalogue: who made it and from where, what it
+// was made to, and how it turned out.
+type Entry struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`
+	IP        string    `json:"ip"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Entity    string    `json:"entity"`
+	EntityIDs []int     `json:"entityIds"`
+	Status    int       `json:"status"`
+}
+
+// Entries represents a list of entries.
+type Entries []Entry
+
+// Filter restricts the entries retrieved by GetEntries. Zero-valued fields are ignored. Entries are ordered
+// newest first, and Limit and Offset select a page of them.
+type Filter struct {
+	Actor    string
+	Entity   string
+	EntityID int
+	From     time.Time
+	To       time.Time
+
+	Limit  int
+	Offset int
+}
+
+// Where returns the SQL condition matching the receiver's fields, along with its arguments.
+func (f *Filter) Where() (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.Entity != "" {
+		add("entity = $%d", f.Entity)
+	}
+	if f.EntityID != 0 {
+		add("$%d = ANY(entity_ids)", f.EntityID)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// Page returns the ORDER BY, LIMIT and OFFSET clauses of the receiver.
+func (f *Filter) Page() string {
+	page := "ORDER BY id DESC"
+	if f.Limit > 0 {
+		page += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	if f.Offset > 0 {
+		page += fmt.Sprintf(" OFFSET %d", f.Offset)
+	}
+	return page
+}
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case GetEntries:
+		return fmt.Sprintf("SELECT id, %s FROM audit WHERE %s %s", Columns, args[0], args[1])
+	case PostEntry:
+		return fmt.Sprintf(
+			`INSERT INTO audit (%s)
+                        VALUES (now(), $1, $2, $3, $4, $5, $6, $7)`,
+			Columns,
+		)
+	default:
+		return ""
+	}
+}
+
+// GetEntries retrieves the entries matching the given filter from the database.
+func (s *Service) GetEntries(f *Filter) (*status.Status, Entries) {
+	db := s.DB
+	where, args := f.Where()
+	getEntries := s.Query(GetEntries, where, f.Page())
+
+	rows, err := db.Query(getEntries, args...)
+	if err != nil {
+		log.Printf("[GetEntries] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	entries := Entries{}
+	for rows.Next() {
+		var e Entry
+		var ids pq.Int64Array
+		if err := rows.Scan(
+			&e.ID, &e.Timestamp, &e.Actor, &e.IP, &e.Method, &e.Route, &e.Entity, &ids, &e.Status,
+		); err != nil {
+			log.Printf("[GetEntries] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		e.EntityIDs = make([]int, len(ids))
+		for i, id := range ids {
+			e.EntityIDs[i] = int(id)
+		}
+		entries = append(entries, e)
+	}
+	return status.New(status.OK, ""), entries
+}
+
+// PostEntry appends the given entry to the audit log. Entries can never be changed or removed afterwards.
+func (s *Service) PostEntry(e *Entry) *status.Status {
+	db := s.DB
+	postEntry := s.Query(PostEntry)
+
+	ids := e.EntityIDs
+	if ids == nil {
+		ids = []int{}
+	}
+	if _, err := db.Exec(
+		postEntry, e.Actor, e.IP, e.Method, e.Route, e.Entity, pq.Array(ids), e.Status,
+	); err != nil {
+		log.Printf("[PostEntry] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.Created, "")
+}
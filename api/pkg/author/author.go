@@ -2,28 +2,51 @@ package author
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/history"
 	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/lib/pq"
 )
 
+// Entity is the name that the changes made to authors are recorded under in the history.
+const Entity string = "author"
+
 // Columns is the comma-separated list of columns found in the author table.
 const Columns string = "first_name, last_name, gender, date_of_birth, place_of_birth"
 
+// Fields describes the fields of an author that can be patched.
+var Fields = patch.Fields{
+	"firstName":    {Column: "first_name", Required: true},
+	"lastName":     {Column: "last_name", Required: true},
+	"gender":       {Column: "gender"},
+	"dateOfBirth":  {Column: "date_of_birth", Required: true},
+	"placeOfBirth": {Column: "place_of_birth", Required: true, Embedded: true},
+}
+
 // Enum constants representing types of SQL statements.
 const (
 	Unknown postgres.Query = iota
+	DeleteAlias
 	DeleteAuthor
 	FindAuthor
+	GetAliases
 	GetAuthor
 	GetAuthors
+	GetAuthorsPage
+	LastModified
+	LockAuthor
 	PatchAuthor
+	PostAlias
 	PostAuthor
+	PurgeAuthors
+	RestoreAuthor
 )
 
 // Author represents a writer of a work.
@@ -39,6 +62,16 @@ type Author struct {
 // Authors represents a list of authors.
 type Authors []Author
 
+// An Alias is another name that an author has written under, such as a pen name.
+type Alias struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+// Aliases represents a list of aliases.
+type Aliases []Alias
+
 // Service wraps the database and other dependencies.
 type Service struct {
 	DB postgres.DB
@@ -46,27 +79,51 @@ type Service struct {
 	LocationService location.Service
 }
 
+// WithTx returns a copy of the receiver whose statements, and those of its dependencies,
+// are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx), LocationService: *s.LocationService.WithTx(tx)}
+}
+
+// WithActor returns a copy of the receiver whose changes, and those of its dependencies,
+// are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{DB: s.DB.WithActor(actor), LocationService: *s.LocationService.WithActor(actor)}
+}
+
 // Query returns a SQL statement based on the postgres.Query value passed in.
 func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 	switch query {
+	case DeleteAlias:
+		return "DELETE FROM author_alias WHERE id = $1 AND author_id = $2"
 	case DeleteAuthor:
-		return "DELETE FROM author WHERE id = $1"
+		return `UPDATE author SET deleted_at = now(), updated_at = now()
+                        WHERE id = $1 AND deleted_at IS NULL
+                        AND NOT EXISTS (SELECT 1 FROM work WHERE author_id = $1 AND deleted_at IS NULL)`
 	case FindAuthor:
-		firstName := args[0].(string)
-		lastName := args[1].(string)
-		dateOfBirth := args[2].(string)
+		// An author found by name and date of birth comes before one who has written under the name. An empty
+		// date of birth matches any.
 		return fmt.Sprintf(
 			`SELECT id, %s
                         FROM author
-                        WHERE first_name = '%s' AND last_name = '%s' AND date_of_birth = '%s'`,
-			Columns, firstName, lastName, dateOfBirth,
+                        WHERE deleted_at IS NULL
+                        AND ((first_name = $1 AND last_name = $2 AND date_of_birth = coalesce(NULLIF($3, '')::date, date_of_birth))
+                        OR id = (SELECT author_id FROM author_alias WHERE first_name = $1 AND last_name = $2))
+                        ORDER BY (first_name = $1 AND last_name = $2 AND date_of_birth = coalesce(NULLIF($3, '')::date, date_of_birth)) DESC, id
+                        LIMIT 1`,
+			Columns,
 		)
+	case GetAliases:
+		return `SELECT id, first_name, last_name
+                        FROM author_alias
+                        WHERE author_id = $1
+                        ORDER BY last_name, first_name`
 	case GetAuthor:
 		return fmt.Sprintf(
 			`SELECT author.id, %s, %s
                         FROM author
                         JOIN location ON author.place_of_birth=location.id
-                        WHERE author.id = $1`,
+                        WHERE author.id = $1 AND author.deleted_at IS NULL`,
 			Columns,
 			location.Columns,
 		)
@@ -74,67 +131,90 @@ func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 		return fmt.Sprintf(
 			`SELECT author.id, %s, %s
                         FROM author
-                        JOIN location ON author.place_of_birth=location.id`,
+                        JOIN location ON author.place_of_birth=location.id
+                        WHERE author.deleted_at IS NULL`,
 			Columns,
 			location.Columns,
 		)
+	case GetAuthorsPage:
+		return postgres.Page(s.Query(GetAuthors)+" ORDER BY author.id", args[0].(int), args[1].(int))
+	case LastModified:
+		return `SELECT max(updated_at)
+                        FROM author
+                        WHERE cardinality($1::integer[]) = 0 OR id = ANY($1)`
+	case LockAuthor:
+		return "SELECT id FROM author WHERE id = $1 FOR UPDATE"
 	case PatchAuthor:
-		var hasUpdate bool
-		query := "UPDATE author SET "
-		for column, value := range args[0].(map[string]interface{}) {
-			switch value.(type) {
-			case string:
-				if value != "" {
-					query += fmt.Sprintf("%s = '%s',", column, value)
-					hasUpdate = true
-				}
-			case int:
-				if value != 0 {
-					query += fmt.Sprintf("%s = %d,", column, value)
-					hasUpdate = true
-				}
-			}
-		}
-		if hasUpdate {
-			return strings.TrimSuffix(query, ",") + fmt.Sprintf(" WHERE id = $1 RETURNING id, %s", Columns)
+		values := args[0].(map[string]interface{})
+		if len(values) == 0 {
+			return ""
 		}
-		return ""
+		return fmt.Sprintf(
+			"UPDATE author SET %s, updated_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id, %s",
+			patch.Assignments(values),
+			Columns,
+		)
+	case PostAlias:
+		return `INSERT INTO author_alias (author_id, first_name, last_name)
+                        SELECT id, $2, $3 FROM author WHERE id = $1 AND deleted_at IS NULL
+                        RETURNING id, first_name, last_name`
 	case PostAuthor:
 		return fmt.Sprintf(
 			`INSERT INTO author (%s)
                         VALUES ($1, $2, $3, $4, $5)
-                        RETURNING *`,
+                        RETURNING id, %s`,
+			Columns,
 			Columns,
 		)
+	case PurgeAuthors:
+		// Authors are only purged once their works are, since the works refer to them.
+		return `DELETE FROM author
+                        WHERE deleted_at < $1
+                        AND NOT EXISTS (SELECT 1 FROM work WHERE work.author_id = author.id)`
+	case RestoreAuthor:
+		return "UPDATE author SET deleted_at = NULL, updated_at = now() WHERE id = $1 AND deleted_at IS NOT NULL"
 	default:
 		return ""
 	}
 }
 
-// DeleteAuthor removes the entry in the author table matching the given id.
-func (s *Service) DeleteAuthor(id int) *status.Status {
+// DeleteAlias removes the alias matching aliasID from the author matching authorID.
+func (s *Service) DeleteAlias(authorID int, aliasID int) *status.Status {
 	db := s.DB
-	deleteAuthor := s.Query(DeleteAuthor)
+	deleteAlias := s.Query(DeleteAlias)
 
-	res, err := db.Exec(deleteAuthor, id)
+	res, err := db.Exec(deleteAlias, aliasID, authorID)
 	if err != nil {
-		log.Printf("[DeleteAuthor] %s", err)
+		log.Printf("[DeleteAlias] %s", err)
 		return status.New(status.InternalServerError, err.Error())
 	}
 
 	numDeleted, err := res.RowsAffected()
 	if err != nil {
-		log.Printf("[DeleteAuthor] %s", err)
+		log.Printf("[DeleteAlias] %s", err)
 		return status.New(status.InternalServerError, err.Error())
 	}
 	if numDeleted == 0 {
-		msg := fmt.Sprintf("Author with id = %d does not exist", id)
-		log.Printf("[DeleteAuthor] %s", msg)
-		return status.New(status.OK, msg)
+		msg := fmt.Sprintf("Alias with id = %d of author with id = %d does not exist", aliasID, authorID)
+		log.Printf("[DeleteAlias] %s", msg)
+		return status.New(status.NotFound, msg)
 	}
 	return status.New(status.NoContent, "")
 }
 
+// DeleteAuthor marks the entry in the author table matching the given id as deleted. It is kept until it is
+// purged, and can be restored in the meantime. Authors cannot be deleted while they have works.
+func (s *Service) DeleteAuthor(id int) *status.Status {
+	return s.Locked(id, func(s *Service) *status.Status {
+		_, before := s.GetAuthor(id)
+		stat := s.deleteAuthor(id)
+		if stat.Code() != status.NoContent {
+			return stat
+		}
+		return s.record(history.Delete, id, before, nil, stat)
+	})
+}
+
 // DeleteAuthors removes the entries in the author table matching the given ids.
 func (s *Service) DeleteAuthors(ids []int) (*status.Status, []int) {
 	notFound := []int{}
@@ -152,13 +232,15 @@ func (s *Service) DeleteAuthors(ids []int) (*status.Status, []int) {
 	return status.New(status.NoContent, ""), nil
 }
 
-// FindAuthor retrieves the author from the database matching the given firstName, lastName, and dateOfBirth.
+// FindAuthor retrieves the author from the database matching the given firstName, lastName, and dateOfBirth, or
+// failing that, the author with an alias matching firstName and lastName, whatever dateOfBirth is. If dateOfBirth is
+// empty, i.e. unknown, the author is matched by name alone.
 func (s *Service) FindAuthor(firstName string, lastName string, dateOfBirth string) (*status.Status, *Author) {
 	db := s.DB
-	findAuthor := s.Query(FindAuthor, firstName, lastName, dateOfBirth)
+	findAuthor := s.Query(FindAuthor)
 
 	var au Author
-	row := db.QueryRow(findAuthor)
+	row := db.QueryRow(findAuthor, firstName, lastName, dateOfBirth)
 	if err := row.Scan(
 		&au.ID, &au.FirstName, &au.LastName, &au.Gender, &au.DateOfBirth, &au.PlaceOfBirth.ID,
 	); err != nil {
@@ -173,6 +255,34 @@ func (s *Service) FindAuthor(firstName string, lastName string, dateOfBirth stri
 	return status.New(status.OK, ""), &au
 }
 
+// GetAliases retrieves the aliases of the author matching the given id, in alphabetical order.
+func (s *Service) GetAliases(id int) (*status.Status, Aliases) {
+	if stat, _ := s.GetAuthor(id); stat.Err() != nil {
+		return stat, nil
+	}
+
+	db := s.DB
+	getAliases := s.Query(GetAliases)
+
+	rows, err := db.Query(getAliases, id)
+	if err != nil {
+		log.Printf("[GetAliases] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	aliases := Aliases{}
+	for rows.Next() {
+		var alias Alias
+		if err := rows.Scan(&alias.ID, &alias.FirstName, &alias.LastName); err != nil {
+			log.Printf("[GetAliases] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		aliases = append(aliases, alias)
+	}
+	return status.New(status.OK, ""), aliases
+}
+
 // GetAuthor retrieves the author from the database matching the given id.
 func (s *Service) GetAuthor(id int) (*status.Status, *Author) {
 	db := s.DB
@@ -215,8 +325,274 @@ func (s *Service) GetAuthors() (*status.Status, Authors) {
 	return status.New(status.OK, ""), authors
 }
 
-// PatchAuthor updates the entry in the database matching author.id with the given attributes.
-func (s *Service) PatchAuthor(author *Author) (*status.Status, *Author) {
+// GetAuthorsPage retrieves the authors ordered by id, skipping the first offset of them and returning at most limit,
+// or every remaining one if limit is negative.
+func (s *Service) GetAuthorsPage(limit int, offset int) (*status.Status, Authors) {
+	db := s.DB
+	getAuthorsPage := s.Query(GetAuthorsPage, limit, offset)
+
+	rows, err := db.Query(getAuthorsPage)
+	if err != nil {
+		log.Printf("[GetAuthorsPage] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	authors := Authors{}
+	for rows.Next() {
+		author, err := s.getAuthor(rows)
+		if err != nil {
+			log.Printf("[GetAuthorsPage] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		authors = append(authors, *author)
+	}
+	return status.New(status.OK, ""), authors
+}
+
+// GetHistory retrieves the revisions of the author matching the given id, oldest first.
+func (s *Service) GetHistory(id int) (*status.Status, history.Revisions) {
+	hs := &history.Service{DB: s.DB}
+	stat, revisions := hs.GetRevisions(Entity, id)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	if len(revisions) == 0 {
+		if stat, _ := s.GetAuthor(id); stat.Err() != nil {
+			return stat, nil
+		}
+	}
+	return status.New(status.OK, ""), revisions
+}
+
+// GetRevision retrieves the author matching the given id as it was after the revision matching revisionID.
+func (s *Service) GetRevision(id int, revisionID int) (*status.Status, *Author) {
+	hs := &history.Service{DB: s.DB}
+	stat, revision := hs.GetRevision(revisionID)
+	if stat.Code() == status.NotFound || (revision != nil && (revision.Entity != Entity || revision.EntityID != id)) {
+		msg := fmt.Sprintf("Revision %d of author with id = %d does not exist", revisionID, id)
+		log.Printf("[GetRevision] %s", msg)
+		return status.Newf(status.NotFound, msg), nil
+	}
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	if revision.After == nil {
+		msg := fmt.Sprintf("Revision %d deleted author with id = %d", revisionID, id)
+		log.Printf("[GetRevision] %s", msg)
+		return status.Newf(status.UnprocessableEntity, msg), nil
+	}
+
+	var au Author
+	if err := json.Unmarshal(revision.After, &au); err != nil {
+		log.Printf("[GetRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), &au
+}
+
+// LastModified returns when the authors matching the given ids, or every author if none are given, were last modified.
+func (s *Service) LastModified(ids []int) (*status.Status, time.Time) {
+	db := s.DB
+	lastModified := s.Query(LastModified)
+
+	if ids == nil {
+		ids = []int{}
+	}
+	var modified sql.NullTime
+	if err := db.QueryRow(lastModified, pq.Array(ids)).Scan(&modified); err != nil {
+		log.Printf("[LastModified] %s", err)
+		return status.New(status.InternalServerError, err.Error()), time.Time{}
+	}
+	return status.New(status.OK, ""), modified.Time
+}
+
+// Locked calls fn with a copy of the receiver whose statements are executed inside a transaction in which the
+// author matching the given id is locked, so that it cannot be changed by anyone else until fn returns. The
+// transaction is committed if fn succeeds and rolled back otherwise.
+func (s *Service) Locked(id int, fn func(*Service) *status.Status) *status.Status {
+	return s.Transaction(func(s *Service) *status.Status {
+		if _, err := s.DB.Exec(s.Query(LockAuthor), id); err != nil {
+			log.Printf("[Locked] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+		return fn(s)
+	})
+}
+
+// PatchAuthor updates the entry in the database matching author.id with the given attributes. If the names of
+// fields are given, exactly those fields are updated, even to empty values; otherwise, only the attributes that are
+// not empty are.
+func (s *Service) PatchAuthor(author *Author, fields ...string) (*status.Status, *Author) {
+	var patched *Author
+	stat := s.Locked(author.ID, func(s *Service) *status.Status {
+		var stat *status.Status
+		_, before := s.GetAuthor(author.ID)
+		if stat, patched = s.patchAuthor(author, fields); patched == nil || stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Patch, author.ID, before, patched, stat)
+	})
+	return stat, patched
+}
+
+// PostAlias adds the given alias to the author matching authorID. Each name can only be the alias of one author.
+func (s *Service) PostAlias(authorID int, alias *Alias) (*status.Status, *Alias) {
+	if alias.FirstName == "" || alias.LastName == "" {
+		msg := "An alias must have a first and a last name"
+		log.Printf("[PostAlias] %s", msg)
+		return status.New(status.UnprocessableEntity, msg), nil
+	}
+
+	db := s.DB
+	postAlias := s.Query(PostAlias)
+
+	var posted Alias
+	row := db.QueryRow(postAlias, authorID, alias.FirstName, alias.LastName)
+	if err := row.Scan(&posted.ID, &posted.FirstName, &posted.LastName); err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Author with id = %d does not exist", authorID)
+			log.Printf("[PostAlias] %s", msg)
+			return status.New(status.NotFound, msg), nil
+		} else if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+			msg := fmt.Sprintf("%s %s is already an alias", alias.FirstName, alias.LastName)
+			log.Printf("[PostAlias] %s", msg)
+			return status.New(status.Conflict, msg), nil
+		}
+		log.Printf("[PostAlias] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.Created, ""), &posted
+}
+
+// PostAuthor creates an entry in the author table with the given attributes.
+func (s *Service) PostAuthor(author *Author) (*status.Status, *Author) {
+	var posted *Author
+	stat := s.Transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, posted = s.postAuthor(author); stat.Code() != status.Created {
+			return stat
+		}
+		return s.record(history.Post, posted.ID, nil, posted, stat)
+	})
+	return stat, posted
+}
+
+// PurgeAuthors permanently removes the authors deleted before the given time whose works have all been purged,
+// and returns how many authors were removed.
+func (s *Service) PurgeAuthors(before time.Time) (*status.Status, int) {
+	db := s.DB
+	purgeAuthors := s.Query(PurgeAuthors)
+
+	res, err := db.Exec(purgeAuthors, before)
+	if err != nil {
+		log.Printf("[PurgeAuthors] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+
+	numPurged, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[PurgeAuthors] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+	return status.New(status.OK, ""), int(numPurged)
+}
+
+// RestoreAuthor restores the deleted author matching the given id.
+func (s *Service) RestoreAuthor(id int) (*status.Status, *Author) {
+	var restored *Author
+	stat := s.Transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, restored = s.restoreAuthor(id); stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Restore, id, nil, restored, stat)
+	})
+	return stat, restored
+}
+
+// Transaction calls fn with a copy of the receiver whose statements are executed inside a transaction, which is
+// committed if fn succeeds and rolled back otherwise, as described by postgres.DB.Transaction. Changes are recorded
+// in the history in the same transaction.
+func (s *Service) Transaction(fn func(*Service) *status.Status) *status.Status {
+	var stat *status.Status
+	if err := s.DB.Transaction(func(tx *sql.Tx) error {
+		stat = fn(s.WithTx(tx))
+		return stat.Err()
+	}); err != nil && (stat == nil || stat.Err() == nil) {
+		log.Printf("[Transaction] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return stat
+}
+
+func (s *Service) deleteAuthor(id int) *status.Status {
+	db := s.DB
+	deleteAuthor := s.Query(DeleteAuthor)
+
+	res, err := db.Exec(deleteAuthor, id)
+	if err != nil {
+		log.Printf("[DeleteAuthor] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	numDeleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[DeleteAuthor] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if numDeleted == 0 {
+		if s, _ := s.GetAuthor(id); s.Code() == status.OK {
+			msg := fmt.Sprintf("Author with id = %d still has works", id)
+			log.Printf("[DeleteAuthor] %s", msg)
+			return status.New(status.Conflict, msg)
+		}
+		msg := fmt.Sprintf("Author with id = %d does not exist", id)
+		log.Printf("[DeleteAuthor] %s", msg)
+		return status.New(status.OK, msg)
+	}
+	return status.New(status.NoContent, "")
+}
+
+func (s *Service) getAuthor(row interface {
+	Scan(dest ...interface{}) error
+}) (*Author, error) {
+	var author Author
+	err := row.Scan(
+		&author.ID,
+		&author.FirstName,
+		&author.LastName,
+		&author.Gender,
+		&author.DateOfBirth,
+		&author.PlaceOfBirth.ID,
+		&author.PlaceOfBirth.City,
+		&author.PlaceOfBirth.Country,
+		&author.PlaceOfBirth.Region,
+	)
+	return &author, err
+}
+
+func (s *Service) handleLocation(author *Author) *status.Status {
+	pob := &author.PlaceOfBirth
+
+	stat, location := s.LocationService.PostLocation(pob)
+	if stat.Err() != nil {
+		if stat.Code() != status.Conflict {
+			return status.New(stat.Code(), stat.Message())
+		}
+
+		if stat, location = s.LocationService.GetLocation(pob.ID); stat.Err() != nil {
+			if stat, location = s.LocationService.FindLocation(pob.City, pob.Country); stat.Err() != nil {
+				return status.New(stat.Code(), stat.Message())
+			}
+		}
+	}
+
+	pob.ID = location.ID
+	return status.New(status.OK, "")
+}
+
+func (s *Service) patchAuthor(author *Author, fields []string) (*status.Status, *Author) {
 	if author.PlaceOfBirth != (location.Location{}) {
 		if s := s.handleLocation(author); s.Err() != nil {
 			log.Printf("[PostAuthor] %s", s.Err())
@@ -233,7 +609,7 @@ func (s *Service) PatchAuthor(author *Author) (*status.Status, *Author) {
 		"place_of_birth": author.PlaceOfBirth.ID,
 	}
 
-	patchAuthor := s.Query(PatchAuthor, a)
+	patchAuthor := s.Query(PatchAuthor, Fields.Select(a, fields...))
 	if patchAuthor != "" {
 		var au Author
 		row := db.QueryRow(patchAuthor, author.ID)
@@ -252,8 +628,7 @@ func (s *Service) PatchAuthor(author *Author) (*status.Status, *Author) {
 	return status.New(status.BadRequest, "No fields in author to update"), nil
 }
 
-// PostAuthor creates an entry in the author table with the given attributes.
-func (s *Service) PostAuthor(author *Author) (*status.Status, *Author) {
+func (s *Service) postAuthor(author *Author) (*status.Status, *Author) {
 	if author.ID != 0 {
 		if s, l := s.GetAuthor(author.ID); s.Code() == status.OK {
 			msg := fmt.Sprintf("Author with id = %d already exists", author.ID)
@@ -298,40 +673,39 @@ func (s *Service) PostAuthor(author *Author) (*status.Status, *Author) {
 	return status.New(status.Created, ""), &au
 }
 
-func (s *Service) getAuthor(row interface {
-	Scan(dest ...interface{}) error
-}) (*Author, error) {
-	var author Author
-	err := row.Scan(
-		&author.ID,
-		&author.FirstName,
-		&author.LastName,
-		&author.Gender,
-		&author.DateOfBirth,
-		&author.PlaceOfBirth.ID,
-		&author.PlaceOfBirth.City,
-		&author.PlaceOfBirth.Country,
-		&author.PlaceOfBirth.Region,
-	)
-	return &author, err
+// record records a change made to the author matching the given id in the history, and returns stat, the status
+// of the change, if it succeeds.
+func (s *Service) record(action string, id int, before *Author, after *Author, stat *status.Status) *status.Status {
+	hs := &history.Service{DB: s.DB}
+	if s := hs.PostRevision(Entity, id, action, before, after); s.Err() != nil {
+		return s
+	}
+	return stat
 }
 
-func (s *Service) handleLocation(author *Author) *status.Status {
-	pob := &author.PlaceOfBirth
-
-	stat, location := s.LocationService.PostLocation(pob)
-	if stat.Err() != nil {
-		if stat.Code() != status.Conflict {
-			return status.New(stat.Code(), stat.Message())
-		}
+func (s *Service) restoreAuthor(id int) (*status.Status, *Author) {
+	db := s.DB
+	restoreAuthor := s.Query(RestoreAuthor)
 
-		if stat, location = s.LocationService.GetLocation(pob.ID); stat.Err() != nil {
-			if stat, location = s.LocationService.FindLocation(pob.City, pob.Country); stat.Err() != nil {
-				return status.New(stat.Code(), stat.Message())
-			}
+	res, err := db.Exec(restoreAuthor, id)
+	if err != nil {
+		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+			log.Printf("[RestoreAuthor] %s", err)
+			return status.New(status.Conflict, err.Error()), nil
 		}
+		log.Printf("[RestoreAuthor] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
 	}
 
-	pob.ID = location.ID
-	return status.New(status.OK, "")
+	numRestored, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[RestoreAuthor] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	if numRestored == 0 {
+		msg := fmt.Sprintf("Deleted author with id = %d does not exist", id)
+		log.Printf("[RestoreAuthor] %s", msg)
+		return status.Newf(status.NotFound, msg), nil
+	}
+	return s.GetAuthor(id)
 }
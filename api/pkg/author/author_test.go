@@ -5,6 +5,7 @@ import (
 
 	"github.com/andrewzulaybar/books/api/internal/test/helpers"
 	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/history"
 	"github.com/andrewzulaybar/books/api/pkg/location"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/andrewzulaybar/books/api/test/data"
@@ -38,6 +39,21 @@ func TestDeleteAuthor(t *testing.T) {
 		helpers.AssertEqual(t, want, got)
 	})
 
+	t.Run("HasWorks", func(t *testing.T) {
+		ws := services.WorkService
+		wks := helpers.PostWorks(t, ws, data.GetWorks(ws))
+		id := wks[0].Author.ID
+
+		got := as.DeleteAuthor(id)
+		want := status.Newf(status.Conflict, "Author with id = %d still has works", id)
+		helpers.AssertEqual(t, want, got)
+
+		helpers.DeleteWork(t, ws, wks[0].ID)
+		got = as.DeleteAuthor(id)
+		want = status.New(status.NoContent, "")
+		helpers.AssertEqual(t, want, got)
+	})
+
 	cleanup()
 }
 
@@ -94,7 +110,7 @@ func TestFindAuthor(t *testing.T) {
 	services, cleanup := helpers.Setup(t)
 	as := services.AuthorService
 	authors := data.GetAuthors(as)
-	helpers.PostAuthors(t, as, authors)
+	posted := helpers.PostAuthors(t, as, authors)
 
 	t.Run("ExistingAuthor", func(t *testing.T) {
 		ta := &authors[0]
@@ -113,6 +129,90 @@ func TestFindAuthor(t *testing.T) {
 		helpers.AssertNil(t, gotAuthor)
 	})
 
+	t.Run("UnknownDateOfBirth", func(t *testing.T) {
+		ta := &authors[0]
+		gotStatus, gotAuthor := as.FindAuthor(ta.FirstName, ta.LastName, "")
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, ta, gotAuthor)
+	})
+
+	t.Run("Alias", func(t *testing.T) {
+		ta := posted[1]
+		as.PostAlias(ta.ID, &author.Alias{FirstName: "Richard", LastName: "Bachman"})
+		gotStatus, gotAuthor := as.FindAuthor("Richard", "Bachman", "1970-01-01T00:00:00Z")
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, ta.ID, gotAuthor.ID)
+	})
+
+	cleanup()
+}
+
+func TestPostAlias(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	as := services.AuthorService
+	posted := helpers.PostAuthors(t, as, data.GetAuthors(as))
+
+	t.Run("Alias", func(t *testing.T) {
+		alias := &author.Alias{FirstName: "Richard", LastName: "Bachman"}
+		gotStatus, gotAlias := as.PostAlias(posted[0].ID, alias)
+		helpers.AssertEqual(t, status.New(status.Created, ""), gotStatus)
+		helpers.AssertEqual(t, alias.LastName, gotAlias.LastName)
+
+		_, gotAliases := as.GetAliases(posted[0].ID)
+		helpers.AssertEqual(t, author.Aliases{*gotAlias}, gotAliases)
+	})
+
+	t.Run("TakenName", func(t *testing.T) {
+		gotStatus, gotAlias := as.PostAlias(posted[1].ID, &author.Alias{FirstName: "Richard", LastName: "Bachman"})
+		helpers.AssertEqual(t, status.Conflict, gotStatus.Code())
+		helpers.AssertNil(t, gotAlias)
+	})
+
+	t.Run("NoName", func(t *testing.T) {
+		gotStatus, _ := as.PostAlias(posted[1].ID, &author.Alias{FirstName: "Richard"})
+		helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+	})
+
+	t.Run("NonExistentAuthor", func(t *testing.T) {
+		gotStatus, _ := as.PostAlias(-1, &author.Alias{FirstName: "Anne", LastName: "Rice"})
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+	})
+
+	cleanup()
+}
+
+func TestGetHistory(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	as := services.AuthorService.WithActor("editor")
+	authors := helpers.PostAuthors(t, as, data.GetAuthors(as))
+
+	t.Run("Patched", func(t *testing.T) {
+		ta := authors[0]
+		if gotStatus, _ := as.PatchAuthor(&author.Author{ID: ta.ID, LastName: "Revised"}); gotStatus.Err() != nil {
+			t.Fatalf("err: %s", gotStatus.Err())
+		}
+
+		gotStatus, gotRevisions := as.GetHistory(ta.ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, 2, len(gotRevisions))
+		helpers.AssertEqual(t, history.Patch, gotRevisions[1].Action)
+		helpers.AssertEqual(t, "editor", gotRevisions[1].Actor)
+		wantDiff := map[string]history.Change{"lastName": {Before: ta.LastName, After: "Revised"}}
+		helpers.AssertEqual(t, wantDiff, gotRevisions[1].Diff)
+
+		gotStatus, gotAuthor := as.GetRevision(ta.ID, gotRevisions[0].ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, &ta, gotAuthor)
+	})
+
+	t.Run("NonExistentID", func(t *testing.T) {
+		gotStatus, gotRevisions := as.GetHistory(-1)
+		wantStatus := status.New(status.NotFound, "Author with id = -1 does not exist")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotRevisions)
+	})
+
 	cleanup()
 }
 
@@ -390,3 +490,29 @@ func TestPostAuthor(t *testing.T) {
 
 	cleanup()
 }
+
+func TestRestoreAuthor(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	as := services.AuthorService
+	authors := helpers.PostAuthors(t, as, data.GetAuthors(as))
+
+	t.Run("Deleted", func(t *testing.T) {
+		helpers.DeleteAuthor(t, as, authors[0].ID)
+		gotStatus, _ := as.GetAuthor(authors[0].ID)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+
+		gotStatus, gotAuthor := as.RestoreAuthor(authors[0].ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, &authors[0], gotAuthor)
+	})
+
+	t.Run("NotDeleted", func(t *testing.T) {
+		gotStatus, gotAuthor := as.RestoreAuthor(authors[1].ID)
+		wantStatus := status.Newf(status.NotFound, "Deleted author with id = %d does not exist", authors[1].ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotAuthor)
+	})
+
+	cleanup()
+}
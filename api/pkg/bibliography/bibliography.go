@@ -0,0 +1,153 @@
+// Package bibliography describes the works of an author: when each was first published, how many editions of it
+// there are, which languages it has been published in and how it has been rated.
+package bibliography
+
+import (
+	"log"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/lib/pq"
+)
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	GetEditions
+	GetLanguages
+	GetRating
+)
+
+// A Bibliography represents an author along with their aliases, their works and statistics about them.
+type Bibliography struct {
+	Author  author.Author  `json:"author"`
+	Aliases author.Aliases `json:"aliases"`
+
+	// Works are ordered by the date they were first published.
+	Works Entries `json:"works"`
+
+	// Languages are those that the author's works have been published in, in alphabetical order.
+	Languages []string `json:"languages"`
+
+	// AverageRating is the average of the ratings given to the author's publications on the shelves of users,
+	// which are from 1 to 5, or 0 if none has been rated.
+	AverageRating float64 `json:"averageRating"`
+	NumRatings    int     `json:"numRatings"`
+}
+
+// An Entry represents a work of a bibliography, along with the number of its editions.
+type Entry struct {
+	Work     work.Work `json:"work"`
+	Editions int       `json:"editions"`
+}
+
+// Entries represents a list of entries.
+type Entries []Entry
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+
+	WorkService work.Service
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case GetEditions:
+		return `SELECT work.id, count(publication.id)
+                        FROM work
+                        LEFT JOIN publication ON publication.work_id = work.id AND publication.deleted_at IS NULL
+                        WHERE work.author_id = $1 AND work.deleted_at IS NULL
+                        GROUP BY work.id
+                        ORDER BY work.initial_pub_date, work.title`
+	case GetLanguages:
+		return `SELECT array_agg(DISTINCT publication.language ORDER BY publication.language)
+                        FROM publication
+                        JOIN work ON publication.work_id = work.id
+                        WHERE work.author_id = $1 AND work.deleted_at IS NULL AND publication.deleted_at IS NULL
+                        AND publication.language <> ''`
+	case GetRating:
+		// Shelves without a rating have a rating of 0.
+		return `SELECT coalesce(avg(shelf.rating), 0), count(shelf.rating)
+                        FROM shelf
+                        JOIN publication ON shelf.publication_id = publication.id
+                        JOIN work ON publication.work_id = work.id
+                        WHERE work.author_id = $1 AND work.deleted_at IS NULL AND publication.deleted_at IS NULL
+                        AND shelf.rating > 0`
+	default:
+		return ""
+	}
+}
+
+// GetBibliography retrieves the bibliography of the author matching the given id.
+func (s *Service) GetBibliography(id int) (*status.Status, *Bibliography) {
+	as := &s.WorkService.AuthorService
+	stat, au := as.GetAuthor(id)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	stat, aliases := as.GetAliases(id)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	b := &Bibliography{Author: *au, Aliases: aliases}
+
+	if stat, b.Works = s.getEntries(id); stat.Err() != nil {
+		return stat, nil
+	}
+
+	db := s.DB
+	var languages pq.StringArray
+	if err := db.QueryRow(s.Query(GetLanguages), id).Scan(&languages); err != nil {
+		log.Printf("[GetBibliography] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	b.Languages = append([]string{}, languages...)
+
+	if err := db.QueryRow(s.Query(GetRating), id).Scan(&b.AverageRating, &b.NumRatings); err != nil {
+		log.Printf("[GetBibliography] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), b
+}
+
+// getEntries retrieves the works of the author matching the given id, along with the number of their editions, in
+// the order they were first published.
+func (s *Service) getEntries(id int) (*status.Status, Entries) {
+	stat, works := s.WorkService.GetWorksByAuthors([]int{id})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	byID := map[int]work.Work{}
+	for _, w := range works {
+		byID[w.ID] = w
+	}
+
+	db := s.DB
+	rows, err := db.Query(s.Query(GetEditions), id)
+	if err != nil {
+		log.Printf("[GetBibliography] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	entries := Entries{}
+	for rows.Next() {
+		var workID, editions int
+		if err := rows.Scan(&workID, &editions); err != nil {
+			log.Printf("[GetBibliography] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		if w, ok := byID[workID]; ok {
+			entries = append(entries, Entry{Work: w, Editions: editions})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[GetBibliography] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), entries
+}
@@ -0,0 +1,73 @@
+package bibliography_test
+
+import (
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/andrewzulaybar/books/api/test/data"
+)
+
+func TestGetBibliography(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	ws := services.WorkService
+	bs := &bibliography.Service{DB: ps.DB, WorkService: *ws}
+	pubs := helpers.PostPublications(t, ps, data.GetPublications(ps))
+	authorID := pubs[0].Work.Author.ID
+
+	earlier := helpers.PostWork(t, ws, &work.Work{
+		InitialPubDate:   "2017-07-11T00:00:00Z",
+		OriginalLanguage: "English",
+		Title:            "Conversations with Friends",
+		Author:           pubs[0].Work.Author,
+	})
+	translation := pubs[0]
+	translation.ID = 0
+	translation.ISBN = "2714493974"
+	translation.ISBN13 = "9782714493972"
+	translation.Language = "French"
+	translated := helpers.PostPublication(t, ps, &translation)
+
+	t.Run("Works", func(t *testing.T) {
+		gotStatus, gotBibliography := bs.GetBibliography(authorID)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 2, len(gotBibliography.Works))
+
+		helpers.AssertEqual(t, earlier.ID, gotBibliography.Works[0].Work.ID)
+		helpers.AssertEqual(t, 0, gotBibliography.Works[0].Editions)
+		helpers.AssertEqual(t, pubs[0].Work.ID, gotBibliography.Works[1].Work.ID)
+		helpers.AssertEqual(t, 2, gotBibliography.Works[1].Editions)
+	})
+
+	t.Run("Languages", func(t *testing.T) {
+		_, gotBibliography := bs.GetBibliography(authorID)
+		helpers.AssertEqual(t, []string{"English", "French"}, gotBibliography.Languages)
+	})
+
+	t.Run("Rating", func(t *testing.T) {
+		_, gotBibliography := bs.GetBibliography(authorID)
+		helpers.AssertEqual(t, 0.0, gotBibliography.AverageRating)
+		helpers.AssertEqual(t, 0, gotBibliography.NumRatings)
+
+		ss := &shelf.Service{DB: ps.DB}
+		for _, e := range []shelf.Entry{
+			{UserID: 1, Publication: pubs[0], ExclusiveShelf: shelf.Read, Rating: 5},
+			{UserID: 1, Publication: *translated, ExclusiveShelf: shelf.Read, Rating: 2},
+			{UserID: 1, Publication: pubs[1], ExclusiveShelf: shelf.Read, Rating: 1},
+		} {
+			e := e
+			gotStatus, _ := ss.PutEntry(&e)
+			helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		}
+
+		_, gotBibliography = bs.GetBibliography(authorID)
+		helpers.AssertEqual(t, 3.5, gotBibliography.AverageRating)
+		helpers.AssertEqual(t, 2, gotBibliography.NumRatings)
+	})
+
+	cleanup()
+}
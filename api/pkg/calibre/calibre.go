@@ -0,0 +1,116 @@
+package calibre
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	_ "github.com/mattn/go-sqlite3" // sqlite driver
+)
+
+// Format is the publication format given to books imported from Calibre.
+const Format string = "Ebook"
+
+// separator joins tags in the books query. Calibre forbids it in tag names.
+const separator string = "\x1f"
+
+// books selects one row per book, picking the first of each linked author, publisher and language.
+const books string = `
+SELECT
+    b.id,
+    b.title,
+    COALESCE(b.pubdate, ''),
+    COALESCE(b.series_index, 0),
+    COALESCE((SELECT a.name FROM books_authors_link l JOIN authors a ON a.id = l.author
+              WHERE l.book = b.id ORDER BY l.id LIMIT 1), ''),
+    COALESCE((SELECT p.name FROM books_publishers_link l JOIN publishers p ON p.id = l.publisher
+              WHERE l.book = b.id ORDER BY l.id LIMIT 1), ''),
+    COALESCE((SELECT g.lang_code FROM books_languages_link l JOIN languages g ON g.id = l.lang_code
+              WHERE l.book = b.id ORDER BY l.item_order LIMIT 1), ''),
+    COALESCE((SELECT s.name FROM books_series_link l JOIN series s ON s.id = l.series
+              WHERE l.book = b.id LIMIT 1), ''),
+    COALESCE((SELECT group_concat(t.name, '` + separator + `') FROM books_tags_link l JOIN tags t ON t.id = l.tag
+              WHERE l.book = b.id), ''),
+    COALESCE((SELECT i.val FROM identifiers i WHERE i.book = b.id AND i.type = 'isbn'), ''),
+    COALESCE((SELECT c.text FROM comments c WHERE c.book = b.id), '')
+FROM books b
+ORDER BY b.id`
+
+// Read opens the metadata.db of the Calibre library in dir and converts each book into a Row.
+// Since books have no line in the library, each Row's Line is the book's Calibre id.
+func Read(dir string) (importer.Rows, error) {
+	path := filepath.Join(dir, "metadata.db")
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("not a Calibre library: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(books)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := importer.Rows{}
+	for rows.Next() {
+		var id int
+		var pub publication.Publication
+		var pubdate, authorName, langCode, tagList, comments string
+		wk := &pub.Work
+		if err := rows.Scan(
+			&id,
+			&wk.Title,
+			&pubdate,
+			&wk.SeriesIndex,
+			&authorName,
+			&pub.Publisher,
+			&langCode,
+			&wk.Series,
+			&tagList,
+			&pub.ISBN13,
+			&comments,
+		); err != nil {
+			return nil, err
+		}
+
+		pub.Format = Format
+		pub.EditionPubDate = date(pubdate)
+		pub.Language = language.Name(langCode)
+		wk.OriginalLanguage = pub.Language
+		wk.Description = importer.PlainText(comments)
+		wk.Author.FirstName, wk.Author.LastName = importer.SplitName(authorName)
+		if wk.Series == "" {
+			wk.SeriesIndex = 0
+		}
+
+		var genres []string
+		if tagList != "" {
+			genres = strings.Split(tagList, separator)
+		}
+
+		// Calibre identifiers may hold either form of ISBN.
+		if len(pub.ISBN13) == 10 {
+			pub.ISBN, pub.ISBN13 = pub.ISBN13, ""
+		}
+		result = append(result, importer.Row{Line: id, Publication: pub, Genres: genres})
+	}
+	return result, rows.Err()
+}
+
+// date returns the date part of a Calibre timestamp, or "" for Calibre's "undefined" dates in year 101.
+func date(timestamp string) string {
+	if len(timestamp) < 10 || timestamp[:4] < "1000" {
+		return ""
+	}
+	return timestamp[:10]
+}
@@ -0,0 +1,99 @@
+package calibre_test
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/calibre"
+)
+
+// schema is the subset of Calibre's metadata.db read by calibre.Read.
+const schema = `
+CREATE TABLE books (id INTEGER PRIMARY KEY, title TEXT, pubdate TIMESTAMP, series_index REAL);
+CREATE TABLE authors (id INTEGER PRIMARY KEY, name TEXT);
+CREATE TABLE books_authors_link (id INTEGER PRIMARY KEY, book INTEGER, author INTEGER);
+CREATE TABLE publishers (id INTEGER PRIMARY KEY, name TEXT);
+CREATE TABLE books_publishers_link (id INTEGER PRIMARY KEY, book INTEGER, publisher INTEGER);
+CREATE TABLE languages (id INTEGER PRIMARY KEY, lang_code TEXT);
+CREATE TABLE books_languages_link (id INTEGER PRIMARY KEY, book INTEGER, lang_code INTEGER, item_order INTEGER);
+CREATE TABLE series (id INTEGER PRIMARY KEY, name TEXT);
+CREATE TABLE books_series_link (id INTEGER PRIMARY KEY, book INTEGER, series INTEGER);
+CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT);
+CREATE TABLE books_tags_link (id INTEGER PRIMARY KEY, book INTEGER, tag INTEGER);
+CREATE TABLE identifiers (id INTEGER PRIMARY KEY, book INTEGER, type TEXT, val TEXT);
+CREATE TABLE comments (id INTEGER PRIMARY KEY, book INTEGER, text TEXT);
+
+INSERT INTO books VALUES (1, 'Catching Fire', '2009-09-01 04:00:00+00:00', 2.0);
+INSERT INTO books VALUES (2, 'Untitled', '0101-01-01 00:00:00+00:00', 1.0);
+INSERT INTO authors VALUES (1, 'Suzanne Collins');
+INSERT INTO books_authors_link VALUES (1, 1, 1);
+INSERT INTO publishers VALUES (1, 'Scholastic');
+INSERT INTO books_publishers_link VALUES (1, 1, 1);
+INSERT INTO languages VALUES (1, 'eng');
+INSERT INTO books_languages_link VALUES (1, 1, 1, 0);
+INSERT INTO series VALUES (1, 'The Hunger Games');
+INSERT INTO books_series_link VALUES (1, 1, 1);
+INSERT INTO tags VALUES (1, 'Dystopia'), (2, 'Young Adult');
+INSERT INTO books_tags_link VALUES (1, 1, 1), (2, 1, 2);
+INSERT INTO identifiers VALUES (1, 1, 'isbn', '9780439023498'), (2, 2, 'isbn', '0743273567');
+INSERT INTO comments VALUES (1, 1, '<p>Sparks are igniting.</p><p>Flames are spreading &amp; burning.</p>');
+`
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "calibre")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "metadata.db"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	db.Close()
+
+	rows, err := calibre.Read(dir)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, 2, len(rows))
+
+	t.Run("AllFields", func(t *testing.T) {
+		row := rows[0]
+		pub := row.Publication
+		helpers.AssertEqual(t, 1, row.Line)
+		helpers.AssertEqual(t, "Catching Fire", pub.Work.Title)
+		helpers.AssertEqual(t, "The Hunger Games", pub.Work.Series)
+		helpers.AssertEqual(t, 2.0, pub.Work.SeriesIndex)
+		helpers.AssertEqual(t, "Sparks are igniting. Flames are spreading & burning.", pub.Work.Description)
+		helpers.AssertEqual(t, "Suzanne", pub.Work.Author.FirstName)
+		helpers.AssertEqual(t, "Collins", pub.Work.Author.LastName)
+		helpers.AssertEqual(t, "Scholastic", pub.Publisher)
+		helpers.AssertEqual(t, "English", pub.Language)
+		helpers.AssertEqual(t, "2009-09-01", pub.EditionPubDate)
+		helpers.AssertEqual(t, "9780439023498", pub.ISBN13)
+		helpers.AssertEqual(t, calibre.Format, pub.Format)
+		helpers.AssertEqual(t, []string{"Dystopia", "Young Adult"}, []string(row.Genres))
+	})
+
+	t.Run("MissingMetadata", func(t *testing.T) {
+		row := rows[1]
+		pub := row.Publication
+		helpers.AssertEqual(t, "", pub.EditionPubDate)
+		helpers.AssertEqual(t, "0743273567", pub.ISBN)
+		helpers.AssertEqual(t, "", pub.ISBN13)
+		helpers.AssertEqual(t, "", pub.Work.Series)
+		helpers.AssertEqual(t, 0.0, pub.Work.SeriesIndex)
+		helpers.AssertNil(t, row.Genres)
+	})
+
+	t.Run("NotALibrary", func(t *testing.T) {
+		_, err := calibre.Read(filepath.Join(dir, "missing"))
+		helpers.AssertEqual(t, false, err == nil)
+	})
+}
@@ -0,0 +1,107 @@
+package catalog
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/audit"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// auditedMethod is the method that calls are recorded with in the audit log, where requests to the REST API are
+// recorded with their HTTP method.
+const auditedMethod = "GRPC"
+
+// UnaryAudited records every call that may change the catalogue in the audit log once it has been handled, as
+// handlers.Audited does for requests to the REST API, with the status its error corresponds to. It must come after
+// UnaryAuthenticator, so that calls are recorded as made by whoever they were authenticated as.
+func UnaryAudited(au *audit.Service) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !mutates(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		res, err := handler(ctx, req)
+		au.PostEntry(&audit.Entry{
+			Actor:     actor(ctx),
+			IP:        remoteIP(ctx),
+			Method:    auditedMethod,
+			Route:     info.FullMethod,
+			Entity:    auditedEntity(info.FullMethod),
+			EntityIDs: auditedIDs(req, res),
+			Status:    httpStatus(err),
+		})
+		return res, err
+	}
+}
+
+// remoteIP returns the address of the client that made the call, without its port.
+func remoteIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// auditedEntity returns the kind of entry that a call of the given method concerns, e.g. publication for
+// /books.catalog.v1.CatalogService/DeletePublication.
+func auditedEntity(method string) string {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range []string{"Create", "Update", "Delete"} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return strings.ToLower(name)
+}
+
+// auditedIDs returns the ids of the entries that a call concerns: the id in its request, if any, and the id of
+// the entry it created or updated.
+func auditedIDs(req interface{}, res interface{}) []int {
+	ids := []int{}
+	for _, m := range []interface{}{req, res} {
+		if m, ok := m.(interface{ GetId() int64 }); ok && m.GetId() != 0 {
+			if id := int(m.GetId()); len(ids) == 0 || ids[0] != id {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// httpStatus returns the status code that a call ending with the given error would have been answered with by
+// the REST API, undoing Error.
+func httpStatus(err error) int {
+	switch grpcstatus.Code(err) {
+	case codes.OK:
+		return status.OK
+	case codes.InvalidArgument:
+		return status.UnprocessableEntity
+	case codes.Unauthenticated:
+		return status.Unauthorized
+	case codes.PermissionDenied:
+		return status.Forbidden
+	case codes.NotFound:
+		return status.NotFound
+	case codes.AlreadyExists:
+		return status.Conflict
+	case codes.FailedPrecondition:
+		return status.PreconditionFailed
+	case codes.ResourceExhausted:
+		return status.TooManyRequests
+	case codes.Unimplemented:
+		return status.NotImplemented
+	case codes.Unavailable:
+		return status.BadGateway
+	default:
+		return status.InternalServerError
+	}
+}
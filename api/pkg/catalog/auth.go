@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// identityKey is the key of the identity of a call in its context.
+type identityKey struct{}
+
+// UnaryAuthenticator authenticates calls with the credentials in their authorization metadata, which are the same
+// as those of the Authorization header of the REST API: the username and password of a user, with the Basic
+// scheme, or the admin token, with the Bearer scheme. Calls with invalid credentials are rejected, and so are calls
+// without any that may change the catalogue, i.e. every Create, Update and Delete.
+func UnaryAuthenticator(us *user.Service, adminToken string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, us, adminToken, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticator authenticates streaming calls as UnaryAuthenticator does unary ones.
+func StreamAuthenticator(us *user.Service, adminToken string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), us, adminToken, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream is a stream whose context holds the identity of its call.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream.
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate returns ctx along with the identity that the call of the given method was made with, if any.
+func authenticate(ctx context.Context, us *user.Service, adminToken string, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	given := md.Get("authorization")
+	if len(given) == 0 {
+		if mutates(method) {
+			return nil, grpcstatus.Error(codes.Unauthenticated, "Credentials are required to make changes")
+		}
+		return ctx, nil
+	}
+
+	s, id := us.Identify(given[0], adminToken)
+	if s.Err() != nil {
+		return nil, Error(s)
+	}
+	return context.WithValue(ctx, identityKey{}, id), nil
+}
+
+// mutates returns whether the method, given by its full name, e.g. /books.catalog.v1.CatalogService/DeleteWork,
+// may change the catalogue.
+func mutates(method string) bool {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range []string{"Create", "Update", "Delete"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// actor returns who is making the call, as authenticated by the interceptors, so that the changes it makes are
+// recorded in the history and the audit log as theirs. It is empty for calls made without credentials, which
+// cannot make any.
+func actor(ctx context.Context) string {
+	if id, ok := ctx.Value(identityKey{}).(*user.Identity); ok {
+		return id.Actor()
+	}
+	return ""
+}
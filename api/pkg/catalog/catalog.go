@@ -0,0 +1,246 @@
+package catalog
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/andrewzulaybar/books/api --go-grpc_out=../.. --go-grpc_opt=module=github.com/andrewzulaybar/books/api catalog/v1/catalog.proto
+
+import (
+	"context"
+
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/catalog/catalogpb"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Server implements catalogpb.CatalogServiceServer on top of the services used by the REST API.
+type Server struct {
+	catalogpb.UnimplementedCatalogServiceServer
+
+	AuthorService      author.Service
+	WorkService        work.Service
+	PublicationService publication.Service
+}
+
+// GetPublication retrieves the publication matching the given id.
+func (s *Server) GetPublication(ctx context.Context, req *catalogpb.GetRequest) (*catalogpb.Publication, error) {
+	st, pub := s.PublicationService.GetPublication(int(req.Id))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return PublicationToProto(pub), nil
+}
+
+// ListPublications retrieves a page of the publications matching the given filters.
+func (s *Server) ListPublications(ctx context.Context, req *catalogpb.ListPublicationsRequest) (*catalogpb.ListPublicationsResponse, error) {
+	st, pubs := s.PublicationService.FilterPublications(FilterFromProto(req))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+
+	res := &catalogpb.ListPublicationsResponse{Publications: make([]*catalogpb.Publication, len(pubs))}
+	for i := range pubs {
+		res.Publications[i] = PublicationToProto(&pubs[i])
+	}
+	return res, nil
+}
+
+// StreamPublications sends the publications matching the given filters as they are read from the database.
+func (s *Server) StreamPublications(req *catalogpb.ListPublicationsRequest, stream catalogpb.CatalogService_StreamPublicationsServer) error {
+	var sendErr error
+	st := s.PublicationService.StreamPublications(FilterFromProto(req), func(pub *publication.Publication) error {
+		sendErr = stream.Send(PublicationToProto(pub))
+		return sendErr
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if st.Err() != nil {
+		return Error(st)
+	}
+	return nil
+}
+
+// CreatePublication creates the given publication, along with its work and author if they do not exist.
+func (s *Server) CreatePublication(ctx context.Context, req *catalogpb.Publication) (*catalogpb.Publication, error) {
+	pub := PublicationFromProto(req)
+	st, created := s.PublicationService.WithActor(actor(ctx)).PostPublication(&pub)
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetPublication(ctx, &catalogpb.GetRequest{Id: int64(created.ID)})
+}
+
+// UpdatePublication updates the fields of the publication that are set in the request.
+func (s *Server) UpdatePublication(ctx context.Context, req *catalogpb.Publication) (*catalogpb.Publication, error) {
+	if _, err := s.GetPublication(ctx, &catalogpb.GetRequest{Id: req.Id}); err != nil {
+		return nil, err
+	}
+
+	pub := PublicationFromProto(req)
+	if st, _ := s.PublicationService.WithActor(actor(ctx)).PatchPublication(&pub); st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetPublication(ctx, &catalogpb.GetRequest{Id: req.Id})
+}
+
+// DeletePublication removes the publication matching the given id.
+func (s *Server) DeletePublication(ctx context.Context, req *catalogpb.DeleteRequest) (*emptypb.Empty, error) {
+	return deleted(s.PublicationService.WithActor(actor(ctx)).DeletePublication(int(req.Id)))
+}
+
+// GetWork retrieves the work matching the given id.
+func (s *Server) GetWork(ctx context.Context, req *catalogpb.GetRequest) (*catalogpb.Work, error) {
+	st, wk := s.WorkService.GetWork(int(req.Id))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return WorkToProto(wk), nil
+}
+
+// ListWorks retrieves a page of the works in the catalogue.
+func (s *Server) ListWorks(ctx context.Context, req *catalogpb.ListRequest) (*catalogpb.ListWorksResponse, error) {
+	st, works := s.WorkService.GetWorksPage(page(req))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+
+	res := &catalogpb.ListWorksResponse{Works: make([]*catalogpb.Work, len(works))}
+	for i := range works {
+		res.Works[i] = WorkToProto(&works[i])
+	}
+	return res, nil
+}
+
+// CreateWork creates the given work, along with its author if they do not exist.
+func (s *Server) CreateWork(ctx context.Context, req *catalogpb.Work) (*catalogpb.Work, error) {
+	wk := WorkFromProto(req)
+	st, created := s.WorkService.WithActor(actor(ctx)).PostWork(&wk)
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetWork(ctx, &catalogpb.GetRequest{Id: int64(created.ID)})
+}
+
+// UpdateWork updates the fields of the work that are set in the request.
+func (s *Server) UpdateWork(ctx context.Context, req *catalogpb.Work) (*catalogpb.Work, error) {
+	if _, err := s.GetWork(ctx, &catalogpb.GetRequest{Id: req.Id}); err != nil {
+		return nil, err
+	}
+
+	wk := WorkFromProto(req)
+	if st, _ := s.WorkService.WithActor(actor(ctx)).PatchWork(&wk); st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetWork(ctx, &catalogpb.GetRequest{Id: req.Id})
+}
+
+// DeleteWork removes the work matching the given id.
+func (s *Server) DeleteWork(ctx context.Context, req *catalogpb.DeleteRequest) (*emptypb.Empty, error) {
+	return deleted(s.WorkService.WithActor(actor(ctx)).DeleteWork(int(req.Id)))
+}
+
+// GetAuthor retrieves the author matching the given id.
+func (s *Server) GetAuthor(ctx context.Context, req *catalogpb.GetRequest) (*catalogpb.Author, error) {
+	st, au := s.AuthorService.GetAuthor(int(req.Id))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return AuthorToProto(au), nil
+}
+
+// ListAuthors retrieves a page of the authors in the catalogue.
+func (s *Server) ListAuthors(ctx context.Context, req *catalogpb.ListRequest) (*catalogpb.ListAuthorsResponse, error) {
+	st, authors := s.AuthorService.GetAuthorsPage(page(req))
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+
+	res := &catalogpb.ListAuthorsResponse{Authors: make([]*catalogpb.Author, len(authors))}
+	for i := range authors {
+		res.Authors[i] = AuthorToProto(&authors[i])
+	}
+	return res, nil
+}
+
+// CreateAuthor creates the given author, along with their place of birth if it does not exist.
+func (s *Server) CreateAuthor(ctx context.Context, req *catalogpb.Author) (*catalogpb.Author, error) {
+	au := AuthorFromProto(req)
+	st, created := s.AuthorService.WithActor(actor(ctx)).PostAuthor(&au)
+	if st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetAuthor(ctx, &catalogpb.GetRequest{Id: int64(created.ID)})
+}
+
+// UpdateAuthor updates the fields of the author that are set in the request.
+func (s *Server) UpdateAuthor(ctx context.Context, req *catalogpb.Author) (*catalogpb.Author, error) {
+	if _, err := s.GetAuthor(ctx, &catalogpb.GetRequest{Id: req.Id}); err != nil {
+		return nil, err
+	}
+
+	au := AuthorFromProto(req)
+	if st, _ := s.AuthorService.WithActor(actor(ctx)).PatchAuthor(&au); st.Err() != nil {
+		return nil, Error(st)
+	}
+	return s.GetAuthor(ctx, &catalogpb.GetRequest{Id: req.Id})
+}
+
+// DeleteAuthor removes the author matching the given id.
+func (s *Server) DeleteAuthor(ctx context.Context, req *catalogpb.DeleteRequest) (*emptypb.Empty, error) {
+	return deleted(s.AuthorService.WithActor(actor(ctx)).DeleteAuthor(int(req.Id)))
+}
+
+// Error returns the gRPC error corresponding to the given status.
+func Error(s *status.Status) error {
+	code := codes.Internal
+	switch s.Code() {
+	case status.BadRequest, status.UnprocessableEntity:
+		code = codes.InvalidArgument
+	case status.Unauthorized:
+		code = codes.Unauthenticated
+	case status.Forbidden:
+		code = codes.PermissionDenied
+	case status.NotFound, status.Gone:
+		code = codes.NotFound
+	case status.Conflict:
+		code = codes.AlreadyExists
+	case status.PreconditionFailed:
+		code = codes.FailedPrecondition
+	case status.TooManyRequests:
+		code = codes.ResourceExhausted
+	case status.NotImplemented, status.MethodNotAllowed:
+		code = codes.Unimplemented
+	case status.BadGateway:
+		code = codes.Unavailable
+	}
+	return grpcstatus.Error(code, s.Message())
+}
+
+// deleted returns the result of a delete that ended with the given status. The services report
+// deletes of ids that do not exist as successful, so they are told apart by their code.
+func deleted(s *status.Status) (*emptypb.Empty, error) {
+	switch {
+	case s.Err() != nil:
+		return nil, Error(s)
+	case s.Code() != status.NoContent:
+		return nil, grpcstatus.Error(codes.NotFound, s.Message())
+	default:
+		return &emptypb.Empty{}, nil
+	}
+}
+
+// page returns the limit and offset of the page selected by the given request, as taken by postgres.Page. Every
+// item is selected when no page size is given.
+func page(req *catalogpb.ListRequest) (int, int) {
+	limit, offset := int(req.PageSize), int(req.Offset)
+	if limit <= 0 {
+		limit = -1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
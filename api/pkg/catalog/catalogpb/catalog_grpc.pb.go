@@ -0,0 +1,672 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package catalogpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CatalogServiceClient is the client API for CatalogService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CatalogServiceClient interface {
+	GetPublication(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Publication, error)
+	ListPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (*ListPublicationsResponse, error)
+	// StreamPublications sends the publications matching the request one at a time, as they are read.
+	StreamPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (CatalogService_StreamPublicationsClient, error)
+	CreatePublication(ctx context.Context, in *Publication, opts ...grpc.CallOption) (*Publication, error)
+	UpdatePublication(ctx context.Context, in *Publication, opts ...grpc.CallOption) (*Publication, error)
+	DeletePublication(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetWork(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Work, error)
+	ListWorks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListWorksResponse, error)
+	CreateWork(ctx context.Context, in *Work, opts ...grpc.CallOption) (*Work, error)
+	UpdateWork(ctx context.Context, in *Work, opts ...grpc.CallOption) (*Work, error)
+	DeleteWork(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetAuthor(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Author, error)
+	ListAuthors(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error)
+	CreateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error)
+	UpdateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error)
+	DeleteAuthor(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type catalogServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCatalogServiceClient(cc grpc.ClientConnInterface) CatalogServiceClient {
+	return &catalogServiceClient{cc}
+}
+
+func (c *catalogServiceClient) GetPublication(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Publication, error) {
+	out := new(Publication)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/GetPublication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) ListPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (*ListPublicationsResponse, error) {
+	out := new(ListPublicationsResponse)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/ListPublications", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) StreamPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (CatalogService_StreamPublicationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CatalogService_ServiceDesc.Streams[0], "/books.catalog.v1.CatalogService/StreamPublications", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &catalogServiceStreamPublicationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CatalogService_StreamPublicationsClient interface {
+	Recv() (*Publication, error)
+	grpc.ClientStream
+}
+
+type catalogServiceStreamPublicationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *catalogServiceStreamPublicationsClient) Recv() (*Publication, error) {
+	m := new(Publication)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *catalogServiceClient) CreatePublication(ctx context.Context, in *Publication, opts ...grpc.CallOption) (*Publication, error) {
+	out := new(Publication)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/CreatePublication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) UpdatePublication(ctx context.Context, in *Publication, opts ...grpc.CallOption) (*Publication, error) {
+	out := new(Publication)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/UpdatePublication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) DeletePublication(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/DeletePublication", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) GetWork(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Work, error) {
+	out := new(Work)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/GetWork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) ListWorks(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListWorksResponse, error) {
+	out := new(ListWorksResponse)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/ListWorks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) CreateWork(ctx context.Context, in *Work, opts ...grpc.CallOption) (*Work, error) {
+	out := new(Work)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/CreateWork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) UpdateWork(ctx context.Context, in *Work, opts ...grpc.CallOption) (*Work, error) {
+	out := new(Work)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/UpdateWork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) DeleteWork(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/DeleteWork", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) GetAuthor(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/GetAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) ListAuthors(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error) {
+	out := new(ListAuthorsResponse)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/ListAuthors", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) CreateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/CreateAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) UpdateAuthor(ctx context.Context, in *Author, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/UpdateAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *catalogServiceClient) DeleteAuthor(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, "/books.catalog.v1.CatalogService/DeleteAuthor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CatalogServiceServer is the server API for CatalogService service.
+// All implementations must embed UnimplementedCatalogServiceServer
+// for forward compatibility
+type CatalogServiceServer interface {
+	GetPublication(context.Context, *GetRequest) (*Publication, error)
+	ListPublications(context.Context, *ListPublicationsRequest) (*ListPublicationsResponse, error)
+	// StreamPublications sends the publications matching the request one at a time, as they are read.
+	StreamPublications(*ListPublicationsRequest, CatalogService_StreamPublicationsServer) error
+	CreatePublication(context.Context, *Publication) (*Publication, error)
+	UpdatePublication(context.Context, *Publication) (*Publication, error)
+	DeletePublication(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	GetWork(context.Context, *GetRequest) (*Work, error)
+	ListWorks(context.Context, *ListRequest) (*ListWorksResponse, error)
+	CreateWork(context.Context, *Work) (*Work, error)
+	UpdateWork(context.Context, *Work) (*Work, error)
+	DeleteWork(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	GetAuthor(context.Context, *GetRequest) (*Author, error)
+	ListAuthors(context.Context, *ListRequest) (*ListAuthorsResponse, error)
+	CreateAuthor(context.Context, *Author) (*Author, error)
+	UpdateAuthor(context.Context, *Author) (*Author, error)
+	DeleteAuthor(context.Context, *DeleteRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedCatalogServiceServer()
+}
+
+// UnimplementedCatalogServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCatalogServiceServer struct {
+}
+
+func (UnimplementedCatalogServiceServer) GetPublication(context.Context, *GetRequest) (*Publication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPublication not implemented")
+}
+func (UnimplementedCatalogServiceServer) ListPublications(context.Context, *ListPublicationsRequest) (*ListPublicationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPublications not implemented")
+}
+func (UnimplementedCatalogServiceServer) StreamPublications(*ListPublicationsRequest, CatalogService_StreamPublicationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPublications not implemented")
+}
+func (UnimplementedCatalogServiceServer) CreatePublication(context.Context, *Publication) (*Publication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePublication not implemented")
+}
+func (UnimplementedCatalogServiceServer) UpdatePublication(context.Context, *Publication) (*Publication, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePublication not implemented")
+}
+func (UnimplementedCatalogServiceServer) DeletePublication(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeletePublication not implemented")
+}
+func (UnimplementedCatalogServiceServer) GetWork(context.Context, *GetRequest) (*Work, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWork not implemented")
+}
+func (UnimplementedCatalogServiceServer) ListWorks(context.Context, *ListRequest) (*ListWorksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorks not implemented")
+}
+func (UnimplementedCatalogServiceServer) CreateWork(context.Context, *Work) (*Work, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateWork not implemented")
+}
+func (UnimplementedCatalogServiceServer) UpdateWork(context.Context, *Work) (*Work, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateWork not implemented")
+}
+func (UnimplementedCatalogServiceServer) DeleteWork(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteWork not implemented")
+}
+func (UnimplementedCatalogServiceServer) GetAuthor(context.Context, *GetRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthor not implemented")
+}
+func (UnimplementedCatalogServiceServer) ListAuthors(context.Context, *ListRequest) (*ListAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthors not implemented")
+}
+func (UnimplementedCatalogServiceServer) CreateAuthor(context.Context, *Author) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAuthor not implemented")
+}
+func (UnimplementedCatalogServiceServer) UpdateAuthor(context.Context, *Author) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateAuthor not implemented")
+}
+func (UnimplementedCatalogServiceServer) DeleteAuthor(context.Context, *DeleteRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAuthor not implemented")
+}
+func (UnimplementedCatalogServiceServer) mustEmbedUnimplementedCatalogServiceServer() {}
+
+// UnsafeCatalogServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CatalogServiceServer will
+// result in compilation errors.
+type UnsafeCatalogServiceServer interface {
+	mustEmbedUnimplementedCatalogServiceServer()
+}
+
+func RegisterCatalogServiceServer(s grpc.ServiceRegistrar, srv CatalogServiceServer) {
+	s.RegisterService(&CatalogService_ServiceDesc, srv)
+}
+
+func _CatalogService_GetPublication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).GetPublication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/GetPublication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).GetPublication(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_ListPublications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPublicationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).ListPublications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/ListPublications",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).ListPublications(ctx, req.(*ListPublicationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_StreamPublications_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListPublicationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CatalogServiceServer).StreamPublications(m, &catalogServiceStreamPublicationsServer{stream})
+}
+
+type CatalogService_StreamPublicationsServer interface {
+	Send(*Publication) error
+	grpc.ServerStream
+}
+
+type catalogServiceStreamPublicationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *catalogServiceStreamPublicationsServer) Send(m *Publication) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CatalogService_CreatePublication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Publication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).CreatePublication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/CreatePublication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).CreatePublication(ctx, req.(*Publication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_UpdatePublication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Publication)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).UpdatePublication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/UpdatePublication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).UpdatePublication(ctx, req.(*Publication))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_DeletePublication_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).DeletePublication(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/DeletePublication",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).DeletePublication(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_GetWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).GetWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/GetWork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).GetWork(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_ListWorks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).ListWorks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/ListWorks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).ListWorks(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_CreateWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Work)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).CreateWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/CreateWork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).CreateWork(ctx, req.(*Work))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_UpdateWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Work)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).UpdateWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/UpdateWork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).UpdateWork(ctx, req.(*Work))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_DeleteWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).DeleteWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/DeleteWork",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).DeleteWork(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_GetAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).GetAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/GetAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).GetAuthor(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_ListAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).ListAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/ListAuthors",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).ListAuthors(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_CreateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Author)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).CreateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/CreateAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).CreateAuthor(ctx, req.(*Author))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_UpdateAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Author)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).UpdateAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/UpdateAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).UpdateAuthor(ctx, req.(*Author))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CatalogService_DeleteAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CatalogServiceServer).DeleteAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/books.catalog.v1.CatalogService/DeleteAuthor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CatalogServiceServer).DeleteAuthor(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CatalogService_ServiceDesc is the grpc.ServiceDesc for CatalogService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CatalogService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "books.catalog.v1.CatalogService",
+	HandlerType: (*CatalogServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPublication",
+			Handler:    _CatalogService_GetPublication_Handler,
+		},
+		{
+			MethodName: "ListPublications",
+			Handler:    _CatalogService_ListPublications_Handler,
+		},
+		{
+			MethodName: "CreatePublication",
+			Handler:    _CatalogService_CreatePublication_Handler,
+		},
+		{
+			MethodName: "UpdatePublication",
+			Handler:    _CatalogService_UpdatePublication_Handler,
+		},
+		{
+			MethodName: "DeletePublication",
+			Handler:    _CatalogService_DeletePublication_Handler,
+		},
+		{
+			MethodName: "GetWork",
+			Handler:    _CatalogService_GetWork_Handler,
+		},
+		{
+			MethodName: "ListWorks",
+			Handler:    _CatalogService_ListWorks_Handler,
+		},
+		{
+			MethodName: "CreateWork",
+			Handler:    _CatalogService_CreateWork_Handler,
+		},
+		{
+			MethodName: "UpdateWork",
+			Handler:    _CatalogService_UpdateWork_Handler,
+		},
+		{
+			MethodName: "DeleteWork",
+			Handler:    _CatalogService_DeleteWork_Handler,
+		},
+		{
+			MethodName: "GetAuthor",
+			Handler:    _CatalogService_GetAuthor_Handler,
+		},
+		{
+			MethodName: "ListAuthors",
+			Handler:    _CatalogService_ListAuthors_Handler,
+		},
+		{
+			MethodName: "CreateAuthor",
+			Handler:    _CatalogService_CreateAuthor_Handler,
+		},
+		{
+			MethodName: "UpdateAuthor",
+			Handler:    _CatalogService_UpdateAuthor_Handler,
+		},
+		{
+			MethodName: "DeleteAuthor",
+			Handler:    _CatalogService_DeleteAuthor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPublications",
+			Handler:       _CatalogService_StreamPublications_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "catalog/v1/catalog.proto",
+}
@@ -0,0 +1,138 @@
+package catalog
+
+import (
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/catalog/catalogpb"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// LocationToProto returns the message of the given location.
+func LocationToProto(l *location.Location) *catalogpb.Location {
+	return &catalogpb.Location{Id: int64(l.ID), City: l.City, Country: l.Country, Region: l.Region}
+}
+
+// LocationFromProto returns the location of the given message, which may be nil.
+func LocationFromProto(m *catalogpb.Location) location.Location {
+	if m == nil {
+		return location.Location{}
+	}
+	return location.Location{ID: int(m.Id), City: m.City, Country: m.Country, Region: m.Region}
+}
+
+// AuthorToProto returns the message of the given author.
+func AuthorToProto(a *author.Author) *catalogpb.Author {
+	return &catalogpb.Author{
+		Id:           int64(a.ID),
+		FirstName:    a.FirstName,
+		LastName:     a.LastName,
+		Gender:       a.Gender,
+		DateOfBirth:  a.DateOfBirth,
+		PlaceOfBirth: LocationToProto(&a.PlaceOfBirth),
+	}
+}
+
+// AuthorFromProto returns the author of the given message, which may be nil.
+func AuthorFromProto(m *catalogpb.Author) author.Author {
+	if m == nil {
+		return author.Author{}
+	}
+	return author.Author{
+		ID:           int(m.Id),
+		FirstName:    m.FirstName,
+		LastName:     m.LastName,
+		Gender:       m.Gender,
+		DateOfBirth:  m.DateOfBirth,
+		PlaceOfBirth: LocationFromProto(m.PlaceOfBirth),
+	}
+}
+
+// WorkToProto returns the message of the given work.
+func WorkToProto(w *work.Work) *catalogpb.Work {
+	return &catalogpb.Work{
+		Id:               int64(w.ID),
+		Description:      w.Description,
+		InitialPubDate:   w.InitialPubDate,
+		OriginalLanguage: w.OriginalLanguage,
+		Title:            w.Title,
+		Series:           w.Series,
+		SeriesIndex:      w.SeriesIndex,
+		Author:           AuthorToProto(&w.Author),
+	}
+}
+
+// WorkFromProto returns the work of the given message, which may be nil.
+func WorkFromProto(m *catalogpb.Work) work.Work {
+	if m == nil {
+		return work.Work{}
+	}
+	return work.Work{
+		ID:               int(m.Id),
+		Description:      m.Description,
+		InitialPubDate:   m.InitialPubDate,
+		OriginalLanguage: m.OriginalLanguage,
+		Title:            m.Title,
+		Series:           m.Series,
+		SeriesIndex:      m.SeriesIndex,
+		Author:           AuthorFromProto(m.Author),
+	}
+}
+
+// PublicationToProto returns the message of the given publication.
+func PublicationToProto(p *publication.Publication) *catalogpb.Publication {
+	return &catalogpb.Publication{
+		Id:             int64(p.ID),
+		EditionPubDate: p.EditionPubDate,
+		Format:         p.Format,
+		ImageUrl:       p.ImageURL,
+		Isbn:           p.ISBN,
+		Isbn13:         p.ISBN13,
+		Language:       p.Language,
+		NumPages:       int32(p.NumPages),
+		Publisher:      p.Publisher,
+		Edition:        p.Edition,
+		Work:           WorkToProto(&p.Work),
+	}
+}
+
+// PublicationFromProto returns the publication of the given message, which may be nil.
+func PublicationFromProto(m *catalogpb.Publication) publication.Publication {
+	if m == nil {
+		return publication.Publication{}
+	}
+	return publication.Publication{
+		ID:             int(m.Id),
+		EditionPubDate: m.EditionPubDate,
+		Format:         m.Format,
+		ImageURL:       m.ImageUrl,
+		ISBN:           m.Isbn,
+		ISBN13:         m.Isbn13,
+		Language:       m.Language,
+		NumPages:       int(m.NumPages),
+		Publisher:      m.Publisher,
+		Edition:        m.Edition,
+		Work:           WorkFromProto(m.Work),
+	}
+}
+
+// FilterFromProto returns the publication filter of the given request.
+func FilterFromProto(m *catalogpb.ListPublicationsRequest) *publication.Filter {
+	f := &publication.Filter{
+		AuthorID:  int(m.AuthorId),
+		WorkID:    int(m.WorkId),
+		Format:    m.Format,
+		Language:  m.Language,
+		Publisher: m.Publisher,
+		Series:    m.Series,
+		Genre:     m.Genre,
+		Search:    m.Search,
+		Newest:    m.Newest,
+		Limit:     int(m.PageSize),
+		Offset:    int(m.Offset),
+	}
+	for _, id := range m.Ids {
+		f.IDs = append(f.IDs, int(id))
+	}
+	return f
+}
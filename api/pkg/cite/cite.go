@@ -0,0 +1,317 @@
+package cite
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// Formats that citations can be generated in.
+const (
+	BibTeX  = "bibtex"
+	RIS     = "ris"
+	CSLJSON = "csl-json"
+)
+
+// ErrFormat is returned for formats other than BibTeX, RIS and CSL-JSON.
+var ErrFormat = errors.New("unknown citation format")
+
+// stopwords are skipped when picking the title word of a citation key.
+var stopwords = map[string]bool{"a": true, "an": true, "the": true, "of": true, "on": true, "in": true}
+
+// folds maps accented Latin letters onto their unaccented ASCII counterparts for citation keys.
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// bibtexEscaper escapes the characters that are special to (La)TeX. Replacements are not rescanned,
+// so the braces of \textbackslash{} are left alone.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "&", `\&`, "%", `\%`, "$", `\$`,
+	"#", `\#`, "_", `\_`, "^", `\^{}`, "~", `\~{}`,
+)
+
+// Key returns the citation key of the given publication: the author's last name, the year of the
+// edition and the first significant word of the title, folded to lowercase ASCII, e.g. fitzgerald2004great.
+// It only depends on the publication's data, so it is the same every time the publication is cited.
+func Key(pub *publication.Publication) string {
+	year := year(pub.EditionPubDate)
+	if year == "" {
+		year = "nd"
+	}
+
+	var word string
+	for _, w := range strings.FieldsFunc(pub.Work.Title, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		if w = fold(w); w != "" && !stopwords[w] {
+			word = w
+			break
+		}
+	}
+
+	name := fold(pub.Work.Author.LastName)
+	if name == "" {
+		name = "anon"
+	}
+	return name + year + word
+}
+
+// Keys returns the citation keys of the given publications, appending a, b, c... to keys
+// that more than one of them share, in the order the publications are given.
+func Keys(pubs publication.Publications) []string {
+	keys := make([]string, len(pubs))
+	counts := map[string]int{}
+	for i := range pubs {
+		keys[i] = Key(&pubs[i])
+		counts[keys[i]]++
+	}
+
+	seen := map[string]int{}
+	for i, key := range keys {
+		if counts[key] > 1 {
+			keys[i] = key + suffix(seen[key])
+			seen[key]++
+		}
+	}
+	return keys
+}
+
+// Write writes citations of the given publications to w in the given format.
+func Write(w io.Writer, format string, pubs publication.Publications) error {
+	keys := Keys(pubs)
+	switch format {
+	case BibTeX:
+		for i := range pubs {
+			if i > 0 {
+				io.WriteString(w, "\n")
+			}
+			if err := writeBibTeX(w, keys[i], &pubs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case RIS:
+		for i := range pubs {
+			if err := writeRIS(w, &pubs[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSLJSON:
+		items := make([]cslItem, len(pubs))
+		for i := range pubs {
+			items[i] = newCSLItem(keys[i], &pubs[i])
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	default:
+		return fmt.Errorf("%w: %q", ErrFormat, format)
+	}
+}
+
+// ContentType returns the media type of the given format.
+func ContentType(format string) string {
+	switch format {
+	case RIS:
+		return "application/x-research-info-systems"
+	case CSLJSON:
+		return "application/vnd.citationstyles.csl+json"
+	default:
+		return "application/x-bibtex; charset=utf-8"
+	}
+}
+
+func writeBibTeX(w io.Writer, key string, pub *publication.Publication) error {
+	wk := &pub.Work
+	au := &wk.Author
+
+	name := au.LastName
+	if au.FirstName != "" {
+		name += ", " + au.FirstName
+	}
+	fields := [][2]string{
+		{"author", bibtex(name)},
+		// Double braces keep BibTeX styles from changing the case of the title.
+		{"title", "{" + bibtex(wk.Title) + "}"},
+		{"publisher", bibtex(pub.Publisher)},
+		{"year", year(pub.EditionPubDate)},
+		{"edition", bibtex(pub.Edition)},
+		{"isbn", pub.ISBN13},
+		{"pagetotal", pages(pub.NumPages)},
+		{"language", bibtex(strings.ToLower(pub.Language))},
+	}
+	if y := year(wk.InitialPubDate); y != "" && y != year(pub.EditionPubDate) {
+		fields = append(fields, [2]string{"origdate", y})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@book{%s,\n", key)
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Fprintf(&b, "  %s = {%s},\n", f[0], f[1])
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeRIS(w io.Writer, pub *publication.Publication) error {
+	wk := &pub.Work
+	au := &wk.Author
+
+	name := au.LastName
+	if au.FirstName != "" {
+		name += ", " + au.FirstName
+	}
+	var date string
+	if d := day(pub.EditionPubDate); d != "" {
+		date = strings.ReplaceAll(d, "-", "/")
+	}
+	fields := [][2]string{
+		{"TY", "BOOK"},
+		{"AU", name},
+		{"TI", wk.Title},
+		{"PB", pub.Publisher},
+		{"PY", year(pub.EditionPubDate)},
+		{"DA", date},
+		{"ET", pub.Edition},
+		{"SN", pub.ISBN13},
+		{"SP", pages(pub.NumPages)},
+		{"LA", pub.Language},
+		{"ER", ""},
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		if f[1] != "" || f[0] == "ER" {
+			// RIS has no escaping, so values are kept to a single line.
+			fmt.Fprintf(&b, "%s  - %s\r\n", f[0], strings.Join(strings.Fields(f[1]), " "))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+type cslItem struct {
+	ID            string    `json:"id"`
+	Type          string    `json:"type"`
+	Title         string    `json:"title"`
+	Author        []cslName `json:"author,omitempty"`
+	Publisher     string    `json:"publisher,omitempty"`
+	Issued        *cslDate  `json:"issued,omitempty"`
+	OriginalDate  *cslDate  `json:"original-date,omitempty"`
+	Edition       string    `json:"edition,omitempty"`
+	ISBN          string    `json:"ISBN,omitempty"`
+	NumberOfPages string    `json:"number-of-pages,omitempty"`
+	Language      string    `json:"language,omitempty"`
+}
+
+func newCSLItem(key string, pub *publication.Publication) cslItem {
+	wk := &pub.Work
+	au := &wk.Author
+
+	item := cslItem{
+		ID:            key,
+		Type:          "book",
+		Title:         wk.Title,
+		Publisher:     pub.Publisher,
+		Issued:        cslDateOf(pub.EditionPubDate),
+		Edition:       pub.Edition,
+		ISBN:          pub.ISBN13,
+		NumberOfPages: pages(pub.NumPages),
+		Language:      pub.Language,
+	}
+	if au.LastName != "" {
+		item.Author = []cslName{{Family: au.LastName, Given: au.FirstName}}
+	}
+	if year(wk.InitialPubDate) != year(pub.EditionPubDate) {
+		item.OriginalDate = cslDateOf(wk.InitialPubDate)
+	}
+	return item
+}
+
+func cslDateOf(timestamp string) *cslDate {
+	var parts []int
+	for _, p := range strings.Split(day(timestamp), "-") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &cslDate{DateParts: [][]int{parts}}
+}
+
+func bibtex(s string) string {
+	return bibtexEscaper.Replace(s)
+}
+
+// fold lowercases s and reduces it to ASCII letters and digits.
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+		case folds[r] != "":
+			b.WriteString(folds[r])
+		}
+	}
+	return b.String()
+}
+
+// suffix returns the n-th disambiguating suffix: a, b, ..., z, aa, ab, ...
+func suffix(n int) string {
+	if n < 26 {
+		return string(rune('a' + n))
+	}
+	return suffix(n/26-1) + suffix(n%26)
+}
+
+func pages(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// day returns the date part of a timestamp as stored in the database.
+func day(timestamp string) string {
+	if len(timestamp) > 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
+
+func year(timestamp string) string {
+	if len(timestamp) >= 4 {
+		return timestamp[:4]
+	}
+	return ""
+}
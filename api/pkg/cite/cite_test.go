@@ -0,0 +1,118 @@
+package cite_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/cite"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+func testPublication() publication.Publication {
+	pub := publication.Publication{
+		ID:             1,
+		EditionPubDate: "2004-09-30T00:00:00Z",
+		ISBN13:         "9780743273565",
+		NumPages:       180,
+		Publisher:      "Simon & Schuster",
+		Edition:        "2nd",
+		Language:       "English",
+		Work: work.Work{
+			Title:          "The Great Gatsby",
+			InitialPubDate: "1925-04-10T00:00:00Z",
+		},
+	}
+	pub.Work.Author.FirstName = "F. Scott"
+	pub.Work.Author.LastName = "Fitzgerald"
+	return pub
+}
+
+func TestKey(t *testing.T) {
+	t.Run("Simple", func(t *testing.T) {
+		pub := testPublication()
+		helpers.AssertEqual(t, "fitzgerald2004great", cite.Key(&pub))
+	})
+
+	t.Run("Diacritics", func(t *testing.T) {
+		pub := testPublication()
+		pub.Work.Author.LastName = "García Márquez"
+		pub.Work.Title = "El amor en los tiempos del cólera"
+		pub.EditionPubDate = ""
+		helpers.AssertEqual(t, "garciamarquezndel", cite.Key(&pub))
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		a, b, c := testPublication(), testPublication(), testPublication()
+		c.Work.Title = "Tender Is the Night"
+		keys := cite.Keys(publication.Publications{a, b, c})
+		helpers.AssertEqual(t, []string{"fitzgerald2004greata", "fitzgerald2004greatb", "fitzgerald2004tender"}, keys)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("BibTeX", func(t *testing.T) {
+		pub := testPublication()
+		pub.Work.Title = "100% Pure_Gatsby {#1}"
+
+		var buf bytes.Buffer
+		err := cite.Write(&buf, cite.BibTeX, publication.Publications{pub})
+		helpers.AssertEqual(t, nil, err)
+
+		want := "@book{fitzgerald2004100,\n" +
+			"  author = {Fitzgerald, F. Scott},\n" +
+			"  title = {{100\\% Pure\\_Gatsby \\{\\#1\\}}},\n" +
+			"  publisher = {Simon \\& Schuster},\n" +
+			"  year = {2004},\n" +
+			"  edition = {2nd},\n" +
+			"  isbn = {9780743273565},\n" +
+			"  pagetotal = {180},\n" +
+			"  language = {english},\n" +
+			"  origdate = {1925},\n" +
+			"}\n"
+		helpers.AssertEqual(t, want, buf.String())
+	})
+
+	t.Run("RIS", func(t *testing.T) {
+		pub := testPublication()
+		pub.Work.Title = "The Great\nGatsby"
+
+		var buf bytes.Buffer
+		err := cite.Write(&buf, cite.RIS, publication.Publications{pub})
+		helpers.AssertEqual(t, nil, err)
+
+		want := strings.Join([]string{
+			"TY  - BOOK", "AU  - Fitzgerald, F. Scott", "TI  - The Great Gatsby", "PB  - Simon & Schuster",
+			"PY  - 2004", "DA  - 2004/09/30", "ET  - 2nd", "SN  - 9780743273565", "SP  - 180",
+			"LA  - English", "ER  - ", "",
+		}, "\r\n")
+		helpers.AssertEqual(t, want, buf.String())
+	})
+
+	t.Run("CSLJSON", func(t *testing.T) {
+		pub := testPublication()
+
+		var buf bytes.Buffer
+		err := cite.Write(&buf, cite.CSLJSON, publication.Publications{pub})
+		helpers.AssertEqual(t, nil, err)
+
+		var items []map[string]interface{}
+		helpers.AssertEqual(t, nil, json.Unmarshal(buf.Bytes(), &items))
+		helpers.AssertEqual(t, 1, len(items))
+		helpers.AssertEqual(t, "fitzgerald2004great", items[0]["id"])
+		helpers.AssertEqual(t, "book", items[0]["type"])
+		helpers.AssertEqual(t, "Simon & Schuster", items[0]["publisher"])
+		helpers.AssertEqual(t, "180", items[0]["number-of-pages"])
+		helpers.AssertEqual(t, []interface{}{[]interface{}{2004.0, 9.0, 30.0}}, items[0]["issued"].(map[string]interface{})["date-parts"])
+		helpers.AssertEqual(t, []interface{}{map[string]interface{}{"family": "Fitzgerald", "given": "F. Scott"}}, items[0]["author"])
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		err := cite.Write(&bytes.Buffer{}, "apa", publication.Publications{testPublication()})
+		helpers.AssertEqual(t, true, errors.Is(err, cite.ErrFormat))
+	})
+}
@@ -0,0 +1,439 @@
+// Package dedup finds authors and works that were entered more than once, e.g. because of a typo in a name or a
+// wrong birth date, and merges them.
+package dedup
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/lib/pq"
+)
+
+// DefaultThreshold is the similarity above which entries are proposed as duplicates when no threshold is given.
+const DefaultThreshold = 0.9
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	CopyGenres
+	LockAuthors
+	LockWorks
+	MoveAliases
+	PostAliases
+)
+
+// An AuthorCandidate is a pair of authors that may be the same person, with how similar their names are.
+type AuthorCandidate struct {
+	Score   float64        `json:"score"`
+	Authors author.Authors `json:"authors"`
+}
+
+// A WorkCandidate is a pair of works by the same author that may be the same work, with how similar their titles are.
+type WorkCandidate struct {
+	Score float64    `json:"score"`
+	Works work.Works `json:"works"`
+}
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+
+	PublicationService publication.Service
+	RedirectService    redirect.Service
+}
+
+// WithTx returns a copy of the receiver whose statements, and those of its dependencies,
+// are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{
+		DB:                 s.DB.WithTx(tx),
+		PublicationService: *s.PublicationService.WithTx(tx),
+		RedirectService:    redirect.Service{DB: s.RedirectService.DB.WithTx(tx)},
+	}
+}
+
+// WithActor returns a copy of the receiver whose changes, and those of its dependencies,
+// are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{
+		DB:                 s.DB.WithActor(actor),
+		PublicationService: *s.PublicationService.WithActor(actor),
+		RedirectService:    s.RedirectService,
+	}
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case CopyGenres:
+		return `INSERT INTO genre (work_id, name)
+                        SELECT $1, name FROM genre WHERE work_id = $2
+                        ON CONFLICT (work_id, name) DO NOTHING`
+	case LockAuthors:
+		// Rows are locked in order of id, so that merges of the same authors cannot deadlock.
+		return "SELECT id FROM author WHERE id = ANY($1) ORDER BY id FOR UPDATE"
+	case LockWorks:
+		return "SELECT id FROM work WHERE id = ANY($1) ORDER BY id FOR UPDATE"
+	case MoveAliases:
+		return "UPDATE author_alias SET author_id = $1 WHERE author_id = ANY($2)"
+	case PostAliases:
+		// Aliases must have a first and a last name, and names that are already aliases are kept as they are.
+		return `INSERT INTO author_alias (author_id, first_name, last_name)
+                        SELECT winner.id, loser.first_name, loser.last_name
+                        FROM author AS loser
+                        JOIN author AS winner ON winner.id = $1
+                        WHERE loser.id = ANY($2) AND loser.first_name <> '' AND loser.last_name <> ''
+                        AND (loser.first_name, loser.last_name) <> (winner.first_name, winner.last_name)
+                        ON CONFLICT (first_name, last_name) DO NOTHING`
+	default:
+		return ""
+	}
+}
+
+// FindAuthorDuplicates proposes the pairs of authors whose normalized names are at least threshold similar, most
+// similar first. Authors whose names only differ in case, accents or punctuation are given a score of 1.
+func (s *Service) FindAuthorDuplicates(threshold float64) (*status.Status, []AuthorCandidate) {
+	stat, authors := s.PublicationService.WorkService.AuthorService.GetAuthors()
+	if stat.Err() != nil {
+		return stat, nil
+	}
+
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = normalize(a.FirstName + " " + a.LastName)
+	}
+
+	candidates := []AuthorCandidate{}
+	for i := range authors {
+		for j := i + 1; j < len(authors); j++ {
+			if score := Similarity(names[i], names[j]); score >= threshold {
+				pair := author.Authors{authors[i], authors[j]}
+				candidates = append(candidates, AuthorCandidate{Score: score, Authors: pair})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return status.New(status.OK, ""), candidates
+}
+
+// FindWorkDuplicates proposes the pairs of works by the same author whose normalized titles are at least threshold
+// similar, most similar first. Leading articles are ignored, so that "The Hobbit" matches "Hobbit".
+func (s *Service) FindWorkDuplicates(threshold float64) (*status.Status, []WorkCandidate) {
+	stat, works := s.PublicationService.WorkService.GetWorks()
+	if stat.Err() != nil {
+		return stat, nil
+	}
+
+	byAuthor := map[int][]int{}
+	titles := make([]string, len(works))
+	for i, w := range works {
+		byAuthor[w.Author.ID] = append(byAuthor[w.Author.ID], i)
+		titles[i] = normalizeTitle(w.Title)
+	}
+
+	candidates := []WorkCandidate{}
+	for i := range works {
+		for _, j := range byAuthor[works[i].Author.ID] {
+			if j <= i {
+				continue
+			}
+			if score := Similarity(titles[i], titles[j]); score >= threshold {
+				candidates = append(candidates, WorkCandidate{Score: score, Works: work.Works{works[i], works[j]}})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	return status.New(status.OK, ""), candidates
+}
+
+// MergeAuthors merges the authors matching loserIDs into the one matching winnerID, in one transaction. Their works
+// are moved to the winner, or merged as described by MergeWorks into the winner's work of the same title, if any.
+// Their aliases are moved to the winner too, and their names become aliases of the winner, so that they are still
+// found by name. The losers are then deleted, and redirects to the winner are left for their ids.
+func (s *Service) MergeAuthors(winnerID int, loserIDs []int) (*status.Status, *author.Author) {
+	if stat := checkMerge("author", winnerID, loserIDs); stat.Err() != nil {
+		return stat, nil
+	}
+
+	var merged *author.Author
+	stat := s.transaction(func(s *Service) *status.Status {
+		if _, err := s.DB.Exec(s.Query(LockAuthors), pq.Array(append([]int{winnerID}, loserIDs...))); err != nil {
+			log.Printf("[MergeAuthors] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+
+		ws := &s.PublicationService.WorkService
+		as := &ws.AuthorService
+		for _, id := range append([]int{winnerID}, loserIDs...) {
+			if stat, _ := as.GetAuthor(id); stat.Err() != nil {
+				return stat
+			}
+		}
+
+		stat, works := ws.GetWorksByAuthors(append([]int{winnerID}, loserIDs...))
+		if stat.Err() != nil {
+			return stat
+		}
+		titles := map[string]int{}
+		for _, w := range works {
+			if w.Author.ID == winnerID {
+				titles[w.Title] = w.ID
+			}
+		}
+		for _, w := range works {
+			if w.Author.ID == winnerID {
+				continue
+			}
+			if id, ok := titles[w.Title]; ok {
+				if stat, _ := s.mergeWorks(id, []int{w.ID}); stat.Err() != nil {
+					return stat
+				}
+				continue
+			}
+			moved := &work.Work{ID: w.ID, Author: author.Author{ID: winnerID}}
+			if stat, _ := ws.PatchWork(moved, "author"); stat.Err() != nil {
+				return stat
+			}
+			titles[w.Title] = w.ID
+		}
+
+		for _, query := range []postgres.Query{MoveAliases, PostAliases} {
+			if _, err := s.DB.Exec(s.Query(query), winnerID, pq.Array(loserIDs)); err != nil {
+				log.Printf("[MergeAuthors] %s", err)
+				return status.New(status.InternalServerError, err.Error())
+			}
+		}
+
+		for _, id := range loserIDs {
+			if stat := as.DeleteAuthor(id); stat.Err() != nil {
+				return stat
+			}
+			if stat := s.RedirectService.PostRedirect(author.Entity, id, winnerID); stat.Err() != nil {
+				return stat
+			}
+		}
+
+		stat, merged = as.GetAuthor(winnerID)
+		return stat
+	})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	return stat, merged
+}
+
+// MergeWorks merges the works matching loserIDs into the one matching winnerID, in one transaction. Their
+// publications are moved to the winner, which also takes on their genres. The losers are then deleted, and
+// redirects to the winner are left for their ids.
+func (s *Service) MergeWorks(winnerID int, loserIDs []int) (*status.Status, *work.Work) {
+	if stat := checkMerge("work", winnerID, loserIDs); stat.Err() != nil {
+		return stat, nil
+	}
+
+	var merged *work.Work
+	stat := s.transaction(func(s *Service) *status.Status {
+		if _, err := s.DB.Exec(s.Query(LockWorks), pq.Array(append([]int{winnerID}, loserIDs...))); err != nil {
+			log.Printf("[MergeWorks] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+
+		var stat *status.Status
+		stat, merged = s.mergeWorks(winnerID, loserIDs)
+		return stat
+	})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	return stat, merged
+}
+
+// Similarity returns the Jaro-Winkler similarity of a and b: 1 if they are equal, 0 if they have nothing in common,
+// and more in between the longer the prefix they share.
+func Similarity(a string, b string) float64 {
+	if a == b {
+		return 1
+	}
+	r1, r2 := []rune(a), []rune(b)
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0
+	}
+
+	window := maxInt(len(r1), len(r2))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	matched1, matched2 := make([]bool, len(r1)), make([]bool, len(r2))
+	matches := 0
+	for i := range r1 {
+		for j := maxInt(0, i-window); j < len(r2) && j <= i+window; j++ {
+			if !matched2[j] && r1[i] == r2[j] {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range r1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if r1[i] != r2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(r1)) + m/float64(len(r2)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(r1) && prefix < len(r2) && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// mergeWorks merges the works matching loserIDs into the one matching winnerID, as described by MergeWorks, inside
+// the transaction of the receiver.
+func (s *Service) mergeWorks(winnerID int, loserIDs []int) (*status.Status, *work.Work) {
+	ps := &s.PublicationService
+	ws := &ps.WorkService
+	for _, id := range append([]int{winnerID}, loserIDs...) {
+		if stat, _ := ws.GetWork(id); stat.Err() != nil {
+			return stat, nil
+		}
+	}
+
+	stat, pubs := ps.FilterPublications(&publication.Filter{WorkIDs: loserIDs})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	for _, pub := range pubs {
+		moved := &publication.Publication{ID: pub.ID, Work: work.Work{ID: winnerID}}
+		if stat, _ := ps.PatchPublication(moved, "work"); stat.Err() != nil {
+			return stat, nil
+		}
+	}
+
+	for _, id := range loserIDs {
+		if _, err := s.DB.Exec(s.Query(CopyGenres), winnerID, id); err != nil {
+			log.Printf("[MergeWorks] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		if stat := ws.DeleteWork(id); stat.Code() != status.NoContent {
+			return stat, nil
+		}
+		if stat := s.RedirectService.PostRedirect(work.Entity, id, winnerID); stat.Err() != nil {
+			return stat, nil
+		}
+	}
+	return ws.GetWork(winnerID)
+}
+
+// transaction calls fn with a copy of the receiver whose statements are executed inside a transaction, which is
+// committed if fn succeeds and rolled back otherwise.
+func (s *Service) transaction(fn func(*Service) *status.Status) *status.Status {
+	var stat *status.Status
+	if err := s.DB.Transaction(func(tx *sql.Tx) error {
+		stat = fn(s.WithTx(tx))
+		return stat.Err()
+	}); err != nil && (stat == nil || stat.Err() == nil) {
+		log.Printf("[Transaction] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return stat
+}
+
+// checkMerge returns an error unless there is at least one loser to merge into the winner, and none is the winner.
+func checkMerge(entity string, winnerID int, loserIDs []int) *status.Status {
+	if len(loserIDs) == 0 {
+		return status.Newf(status.UnprocessableEntity, "No %ss were given to merge", entity)
+	}
+	seen := map[int]bool{winnerID: true}
+	for _, id := range loserIDs {
+		if seen[id] {
+			msg := fmt.Sprintf("The %s with id = %d cannot be merged into itself or twice", entity, id)
+			return status.New(status.UnprocessableEntity, msg)
+		}
+		seen[id] = true
+	}
+	return status.New(status.OK, "")
+}
+
+// articles are skipped at the start of titles, since they are often left out or moved to the end.
+var articles = []string{"the ", "a ", "an "}
+
+// normalizeTitle normalizes title as described by normalize, without its leading article.
+func normalizeTitle(title string) string {
+	t := normalize(title)
+	for _, article := range articles {
+		if strings.HasPrefix(t, article) && len(t) > len(article) {
+			return t[len(article):]
+		}
+	}
+	return t
+}
+
+// folds maps accented Latin letters onto their unaccented ASCII counterparts.
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// normalize lowercases s, reduces it to ASCII letters and digits, and separates its words by single spaces, so that
+// e.g. "J.R.R. Tolkien" and "j r r tolkien" are equal. Apostrophes are dropped rather than separating words.
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case folds[r] != "":
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(folds[r])
+		case r == '\'' || r == '’':
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
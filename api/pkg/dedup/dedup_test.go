@@ -0,0 +1,101 @@
+package dedup_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/test/data"
+)
+
+func TestSimilarity(t *testing.T) {
+	round := func(f float64) float64 { return math.Round(f*1000) / 1000 }
+
+	t.Run("Equal", func(t *testing.T) {
+		helpers.AssertEqual(t, 1.0, dedup.Similarity("sally rooney", "sally rooney"))
+	})
+
+	t.Run("NothingInCommon", func(t *testing.T) {
+		helpers.AssertEqual(t, 0.0, dedup.Similarity("abc", "xyz"))
+		helpers.AssertEqual(t, 0.0, dedup.Similarity("", "xyz"))
+	})
+
+	t.Run("Transposition", func(t *testing.T) {
+		helpers.AssertEqual(t, 0.961, round(dedup.Similarity("martha", "marhta")))
+	})
+
+	t.Run("OddTranspositions", func(t *testing.T) {
+		helpers.AssertEqual(t, 0.942, round(dedup.Similarity("abcdef", "abcfde")))
+	})
+
+	t.Run("CommonPrefix", func(t *testing.T) {
+		helpers.AssertEqual(t, 0.813, round(dedup.Similarity("dixon", "dicksonx")))
+	})
+
+	t.Run("Symmetric", func(t *testing.T) {
+		a, b := "fyodor dostoevsky", "fyodor dostoyevsky"
+		helpers.AssertEqual(t, dedup.Similarity(a, b), dedup.Similarity(b, a))
+		helpers.AssertEqual(t, true, dedup.Similarity(a, b) >= dedup.DefaultThreshold)
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	s := &dedup.Service{}
+
+	t.Run("NoLosers", func(t *testing.T) {
+		gotStatus, gotAuthor := s.MergeAuthors(1, nil)
+		helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+		helpers.AssertNil(t, gotAuthor)
+	})
+
+	t.Run("IntoItself", func(t *testing.T) {
+		gotStatus, gotAuthor := s.MergeAuthors(1, []int{2, 1})
+		helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+		helpers.AssertNil(t, gotAuthor)
+	})
+
+	t.Run("Twice", func(t *testing.T) {
+		gotStatus, _ := s.MergeAuthors(1, []int{2, 2})
+		helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+	})
+}
+
+func TestMergeAuthorsAliases(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	as := services.AuthorService
+	s := &dedup.Service{DB: ps.DB, PublicationService: *ps, RedirectService: redirect.Service{DB: ps.DB}}
+	authors := helpers.PostAuthors(t, as, data.GetAuthors(as))
+	winner, loser := authors[0], authors[1]
+	as.PostAlias(loser.ID, &author.Alias{FirstName: "Richard", LastName: "Bachman"})
+
+	gotStatus, _ := s.MergeAuthors(winner.ID, []int{loser.ID})
+	helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+
+	t.Run("Moved", func(t *testing.T) {
+		_, gotAliases := as.GetAliases(winner.ID)
+		names := []string{}
+		for _, alias := range gotAliases {
+			names = append(names, alias.FirstName+" "+alias.LastName)
+		}
+		helpers.AssertEqual(t, []string{"Richard Bachman", loser.FirstName + " " + loser.LastName}, names)
+	})
+
+	t.Run("LoserName", func(t *testing.T) {
+		gotStatus, gotAuthor := as.FindAuthor(loser.FirstName, loser.LastName, "")
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, winner.ID, gotAuthor.ID)
+	})
+
+	t.Run("Pseudonym", func(t *testing.T) {
+		gotStatus, gotAuthor := as.FindAuthor("Richard", "Bachman", "")
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, winner.ID, gotAuthor.ID)
+	})
+
+	cleanup()
+}
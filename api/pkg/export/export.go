@@ -0,0 +1,218 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+)
+
+// Formats that publications and shelves can be exported in.
+const (
+	CSV       = "csv"
+	NDJSON    = "ndjson"
+	Goodreads = "goodreads"
+)
+
+// ErrFormat is returned for formats other than CSV, NDJSON and Goodreads.
+var ErrFormat = errors.New("unknown export format")
+
+// publicationColumns are the CSV columns of a publication. They use the field names
+// understood by importer.ParseCSV so that an export can be imported again.
+var publicationColumns = []string{
+	"id", importer.Title, importer.Description, importer.InitialPubDate, importer.OriginalLanguage,
+	"series", "seriesIndex", importer.EditionPubDate, importer.Format, importer.ImageURL, importer.ISBN,
+	importer.ISBN13, importer.Language, importer.NumPages, importer.Publisher, importer.Edition,
+	importer.AuthorFirstName, importer.AuthorLastName, importer.AuthorGender, importer.AuthorDateOfBirth,
+}
+
+// shelfColumns are the CSV columns that follow the publication columns of a shelf entry.
+var shelfColumns = []string{"exclusiveShelf", "rating", "dateRead", "bookshelves"}
+
+// goodreadsColumns are the columns of goodreads_library_export.csv.
+var goodreadsColumns = []string{
+	"Book Id", "Title", "Author", "Author l-f", "Additional Authors", "ISBN", "ISBN13", "My Rating",
+	"Average Rating", "Publisher", "Binding", "Number of Pages", "Year Published", "Original Publication Year",
+	"Date Read", "Date Added", "Bookshelves", "Bookshelves with positions", "Exclusive Shelf", "My Review",
+	"Spoiler", "Private Notes", "Read Count", "Owned Copies",
+}
+
+// Encoder writes publications or shelf entries one at a time. Nothing is guaranteed to reach
+// the underlying writer until Flush is called.
+type Encoder interface {
+	EncodePublication(pub *publication.Publication) error
+	EncodeEntry(entry *shelf.Entry) error
+	Flush() error
+}
+
+// NewEncoder returns an encoder that writes to w in the given format. For CSV, shelves
+// selects whether the shelf columns are written after the publication columns.
+func NewEncoder(w io.Writer, format string, shelves bool) (Encoder, error) {
+	switch format {
+	case CSV:
+		enc := &csvEncoder{w: csv.NewWriter(w), shelves: shelves}
+		header := publicationColumns
+		if shelves {
+			header = append(append([]string{}, publicationColumns...), shelfColumns...)
+		}
+		return enc, enc.w.Write(header)
+	case NDJSON:
+		b := bufio.NewWriter(w)
+		return &ndjsonEncoder{b: b, enc: json.NewEncoder(b)}, nil
+	case Goodreads:
+		enc := &goodreadsEncoder{w: csv.NewWriter(w)}
+		return enc, enc.w.Write(goodreadsColumns)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrFormat, format)
+	}
+}
+
+// ContentType returns the media type of the given format.
+func ContentType(format string) string {
+	switch format {
+	case NDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension of the given format.
+func Extension(format string) string {
+	if format == NDJSON {
+		return "ndjson"
+	}
+	return "csv"
+}
+
+type csvEncoder struct {
+	w       *csv.Writer
+	shelves bool
+}
+
+func (e *csvEncoder) EncodePublication(pub *publication.Publication) error {
+	return e.w.Write(publicationRecord(pub))
+}
+
+func (e *csvEncoder) EncodeEntry(entry *shelf.Entry) error {
+	record := publicationRecord(&entry.Publication)
+	if e.shelves {
+		record = append(record,
+			entry.ExclusiveShelf,
+			strconv.Itoa(entry.Rating),
+			day(entry.DateRead),
+			strings.Join(entry.Bookshelves, ", "),
+		)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func publicationRecord(pub *publication.Publication) []string {
+	wk := &pub.Work
+	au := &wk.Author
+	var seriesIndex string
+	if wk.Series != "" {
+		seriesIndex = strconv.FormatFloat(wk.SeriesIndex, 'f', -1, 64)
+	}
+	return []string{
+		strconv.Itoa(pub.ID), wk.Title, wk.Description, day(wk.InitialPubDate), wk.OriginalLanguage,
+		wk.Series, seriesIndex, day(pub.EditionPubDate), pub.Format, pub.ImageURL, pub.ISBN,
+		pub.ISBN13, pub.Language, strconv.Itoa(pub.NumPages), pub.Publisher, pub.Edition,
+		au.FirstName, au.LastName, au.Gender, day(au.DateOfBirth),
+	}
+}
+
+type ndjsonEncoder struct {
+	b   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) EncodePublication(pub *publication.Publication) error {
+	return e.enc.Encode(pub)
+}
+
+func (e *ndjsonEncoder) EncodeEntry(entry *shelf.Entry) error {
+	return e.enc.Encode(entry)
+}
+
+func (e *ndjsonEncoder) Flush() error {
+	return e.b.Flush()
+}
+
+type goodreadsEncoder struct {
+	w *csv.Writer
+}
+
+func (e *goodreadsEncoder) EncodePublication(pub *publication.Publication) error {
+	return e.EncodeEntry(&shelf.Entry{Publication: *pub})
+}
+
+// EncodeEntry writes the entry the way Goodreads does, with ISBNs wrapped in ="..." and the
+// series appended to the title, both of which importer.ParseGoodreads undoes.
+func (e *goodreadsEncoder) EncodeEntry(entry *shelf.Entry) error {
+	pub := &entry.Publication
+	wk := &pub.Work
+	au := &wk.Author
+
+	title := wk.Title
+	if wk.Series != "" {
+		title += fmt.Sprintf(" (%s, #%s)", wk.Series, strconv.FormatFloat(wk.SeriesIndex, 'f', -1, 64))
+	}
+	name := strings.TrimSpace(au.FirstName + " " + au.LastName)
+	inverted := au.LastName
+	if au.FirstName != "" {
+		inverted += ", " + au.FirstName
+	}
+
+	var readCount, dateRead string
+	if entry.ExclusiveShelf == shelf.Read {
+		readCount = "1"
+	}
+	if d := day(entry.DateRead); d != "" {
+		dateRead = strings.ReplaceAll(d, "-", "/")
+	}
+	var positions []string
+	for i, s := range entry.Bookshelves {
+		positions = append(positions, fmt.Sprintf("%s (#%d)", s, i+1))
+	}
+
+	return e.w.Write([]string{
+		strconv.Itoa(pub.ID), title, name, inverted, "", `="` + pub.ISBN + `"`, `="` + pub.ISBN13 + `"`,
+		strconv.Itoa(entry.Rating), "", pub.Publisher, pub.Format, strconv.Itoa(pub.NumPages),
+		year(pub.EditionPubDate), year(wk.InitialPubDate), dateRead, "", strings.Join(entry.Bookshelves, ", "),
+		strings.Join(positions, ", "), entry.ExclusiveShelf, "", "", "", readCount, "0",
+	})
+}
+
+func (e *goodreadsEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// day returns the date part of a timestamp as stored in the database.
+func day(timestamp string) string {
+	if len(timestamp) > 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
+
+func year(timestamp string) string {
+	if len(timestamp) >= 4 {
+		return timestamp[:4]
+	}
+	return ""
+}
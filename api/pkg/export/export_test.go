@@ -0,0 +1,136 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/export"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+func testEntry() *shelf.Entry {
+	pub := publication.Publication{
+		ID:             3,
+		EditionPubDate: "2009-09-01T00:00:00Z",
+		Format:         "Hardcover",
+		ImageURL:       "https://covers.openlibrary.org/b/isbn/9780439023498-L.jpg",
+		ISBN:           "0439023491",
+		ISBN13:         "9780439023498",
+		Language:       "English",
+		NumPages:       391,
+		Publisher:      "Scholastic",
+		Work: work.Work{
+			ID:               2,
+			Description:      "Sparks are igniting, \"flames\" are spreading.",
+			InitialPubDate:   "2009-09-01T00:00:00Z",
+			OriginalLanguage: "English",
+			Title:            "Catching Fire",
+			Series:           "The Hunger Games",
+			SeriesIndex:      2,
+		},
+	}
+	pub.Work.Author.FirstName = "Suzanne"
+	pub.Work.Author.LastName = "Collins"
+	pub.Work.Author.DateOfBirth = "1962-08-10T00:00:00Z"
+
+	return &shelf.Entry{
+		ID:             1,
+		UserID:         1,
+		Publication:    pub,
+		ExclusiveShelf: shelf.Read,
+		Rating:         4,
+		DateRead:       "2020-01-02T00:00:00Z",
+		Bookshelves:    []string{"dystopia", "young-adult"},
+	}
+}
+
+func TestCSV(t *testing.T) {
+	entry := testEntry()
+
+	var buf bytes.Buffer
+	enc, err := export.NewEncoder(&buf, export.CSV, false)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, nil, enc.EncodePublication(&entry.Publication))
+	helpers.AssertEqual(t, nil, enc.Flush())
+
+	rows, err := importer.ParseCSV(&buf, importer.Mapping{})
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, 1, len(rows))
+
+	got := rows[0].Publication
+	helpers.AssertEqual(t, nil, rows[0].Err)
+	helpers.AssertEqual(t, "Catching Fire", got.Work.Title)
+	helpers.AssertEqual(t, entry.Publication.Work.Description, got.Work.Description)
+	helpers.AssertEqual(t, "Collins", got.Work.Author.LastName)
+	helpers.AssertEqual(t, "1962-08-10", got.Work.Author.DateOfBirth)
+	helpers.AssertEqual(t, "9780439023498", got.ISBN13)
+	helpers.AssertEqual(t, "2009-09-01", got.EditionPubDate)
+	helpers.AssertEqual(t, 391, got.NumPages)
+}
+
+func TestCSVShelves(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := export.NewEncoder(&buf, export.CSV, true)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, nil, enc.EncodeEntry(testEntry()))
+	helpers.AssertEqual(t, nil, enc.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	helpers.AssertEqual(t, 2, len(lines))
+	helpers.AssertEqual(t, true, strings.HasSuffix(lines[0], "exclusiveShelf,rating,dateRead,bookshelves"))
+	helpers.AssertEqual(t, true, strings.HasSuffix(lines[1], `read,4,2020-01-02,"dystopia, young-adult"`))
+}
+
+func TestNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	enc, err := export.NewEncoder(&buf, export.NDJSON, true)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, nil, enc.EncodeEntry(testEntry()))
+	helpers.AssertEqual(t, nil, enc.EncodeEntry(testEntry()))
+	helpers.AssertEqual(t, nil, enc.Flush())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	helpers.AssertEqual(t, 2, len(lines))
+
+	var got shelf.Entry
+	helpers.AssertEqual(t, nil, json.Unmarshal([]byte(lines[1]), &got))
+	helpers.AssertEqual(t, testEntry(), &got)
+}
+
+func TestGoodreads(t *testing.T) {
+	entry := testEntry()
+
+	var buf bytes.Buffer
+	enc, err := export.NewEncoder(&buf, export.Goodreads, true)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, nil, enc.EncodeEntry(entry))
+	helpers.AssertEqual(t, nil, enc.Flush())
+
+	rows, err := importer.ParseGoodreads(&buf, 1)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, 1, len(rows))
+
+	row := rows[0]
+	helpers.AssertEqual(t, nil, row.Err)
+	helpers.AssertEqual(t, "Catching Fire", row.Publication.Work.Title)
+	helpers.AssertEqual(t, "Suzanne", row.Publication.Work.Author.FirstName)
+	helpers.AssertEqual(t, "0439023491", row.Publication.ISBN)
+	helpers.AssertEqual(t, "9780439023498", row.Publication.ISBN13)
+	helpers.AssertEqual(t, "2009-01-01", row.Publication.EditionPubDate)
+	helpers.AssertEqual(t, shelf.Read, row.Shelf.ExclusiveShelf)
+	helpers.AssertEqual(t, 4, row.Shelf.Rating)
+	helpers.AssertEqual(t, "2020-01-02", row.Shelf.DateRead)
+	helpers.AssertEqual(t, entry.Bookshelves, row.Shelf.Bookshelves)
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := export.NewEncoder(&bytes.Buffer{}, "xml", false)
+	helpers.AssertEqual(t, true, errors.Is(err, export.ErrFormat))
+}
@@ -0,0 +1,112 @@
+package genre
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	GetGenres
+	GetNames
+	PostGenre
+)
+
+// Genres represents a list of genre names.
+type Genres []string
+
+// Service wraps the database.
+type Service struct {
+	DB postgres.DB
+}
+
+// WithTx returns a copy of the receiver whose statements are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx)}
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case GetGenres:
+		return "SELECT name FROM genre WHERE work_id = $1 ORDER BY name"
+	case GetNames:
+		return `SELECT DISTINCT genre.name
+                        FROM genre
+                        JOIN work ON genre.work_id=work.id
+                        WHERE work.deleted_at IS NULL
+                        ORDER BY genre.name`
+	case PostGenre:
+		return `INSERT INTO genre (work_id, name)
+                        VALUES ($1, $2)
+                        ON CONFLICT (work_id, name) DO NOTHING`
+	default:
+		return ""
+	}
+}
+
+// GetGenres retrieves the genres of the work matching the given id.
+func (s *Service) GetGenres(workID int) (*status.Status, Genres) {
+	db := s.DB
+	getGenres := s.Query(GetGenres)
+
+	rows, err := db.Query(getGenres, workID)
+	if err != nil {
+		log.Printf("[GetGenres] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	genres := Genres{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			log.Printf("[GetGenres] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		genres = append(genres, name)
+	}
+	return status.New(status.OK, ""), genres
+}
+
+// GetNames retrieves the name of every genre that a work belongs to, in alphabetical order.
+func (s *Service) GetNames() (*status.Status, Genres) {
+	db := s.DB
+	getNames := s.Query(GetNames)
+
+	rows, err := db.Query(getNames)
+	if err != nil {
+		log.Printf("[GetNames] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	genres := Genres{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			log.Printf("[GetNames] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		genres = append(genres, name)
+	}
+	return status.New(status.OK, ""), genres
+}
+
+// PostGenres adds the given genres to the work matching the given id, skipping any it already has.
+func (s *Service) PostGenres(workID int, genres Genres) *status.Status {
+	db := s.DB
+	postGenre := s.Query(PostGenre)
+
+	for _, name := range genres {
+		if _, err := db.Exec(postGenre, workID, name); err != nil {
+			log.Printf("[PostGenres] %s", err)
+			return status.New(status.UnprocessableEntity, err.Error())
+		}
+	}
+	return status.New(status.Created, "")
+}
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/graphql-go/graphql"
+)
+
+// Request is a GraphQL request, as sent in the body of a POST or the query string of a GET.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Service wraps the services that GraphQL queries are resolved with.
+type Service struct {
+	AuthorService      author.Service
+	WorkService        work.Service
+	PublicationService publication.Service
+}
+
+// loaders holds the loaders of a single request, so that values are only cached for as long as it lasts.
+type loaders struct {
+	service      *Service
+	works        *Loader
+	publications *Loader
+}
+
+type contextKey struct{}
+
+// Do executes the given request against Schema.
+func (s *Service) Do(ctx context.Context, req *Request) *graphql.Result {
+	l := &loaders{service: s}
+	l.works = NewLoader(func(authorIDs []int) (map[int]interface{}, error) {
+		st, works := s.WorkService.GetWorksByAuthors(authorIDs)
+		if st.Err() != nil {
+			return nil, st.Err()
+		}
+		byAuthor := make(map[int]interface{}, len(authorIDs))
+		for _, id := range authorIDs {
+			byAuthor[id] = work.Works{}
+		}
+		for _, wk := range works {
+			byAuthor[wk.Author.ID] = append(byAuthor[wk.Author.ID].(work.Works), wk)
+		}
+		return byAuthor, nil
+	})
+	l.publications = NewLoader(func(workIDs []int) (map[int]interface{}, error) {
+		st, pubs := s.PublicationService.FilterPublications(&publication.Filter{WorkIDs: workIDs})
+		if st.Err() != nil {
+			return nil, st.Err()
+		}
+		byWork := make(map[int]interface{}, len(workIDs))
+		for _, id := range workIDs {
+			byWork[id] = publication.Publications{}
+		}
+		for _, pub := range pubs {
+			byWork[pub.Work.ID] = append(byWork[pub.Work.ID].(publication.Publications), pub)
+		}
+		return byWork, nil
+	})
+
+	return graphql.Do(graphql.Params{
+		Schema:         Schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        context.WithValue(ctx, contextKey{}, l),
+	})
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(contextKey{}).(*loaders)
+}
+
+// notFound returns the result of a field whose value could not be retrieved with the given status:
+// null if it does not exist, or the status's error otherwise.
+func notFound(s *status.Status) (interface{}, error) {
+	if s.Code() == status.NotFound {
+		return nil, nil
+	}
+	return nil, s.Err()
+}
@@ -0,0 +1,174 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/test/data"
+	"github.com/graphql-go/graphql"
+)
+
+func TestLoader(t *testing.T) {
+	var batches [][]int
+	fetch := func(keys []int) (map[int]interface{}, error) {
+		batches = append(batches, keys)
+		values := map[int]interface{}{}
+		for _, k := range keys {
+			if k > 0 {
+				values[k] = k * 10
+			}
+		}
+		return values, nil
+	}
+
+	t.Run("Batch", func(t *testing.T) {
+		batches = nil
+		l := graph.NewLoader(fetch)
+		one, two, again, missing := l.Load(1), l.Load(2), l.Load(1), l.Load(-1)
+
+		v, err := two()
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 20, v)
+		v, _ = one()
+		helpers.AssertEqual(t, 10, v)
+		v, _ = again()
+		helpers.AssertEqual(t, 10, v)
+		v, _ = missing()
+		helpers.AssertEqual(t, nil, v)
+		helpers.AssertEqual(t, [][]int{{1, 2, -1}}, batches)
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		batches = nil
+		l := graph.NewLoader(fetch)
+		l.Load(1)()
+		l.Load(1)()
+		l.Load(3)()
+		helpers.AssertEqual(t, [][]int{{1}, {3}}, batches)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		want := errors.New("connection refused")
+		l := graph.NewLoader(func(keys []int) (map[int]interface{}, error) { return nil, want })
+		one, two := l.Load(1), l.Load(2)
+		one()
+		_, err := two()
+		helpers.AssertEqual(t, want, err)
+	})
+}
+
+// TestLoaderQuery checks that a loader batches the fields of every item in a list into one fetch
+// when its thunks are resolved by graphql-go.
+func TestLoaderQuery(t *testing.T) {
+	var batches [][]int
+	l := graph.NewLoader(func(keys []int) (map[int]interface{}, error) {
+		batches = append(batches, keys)
+		values := map[int]interface{}{}
+		for _, k := range keys {
+			values[k] = k * k
+		}
+		return values, nil
+	})
+
+	item := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Item",
+		Fields: graphql.Fields{
+			"square": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return l.Load(p.Source.(int)), nil
+				},
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"items": &graphql.Field{
+					Type: graphql.NewList(item),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return []int{1, 2, 3}, nil
+					},
+				},
+			},
+		}),
+	})
+	helpers.AssertEqual(t, nil, err)
+
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: "{ items { square } }"})
+	helpers.AssertEqual(t, 0, len(result.Errors))
+	helpers.AssertEqual(t, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"square": 1},
+			map[string]interface{}{"square": 4},
+			map[string]interface{}{"square": 9},
+		},
+	}, result.Data)
+	helpers.AssertEqual(t, [][]int{{1, 2, 3}}, batches)
+}
+
+func TestDo(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	publications := helpers.PostPublications(t, ps, data.GetPublications(ps))
+	g := &graph.Service{
+		AuthorService:      *services.AuthorService,
+		WorkService:        *services.WorkService,
+		PublicationService: *ps,
+	}
+
+	t.Run("Nested", func(t *testing.T) {
+		result := g.Do(context.Background(), &graph.Request{
+			Query: `{ authors(first: 2) { lastName works { title publications { isbn13 } } } }`,
+		})
+		helpers.AssertEqual(t, 0, len(result.Errors))
+
+		authors := result.Data.(map[string]interface{})["authors"].([]interface{})
+		helpers.AssertEqual(t, 2, len(authors))
+		works := authors[0].(map[string]interface{})["works"].([]interface{})
+		helpers.AssertEqual(t, 1, len(works))
+		helpers.AssertEqual(t, publications[0].Work.Title, works[0].(map[string]interface{})["title"])
+		pubs := works[0].(map[string]interface{})["publications"].([]interface{})
+		helpers.AssertEqual(t, publications[0].ISBN13, pubs[0].(map[string]interface{})["isbn13"])
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		ids := func(query string) []interface{} {
+			result := g.Do(context.Background(), &graph.Request{Query: query})
+			helpers.AssertEqual(t, 0, len(result.Errors))
+			var ids []interface{}
+			for _, wk := range result.Data.(map[string]interface{})["works"].([]interface{}) {
+				ids = append(ids, wk.(map[string]interface{})["id"])
+			}
+			return ids
+		}
+
+		all := ids(`{ works { id } }`)
+		helpers.AssertEqual(t, all[1:3], ids(`{ works(first: 2, offset: 1) { id } }`))
+		helpers.AssertEqual(t, all[1:], ids(`{ works(offset: 1) { id } }`))
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		result := g.Do(context.Background(), &graph.Request{
+			Query:     `query($publisher: String) { publications(publisher: $publisher) { id work { author { lastName } } } }`,
+			Variables: map[string]interface{}{"publisher": "Scribner"},
+		})
+		helpers.AssertEqual(t, 0, len(result.Errors))
+
+		pubs := result.Data.(map[string]interface{})["publications"].([]interface{})
+		helpers.AssertEqual(t, 1, len(pubs))
+		helpers.AssertEqual(t, publications[3].ID, pubs[0].(map[string]interface{})["id"])
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		result := g.Do(context.Background(), &graph.Request{Query: `{ publication(id: -1) { id } }`})
+		helpers.AssertEqual(t, 0, len(result.Errors))
+		helpers.AssertEqual(t, map[string]interface{}{"publication": nil}, result.Data)
+	})
+
+	cleanup()
+}
@@ -0,0 +1,62 @@
+package graph
+
+import "sync"
+
+// FetchFunc retrieves the values of the given keys in one go. Keys without a value are left out of the map.
+type FetchFunc func(keys []int) (map[int]interface{}, error)
+
+// A Loader batches the loads of many keys into a single fetch, in the manner of DataLoader.
+// Keys given to Load are collected until the value of any of them is needed, at which point
+// every pending key is fetched at once. Fetched values are cached for the life of the loader,
+// which is a single request.
+type Loader struct {
+	fetch FetchFunc
+
+	mu      sync.Mutex
+	pending []int
+	queued  map[int]bool
+	values  map[int]interface{}
+	errs    map[int]error
+}
+
+// NewLoader returns a loader that retrieves values with fetch.
+func NewLoader(fetch FetchFunc) *Loader {
+	return &Loader{
+		fetch:  fetch,
+		queued: map[int]bool{},
+		values: map[int]interface{}{},
+		errs:   map[int]error{},
+	}
+}
+
+// Load queues the given key and returns a thunk that returns its value, fetching it along with
+// every other queued key the first time a thunk is called. The value of a key that fetch did not
+// return is nil.
+func (l *Loader) Load(key int) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else {
+					l.values[k] = values[k]
+				}
+			}
+		}
+		return l.values[key], l.errs[key]
+	}
+}
@@ -0,0 +1,236 @@
+package graph
+
+import (
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/graphql-go/graphql"
+)
+
+// Objects are resolved from the structs of their packages, whose fields are matched by their JSON names.
+// A publication is retrieved with its work, author and place of birth, so those fields need no resolver;
+// the reverse relations, an author's works and a work's publications, are batched by the request's loaders.
+var (
+	locationType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Location",
+		Description: "A geographic city location.",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"city":    &graphql.Field{Type: graphql.String},
+			"country": &graphql.Field{Type: graphql.String},
+			"region":  &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	authorType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Author",
+		Description: "A writer of works.",
+		Fields: graphql.Fields{
+			"id":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"firstName":    &graphql.Field{Type: graphql.String},
+			"lastName":     &graphql.Field{Type: graphql.String},
+			"gender":       &graphql.Field{Type: graphql.String},
+			"dateOfBirth":  &graphql.Field{Type: graphql.String},
+			"placeOfBirth": &graphql.Field{Type: locationType},
+		},
+	})
+
+	workType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Work",
+		Description: "A literary work.",
+		Fields: graphql.Fields{
+			"id":               &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"description":      &graphql.Field{Type: graphql.String},
+			"initialPubDate":   &graphql.Field{Type: graphql.String},
+			"originalLanguage": &graphql.Field{Type: graphql.String},
+			"title":            &graphql.Field{Type: graphql.String},
+			"series":           &graphql.Field{Type: graphql.String},
+			"seriesIndex":      &graphql.Field{Type: graphql.Float},
+			"author":           &graphql.Field{Type: authorType},
+		},
+	})
+
+	publicationType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Publication",
+		Description: "A specific edition of a work.",
+		Fields: graphql.Fields{
+			"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"editionPubDate": &graphql.Field{Type: graphql.String},
+			"format":         &graphql.Field{Type: graphql.String},
+			"imageUrl":       &graphql.Field{Type: graphql.String},
+			"isbn":           &graphql.Field{Type: graphql.String},
+			"isbn13":         &graphql.Field{Type: graphql.String},
+			"language":       &graphql.Field{Type: graphql.String},
+			"numPages":       &graphql.Field{Type: graphql.Int},
+			"publisher":      &graphql.Field{Type: graphql.String},
+			"edition":        &graphql.Field{Type: graphql.String},
+			"work":           &graphql.Field{Type: workType},
+		},
+	})
+)
+
+// pageArgs are the arguments of every list field that can be paginated.
+var pageArgs = graphql.FieldConfigArgument{
+	"first":  &graphql.ArgumentConfig{Type: graphql.Int, Description: "The number of items to return."},
+	"offset": &graphql.ArgumentConfig{Type: graphql.Int, Description: "The number of items to skip."},
+}
+
+// Schema is the GraphQL schema of the catalogue.
+var Schema graphql.Schema
+
+func init() {
+	authorType.AddFieldConfig("works", &graphql.Field{
+		Type: list(workType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).works.Load(p.Source.(author.Author).ID), nil
+		},
+	})
+	workType.AddFieldConfig("publications", &graphql.Field{
+		Type: list(publicationType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return loadersFrom(p.Context).publications.Load(p.Source.(work.Work).ID), nil
+		},
+	})
+
+	filterArgs := graphql.FieldConfigArgument{
+		"ids":       &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+		"author":    &graphql.ArgumentConfig{Type: graphql.Int},
+		"work":      &graphql.ArgumentConfig{Type: graphql.Int},
+		"format":    &graphql.ArgumentConfig{Type: graphql.String},
+		"language":  &graphql.ArgumentConfig{Type: graphql.String},
+		"publisher": &graphql.ArgumentConfig{Type: graphql.String},
+		"series":    &graphql.ArgumentConfig{Type: graphql.String},
+		"genre":     &graphql.ArgumentConfig{Type: graphql.String},
+		"search":    &graphql.ArgumentConfig{Type: graphql.String, Description: "Matches titles and author names."},
+		"newest":    &graphql.ArgumentConfig{Type: graphql.Boolean, Description: "Orders publications newest first."},
+	}
+	for name, arg := range pageArgs {
+		filterArgs[name] = arg
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"publication": &graphql.Field{
+				Type: publicationType,
+				Args: idArgs(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s, pub := loadersFrom(p.Context).service.PublicationService.GetPublication(p.Args["id"].(int))
+					if s.Err() != nil {
+						return notFound(s)
+					}
+					return *pub, nil
+				},
+			},
+			"publications": &graphql.Field{
+				Type:    list(publicationType),
+				Args:    filterArgs,
+				Resolve: resolvePublications,
+			},
+			"work": &graphql.Field{
+				Type: workType,
+				Args: idArgs(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s, wk := loadersFrom(p.Context).service.WorkService.GetWork(p.Args["id"].(int))
+					if s.Err() != nil {
+						return notFound(s)
+					}
+					return *wk, nil
+				},
+			},
+			"works": &graphql.Field{
+				Type: list(workType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, offset := page(p.Args)
+					s, works := loadersFrom(p.Context).service.WorkService.GetWorksPage(limit, offset)
+					if s.Err() != nil {
+						return nil, s.Err()
+					}
+					return works, nil
+				},
+			},
+			"author": &graphql.Field{
+				Type: authorType,
+				Args: idArgs(),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					s, au := loadersFrom(p.Context).service.AuthorService.GetAuthor(p.Args["id"].(int))
+					if s.Err() != nil {
+						return notFound(s)
+					}
+					return *au, nil
+				},
+			},
+			"authors": &graphql.Field{
+				Type: list(authorType),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					limit, offset := page(p.Args)
+					s, authors := loadersFrom(p.Context).service.AuthorService.GetAuthorsPage(limit, offset)
+					if s.Err() != nil {
+						return nil, s.Err()
+					}
+					return authors, nil
+				},
+			},
+		},
+	})
+
+	var err error
+	if Schema, err = graphql.NewSchema(graphql.SchemaConfig{Query: query}); err != nil {
+		panic(err)
+	}
+}
+
+func resolvePublications(p graphql.ResolveParams) (interface{}, error) {
+	filter := &publication.Filter{}
+	if ids, ok := p.Args["ids"].([]interface{}); ok {
+		for _, id := range ids {
+			filter.IDs = append(filter.IDs, id.(int))
+		}
+	}
+	for arg, field := range map[string]*int{"author": &filter.AuthorID, "work": &filter.WorkID, "first": &filter.Limit, "offset": &filter.Offset} {
+		if v, ok := p.Args[arg].(int); ok {
+			*field = v
+		}
+	}
+	for arg, field := range map[string]*string{
+		"format": &filter.Format, "language": &filter.Language, "publisher": &filter.Publisher,
+		"series": &filter.Series, "genre": &filter.Genre, "search": &filter.Search,
+	} {
+		if v, ok := p.Args[arg].(string); ok {
+			*field = v
+		}
+	}
+	filter.Newest, _ = p.Args["newest"].(bool)
+
+	s, pubs := loadersFrom(p.Context).service.PublicationService.FilterPublications(filter)
+	if s.Err() != nil {
+		return nil, s.Err()
+	}
+	return pubs, nil
+}
+
+func list(t graphql.Type) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+func idArgs() graphql.FieldConfigArgument {
+	return graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	}
+}
+
+// page returns the limit and offset of the page selected by the first and offset arguments. The limit is negative
+// if first is not given, for every item after the offset.
+func page(args map[string]interface{}) (int, int) {
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		offset = 0
+	}
+	limit, ok := args["first"].(int)
+	if !ok || limit < 0 {
+		limit = -1
+	}
+	return limit, offset
+}
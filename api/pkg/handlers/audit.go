@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/audit"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// maxAudited is how many bytes of each request and response body are kept to find the ids of the entries
+// they concern. Bodies that are longer, such as imported files, are not searched for ids.
+const maxAudited = 64 << 10
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// Audit handles requests made to /api/admin/audit
+func Audit(au *audit.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseAuditFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+		s, entries := au.GetEntries(f)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(entries)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
+// Audited records every request that may change the catalogue, i.e. every request other than a GET, HEAD or
+// OPTIONS, in the audit log once it has been handled, along with who made it and how it turned out. Retries that
+// are answered with the response to the first request, by Idempotent, are not recorded again.
+func Audited(au *audit.Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			req := &cappedBuffer{}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.TeeReader(r.Body, req), r.Body}
+			aw := &auditWriter{ResponseWriter: w, code: status.OK}
+			next.ServeHTTP(aw, r)
+			if w.Header().Get(replayedHeader) != "" {
+				return
+			}
+
+			route := r.URL.RequestURI()
+			if len(route) > 2000 {
+				route = route[:2000]
+			}
+			au.PostEntry(&audit.Entry{
+				Actor:     actor(r),
+				IP:        remoteIP(r),
+				Method:    r.Method,
+				Route:     route,
+				Entity:    auditedEntity(r.URL.Path),
+				EntityIDs: auditedIDs(r, req, &aw.body),
+				Status:    aw.code,
+			})
+		})
+	}
+}
+
+// parseAuditFilter reads an audit.Filter from the query parameters actor, entity, id, from and to, which are
+// RFC 3339 times, and limit and offset.
+func parseAuditFilter(query url.Values) (*audit.Filter, error) {
+	f := &audit.Filter{Actor: query.Get("actor"), Entity: query.Get("entity")}
+
+	for param, field := range map[string]*int{"id": &f.EntityID, "limit": &f.Limit, "offset": &f.Offset} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%s: %q is not a positive integer", param, v)
+			}
+			*field = n
+		}
+	}
+	for param, field := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := query.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %q is not an RFC 3339 time", param, v)
+			}
+			*field = t
+		}
+	}
+	return f, nil
+}
+
+// remoteIP returns the address of the client that made the request, without its port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditedEntity returns the kind of entry that a request to the given path concerns, e.g. publication for
+// /api/v2/publication/1 or import for /api/import/csv.
+func auditedEntity(path string) string {
+	return strings.Split(strings.TrimPrefix(unversioned(path), "/"), "/")[0]
+}
+
+// unversioned returns the given path under the API without its prefix, e.g. /publication/1 for both
+// /api/v2/publication/1 and /api/publication/1.
+func unversioned(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if len(segments) > 1 && versionSegment.MatchString(segments[0]) {
+		segments = segments[1:]
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// auditedIDs returns the ids of the entries that a request concerns: the id in its path, the ids in the body of
+// a bulk request, the id of the entry it created, if any, and the ids of the entries of a batch it created or
+// updated.
+func auditedIDs(r *http.Request, req *cappedBuffer, res *cappedBuffer) []int {
+	ids := []int{}
+	if id, err := strconv.Atoi(mux.Vars(r)["id"]); err == nil {
+		ids = append(ids, id)
+	}
+
+	var body struct {
+		ID  int   `json:"id"`
+		IDs []int `json:"ids"`
+	}
+	if !req.truncated && json.Unmarshal(req.Bytes(), &body) == nil {
+		ids = append(ids, body.IDs...)
+	}
+	body.ID = 0
+	if r.Method == http.MethodPost && !res.truncated && json.Unmarshal(res.Bytes(), &body) == nil && body.ID != 0 {
+		// Restoring an entry returns it, and its id is already in the path.
+		if len(ids) == 0 || ids[0] != body.ID {
+			ids = append(ids, body.ID)
+		}
+	}
+
+	var results []struct {
+		Entry struct {
+			ID int `json:"id"`
+		} `json:"entry"`
+	}
+	if !res.truncated && json.Unmarshal(res.Bytes(), &results) == nil {
+		for _, result := range results {
+			if result.Entry.ID != 0 {
+				ids = append(ids, result.Entry.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// auditWriter keeps the status code of a response and the beginning of its body.
+type auditWriter struct {
+	http.ResponseWriter
+	code int
+	body cappedBuffer
+}
+
+func (aw *auditWriter) WriteHeader(code int) {
+	aw.code = code
+	aw.ResponseWriter.WriteHeader(code)
+}
+
+func (aw *auditWriter) Write(b []byte) (int, error) {
+	aw.body.Write(b)
+	return aw.ResponseWriter.Write(b)
+}
+
+// cappedBuffer is a buffer that keeps at most maxAudited bytes and discards the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxAudited - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:room])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/user"
+	"github.com/gorilla/mux"
+)
+
+// identityKey is the key of the identity of a request in its context.
+type identityKey struct{}
+
+// Authenticated authenticates requests with the credentials in their Authorization header: the username and
+// password of a user, with the Basic scheme, or the admin token, with the Bearer scheme. Requests with invalid
+// credentials are rejected, and so are requests without any that may change the catalogue, i.e. every request
+// other than a GET, HEAD or OPTIONS, so that every change is recorded as made by someone.
+func Authenticated(us *user.Service, adminToken string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			given := r.Header.Get("Authorization")
+			if given == "" {
+				switch r.Method {
+				case http.MethodGet, http.MethodHead, http.MethodOptions:
+					next.ServeHTTP(w, r)
+				default:
+					unauthorized(w, "Credentials are required to make changes")
+				}
+				return
+			}
+
+			s, id := us.Identify(given, adminToken)
+			if s.Code() == status.Unauthorized {
+				unauthorized(w, s.Message())
+				return
+			} else if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+		})
+	}
+}
+
+// AdminOnly only lets the requests authenticated with the admin token through to h. Every request is forbidden
+// when no admin token is configured, since the admin routes are then disabled.
+func AdminOnly(token string, h http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch id := authenticated(r); {
+		case token == "":
+			http.Error(w, "This route is only available when an admin token is configured", status.Forbidden)
+		case id == nil:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "An admin token is required", status.Unauthorized)
+		case !id.Admin:
+			http.Error(w, "This route is only available to admins", status.Forbidden)
+		default:
+			h(w, r)
+		}
+	})
+}
+
+// shelfOwner returns the id of the user whose shelves the request concerns: the user it was authenticated as, or,
+// for the admin, the user given by its user query parameter. Users may only give their own id. It responds with an
+// error and returns 0 if the request was made without credentials, or names no user or another user.
+func shelfOwner(w http.ResponseWriter, r *http.Request) int {
+	id := authenticated(r)
+	if id == nil {
+		unauthorized(w, "Credentials are required to access the shelves of a user")
+		return 0
+	}
+
+	param := r.URL.Query().Get("user")
+	if param == "" {
+		if id.Admin {
+			http.Error(w, "user must be the id of a user", status.BadRequest)
+			return 0
+		}
+		return id.User.ID
+	}
+	userID, err := strconv.Atoi(param)
+	if err != nil || userID <= 0 {
+		http.Error(w, "user must be the id of a user", status.BadRequest)
+		return 0
+	}
+	if !ownsShelves(w, r, userID) {
+		return 0
+	}
+	return userID
+}
+
+// ownsShelves reports whether the request may access the shelves of the user matching the given id: only that user
+// and the admin may. It responds with an error if the request may not.
+func ownsShelves(w http.ResponseWriter, r *http.Request, userID int) bool {
+	switch id := authenticated(r); {
+	case id == nil:
+		unauthorized(w, "Credentials are required to access the shelves of a user")
+		return false
+	case !id.Admin && userID != id.User.ID:
+		http.Error(w, "The shelves of other users cannot be accessed", status.Forbidden)
+		return false
+	default:
+		return true
+	}
+}
+
+// authenticated returns who the request has been authenticated as, or nil if it was made without credentials.
+func authenticated(r *http.Request) *user.Identity {
+	id, _ := r.Context().Value(identityKey{}).(*user.Identity)
+	return id
+}
+
+// actor returns who is making the request, as authenticated by Authenticated, so that the changes it makes are
+// recorded in the history as theirs. It is empty for requests made without credentials, which cannot make any.
+func actor(r *http.Request) string {
+	if id := authenticated(r); id != nil {
+		return id.Actor()
+	}
+	return ""
+}
+
+// unauthorized responds with a 401 Unauthorized that asks for either scheme of credentials.
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Add("WWW-Authenticate", `Basic realm="books"`)
+	w.Header().Add("WWW-Authenticate", `Bearer realm="admin"`)
+	http.Error(w, msg, status.Unauthorized)
+}
@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/jsonld"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/gorilla/mux"
 )
 
 // Author handles requests made to /api/author/{id:[0-9]+}
-func Author(a *author.Service) http.HandlerFunc {
+func Author(a *author.Service, rd *redirect.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := a.WithActor(actor(r))
 		vars := mux.Vars(r)
 		id, err := strconv.Atoi(vars["id"])
 		if err != nil {
@@ -22,29 +27,55 @@ func Author(a *author.Service) http.HandlerFunc {
 
 		switch r.Method {
 		case http.MethodGet:
-			s, author := a.GetAuthor(id)
+			w.Header().Add("Vary", "Accept")
+			s, au := a.GetAuthor(id)
+			if s.Code() == status.NotFound {
+				if id = followRedirect(w, r, rd, author.Entity, id, s); id == 0 {
+					return
+				}
+				s, au = a.GetAuthor(id)
+			}
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(*author)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := a.LastModified([]int{id})
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
-		case http.MethodPatch:
-			var author author.Author
-			author.ID = id
-			if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			if wantsJSONLD(r) {
+				writeJSONLD(w, r, jsonld.FromAuthor(baseURL(r), au), modified)
 				return
 			}
+			writeJSON(w, r, *au, modified)
+		case http.MethodPatch:
+			var p patch.Patch
+			if revert := r.URL.Query().Get("revert"); revert != "" {
+				// Reverting patches the author with its fields as they were after the given revision.
+				revisionID, err := strconv.Atoi(revert)
+				if err != nil {
+					http.Error(w, err.Error(), status.UnprocessableEntity)
+					return
+				}
+				s, revision := a.GetRevision(id, revisionID)
+				if s.Err() != nil {
+					http.Error(w, s.Message(), s.Code())
+					return
+				}
+				if p, err = patch.From(revision, author.Fields); err != nil {
+					http.Error(w, err.Error(), status.InternalServerError)
+					return
+				}
+			} else {
+				var s *status.Status
+				if s, p = decodePatch(r, author.Fields); s.Err() != nil {
+					http.Error(w, s.Message(), s.Code())
+					return
+				}
+			}
 
-			s, updated := a.PatchAuthor(&author)
+			s, updated := patchAuthor(a, r, id, p)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -59,7 +90,7 @@ func Author(a *author.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			s := a.DeleteAuthor(id)
+			s := deleteAuthor(a, r, id)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -69,9 +100,38 @@ func Author(a *author.Service) http.HandlerFunc {
 	})
 }
 
+// RestoreAuthor handles requests made to /api/author/{id:[0-9]+}/restore
+func RestoreAuthor(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := a.WithActor(actor(r))
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, restored := a.RestoreAuthor(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(*restored)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
 // Authors handles requests made to /api/author
 func Authors(a *author.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := a.WithActor(actor(r))
 		switch r.Method {
 		case http.MethodGet:
 			s, authors := a.GetAuthors()
@@ -79,15 +139,12 @@ func Authors(a *author.Service) http.HandlerFunc {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(authors)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := a.LastModified(nil)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
+			writeJSON(w, r, authors, modified)
 		case http.MethodPost:
 			var author author.Author
 			if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
@@ -110,10 +167,6 @@ func Authors(a *author.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			type identifiers struct {
-				IDs []int `json:"ids"`
-			}
-
 			var toDelete identifiers
 			if err := json.NewDecoder(r.Body).Decode(&toDelete); err != nil {
 				http.Error(w, err.Error(), status.UnprocessableEntity)
@@ -140,3 +193,148 @@ func Authors(a *author.Service) http.HandlerFunc {
 		}
 	})
 }
+
+// AuthorBatch handles requests made to /api/author/batch
+func AuthorBatch(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := a.WithActor(actor(r))
+		s, b := decodeBatch(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		handle := func(a *author.Service) *status.Status {
+			return b.handle(func(item json.RawMessage) (*status.Status, interface{}) {
+				if r.Method == http.MethodPost {
+					var au author.Author
+					if err := json.Unmarshal(item, &au); err != nil {
+						return status.New(status.UnprocessableEntity, err.Error()), nil
+					}
+					s, posted := a.PostAuthor(&au)
+					return s, posted
+				}
+
+				s, id, p := patchItem(item, author.Fields)
+				if s.Err() != nil {
+					return s, nil
+				}
+				s, updated := patchAuthor(a, r, id, p)
+				return s, updated
+			})
+		}
+		if b.atomic {
+			s = a.Transaction(handle)
+		} else {
+			s = handle(a)
+		}
+		b.write(w, s)
+	})
+}
+
+// AuthorAliases handles requests made to /api/author/{id:[0-9]+}/aliases
+func AuthorAliases(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			s, aliases := a.GetAliases(id)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
+				return
+			}
+			writeJSON(w, r, aliases, time.Time{})
+		case http.MethodPost:
+			a := a.WithActor(actor(r))
+			var alias author.Alias
+			if err := json.NewDecoder(r.Body).Decode(&alias); err != nil {
+				http.Error(w, err.Error(), status.UnprocessableEntity)
+				return
+			}
+
+			s, posted := a.PostAlias(id, &alias)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
+				return
+			}
+
+			bytes, err := json.Marshal(*posted)
+			if err != nil {
+				http.Error(w, err.Error(), status.InternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(s.Code())
+			w.Write(bytes)
+		}
+	})
+}
+
+// AuthorAlias handles requests made to /api/author/{id:[0-9]+}/aliases/{aliasId:[0-9]+}
+func AuthorAlias(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+		aliasID, err := strconv.Atoi(vars["aliasId"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		a := a.WithActor(actor(r))
+		s := a.DeleteAlias(id, aliasID)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		w.WriteHeader(s.Code())
+	})
+}
+
+// patchAuthor patches the author matching the given id with p, provided that it still matches the request's
+// If-Match header. The author is locked while it is read, checked and updated, so that no one else can change it
+// in between.
+func patchAuthor(a *author.Service, r *http.Request, id int, p patch.Patch) (*status.Status, *author.Author) {
+	var updated *author.Author
+	s := a.Locked(id, func(a *author.Service) *status.Status {
+		s, current := a.GetAuthor(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		if s.Err() != nil || len(p) == 0 {
+			updated = current
+			return s
+		}
+		if err := p.Apply(current, author.Fields); err != nil {
+			return status.New(status.UnprocessableEntity, err.Error())
+		}
+		s, updated = a.PatchAuthor(current, p.Names()...)
+		return s
+	})
+	return s, updated
+}
+
+// deleteAuthor removes the author matching the given id, provided that it still matches the request's If-Match header.
+func deleteAuthor(a *author.Service, r *http.Request, id int) *status.Status {
+	if r.Header.Get("If-Match") == "" {
+		return a.DeleteAuthor(id)
+	}
+
+	return a.Locked(id, func(a *author.Service) *status.Status {
+		s, current := a.GetAuthor(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		return a.DeleteAuthor(id)
+	})
+}
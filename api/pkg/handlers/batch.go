@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/andrewzulaybar/books/api/pkg/patch"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// maxBatch is the largest number of items that a batch may hold.
+const maxBatch = 100
+
+// A BatchResult is the outcome of an item of a batch: the status it was handled with, as a status.Status, and the
+// entry it created or updated, if it succeeded.
+type BatchResult struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message,omitempty"`
+	Entry   interface{} `json:"entry,omitempty"`
+}
+
+// A batch holds the items to create or update in a single request, given as a JSON array in its body. In atomic
+// mode, either every item succeeds or none does; otherwise, each item succeeds or fails on its own.
+type batch struct {
+	items   []json.RawMessage
+	atomic  bool
+	results []BatchResult
+}
+
+// decodeBatch reads a batch from the body of the request, and whether it is atomic from its atomic query parameter.
+func decodeBatch(r *http.Request) (*status.Status, *batch) {
+	if r.Header.Get("If-Match") != "" {
+		return status.New(status.BadRequest, "If-Match is only supported on requests for a single entry"), nil
+	}
+
+	b := &batch{}
+	if atomic := r.URL.Query().Get("atomic"); atomic != "" {
+		var err error
+		if b.atomic, err = strconv.ParseBool(atomic); err != nil {
+			return status.Newf(status.BadRequest, "atomic: %q is not a boolean", atomic), nil
+		}
+	}
+	if err := json.NewDecoder(r.Body).Decode(&b.items); err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), nil
+	}
+	if len(b.items) == 0 || len(b.items) > maxBatch {
+		return status.Newf(status.UnprocessableEntity, "A batch must hold between 1 and %d items", maxBatch), nil
+	}
+	return status.New(status.OK, ""), b
+}
+
+// handle handles each item of the receiver in turn with fn, and records its result. In atomic mode, it stops at the
+// first item that fails and returns its status, so that the transaction that it is called in is rolled back.
+// Otherwise, it returns a 207 Multi-Status if any item failed, so that clients can tell without reading every result.
+func (b *batch) handle(fn func(item json.RawMessage) (*status.Status, interface{})) *status.Status {
+	b.results = make([]BatchResult, len(b.items))
+	for i, item := range b.items {
+		s, entry := fn(item)
+		if s.Err() != nil {
+			entry = nil
+		}
+		b.results[i] = BatchResult{Code: s.Code(), Message: s.Message(), Entry: entry}
+
+		if b.atomic && s.Err() != nil {
+			for j := range b.results {
+				if j != i {
+					msg := fmt.Sprintf("Not applied, since item %d of the batch failed", i)
+					b.results[j] = BatchResult{Code: status.FailedDependency, Message: msg}
+				}
+			}
+			return s
+		}
+	}
+	for _, result := range b.results {
+		if result.Code/100 != 2 {
+			return status.New(status.MultiStatus, "")
+		}
+	}
+	return status.New(status.OK, "")
+}
+
+// write writes the results of the receiver, with the status of the item that failed an atomic batch, if any.
+func (b *batch) write(w http.ResponseWriter, s *status.Status) {
+	bytes, err := json.Marshal(b.results)
+	if err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(s.Code())
+	w.Write(bytes)
+}
+
+// patchItem reads the id of the entry to update from an item of a batch, along with the merge patch to update it
+// with, which is checked as described by decodePatch.
+func patchItem(item json.RawMessage, fields patch.Fields) (*status.Status, int, patch.Patch) {
+	p, err := patch.Decode(bytes.NewReader(item))
+	if err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), 0, nil
+	}
+
+	number, _ := p["id"].(json.Number)
+	id, err := strconv.Atoi(number.String())
+	if err != nil || id <= 0 {
+		return status.New(status.UnprocessableEntity, "Every item of the batch must have the id of the entry to update"), 0, nil
+	}
+	delete(p, "id")
+
+	if err := p.Check(fields); err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), 0, nil
+	}
+	return status.New(status.OK, ""), id, p
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// AuthorBibliography handles requests made to /api/author/{id:[0-9]+}/bibliography
+func AuthorBibliography(b *bibliography.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, bib := b.GetBibliography(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		// Ratings are not timestamped, so responses only carry an ETag.
+		writeJSON(w, r, *bib, time.Time{})
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/andrewzulaybar/books/api/pkg/cite"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// Cite handles requests made to /api/publication/{id:[0-9]+}/cite
+func Cite(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, pub := p.GetPublication(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeCitations(w, r.URL.Query().Get("format"), publication.Publications{*pub})
+	})
+}
+
+// CiteBatch handles requests made to /api/publication/cite
+func CiteBatch(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter, err := parseFilter(query)
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+		// The format parameter selects the citation format here, not the publication format.
+		filter.Format = ""
+
+		s, pubs := p.FilterPublications(filter)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeCitations(w, query.Get("format"), pubs)
+	})
+}
+
+func writeCitations(w http.ResponseWriter, format string, pubs publication.Publications) {
+	if format == "" {
+		format = cite.BibTeX
+	}
+
+	var buf bytes.Buffer
+	if err := cite.Write(&buf, format, pubs); err != nil {
+		http.Error(w, err.Error(), status.BadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", cite.ContentType(format))
+	w.WriteHeader(status.OK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// etag returns a strong entity tag for the given representation, derived from its bytes.
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// writeConditional writes body, of the given media type, along with its ETag and, unless modified is zero, when it
+// was last modified. A 304 Not Modified is written instead when the request's If-None-Match header, or its
+// If-Modified-Since header in the absence of the former, shows that the client already has this representation.
+func writeConditional(w http.ResponseWriter, r *http.Request, contentType string, body []byte, modified time.Time) {
+	tag := etag(body)
+	w.Header().Set("ETag", tag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, tag, modified) {
+		w.WriteHeader(status.NotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status.OK)
+	w.Write(body)
+}
+
+// writeJSON writes v as a JSON document, or a 304 Not Modified, as writeConditional does.
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}, modified time.Time) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	writeConditional(w, r, "application/json", bytes, modified)
+}
+
+// checkIfMatch checks the request's If-Match header, if any, against the JSON representation of current: the entry
+// as it is before the request changes it, retrieved with status s. An entry that does not exist matches no tags.
+func checkIfMatch(r *http.Request, s *status.Status, current interface{}) *status.Status {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return status.New(status.OK, "")
+	}
+	if s.Code() == status.NotFound {
+		return status.New(status.PreconditionFailed, s.Message())
+	}
+	if s.Err() != nil {
+		return s
+	}
+
+	bytes, err := json.Marshal(current)
+	if err != nil {
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if !matches(header, etag(bytes), true) {
+		return status.New(status.PreconditionFailed, "The entry has been modified since it was retrieved")
+	}
+	return status.New(status.OK, "")
+}
+
+// notModified reports whether the client already has the representation with the given tag, last modified at modified.
+func notModified(r *http.Request, tag string, modified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matches(header, tag, false)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modified.IsZero() {
+		return false
+	}
+	// HTTP dates have a precision of one second.
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// matches reports whether the list of entity tags in an If-Match or If-None-Match header includes tag.
+// Weak tags only match when the comparison is not strong.
+func matches(header string, tag string, strong bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+		if strings.HasPrefix(t, "W/") {
+			if strong {
+				continue
+			}
+			t = strings.TrimPrefix(t, "W/")
+		}
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
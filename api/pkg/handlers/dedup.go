@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// AuthorDuplicates handles requests made to /api/author/duplicates
+func AuthorDuplicates(d *dedup.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, threshold := parseThreshold(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		s, candidates := d.FindAuthorDuplicates(threshold)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeJSON(w, r, candidates, time.Time{})
+	})
+}
+
+// MergeAuthor handles requests made to /api/author/{id:[0-9]+}/merge
+func MergeAuthor(d *dedup.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := d.WithActor(actor(r))
+		s, id, losers := decodeMerge(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		s, merged := d.MergeAuthors(id, losers)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(*merged)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
+// MergeWork handles requests made to /api/work/{id:[0-9]+}/merge
+func MergeWork(d *dedup.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d := d.WithActor(actor(r))
+		s, id, losers := decodeMerge(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		s, merged := d.MergeWorks(id, losers)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(*merged)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
+// WorkDuplicates handles requests made to /api/work/duplicates
+func WorkDuplicates(d *dedup.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, threshold := parseThreshold(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		s, candidates := d.FindWorkDuplicates(threshold)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeJSON(w, r, candidates, time.Time{})
+	})
+}
+
+// decodeMerge reads the id of the entry to merge into from the path of the request, and the ids of the entries to
+// merge into it from its body.
+func decodeMerge(r *http.Request) (*status.Status, int, []int) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), 0, nil
+	}
+
+	var losers identifiers
+	if err := json.NewDecoder(r.Body).Decode(&losers); err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), 0, nil
+	}
+	return status.New(status.OK, ""), id, losers.IDs
+}
+
+// parseThreshold reads the similarity above which entries are proposed as duplicates from the threshold query
+// parameter of the request, or returns dedup.DefaultThreshold if it is not given.
+func parseThreshold(r *http.Request) (*status.Status, float64) {
+	param := r.URL.Query().Get("threshold")
+	if param == "" {
+		return status.New(status.OK, ""), dedup.DefaultThreshold
+	}
+	threshold, err := strconv.ParseFloat(param, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		return status.Newf(status.BadRequest, "threshold: %q is not a number between 0 and 1", param), 0
+	}
+	return status.New(status.OK, ""), threshold
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// Enrich handles requests made to /api/publication/enrich
+func Enrich(m *metadata.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isbn := r.URL.Query().Get("isbn")
+		if isbn == "" {
+			http.Error(w, "Missing isbn query parameter", status.BadRequest)
+			return
+		}
+
+		var pub publication.Publication
+		if err := json.NewDecoder(r.Body).Decode(&pub); err != nil && err != io.EOF {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+
+		s, preview := m.Enrich(isbn, &pub)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(preview)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
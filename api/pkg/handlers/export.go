@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/export"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// ExportPublications handles requests made to /api/export/publications
+func ExportPublications(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter, err := parseFilter(query)
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+
+		ew, err := newExportWriter(w, query.Get("as"), "publications", false)
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+		ew.finish(p.StreamPublications(filter, ew.enc.EncodePublication))
+	})
+}
+
+// ExportShelf handles requests made to /api/export/shelf
+func ExportShelf(ss *shelf.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		userID := shelfOwner(w, r)
+		if userID == 0 {
+			return
+		}
+
+		ew, err := newExportWriter(w, query.Get("as"), fmt.Sprintf("shelf-%d", userID), true)
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+		ew.finish(ss.StreamShelf(userID, ew.enc.EncodeEntry))
+	})
+}
+
+// exportWriter streams an export to a response, keeping track of whether any of it has been sent.
+// Encoders buffer their output, so an error before the buffer is first written out can still be
+// reported with an error status; after that, it can only be logged.
+type exportWriter struct {
+	w      http.ResponseWriter
+	enc    export.Encoder
+	format string
+	sent   bool
+}
+
+// newExportWriter sets the headers of an export in the given format, CSV by default.
+func newExportWriter(w http.ResponseWriter, format string, name string, shelves bool) (*exportWriter, error) {
+	if format == "" {
+		format = export.CSV
+	}
+	ew := &exportWriter{w: w, format: format}
+	enc, err := export.NewEncoder(ew, format, shelves)
+	if err != nil {
+		return nil, err
+	}
+	ew.enc = enc
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+export.Extension(format)))
+	return ew, nil
+}
+
+func (ew *exportWriter) Write(b []byte) (int, error) {
+	ew.sent = true
+	return ew.w.Write(b)
+}
+
+// finish flushes the export, or reports the given status if streaming failed. Once part of the export has been
+// sent, the status is logged and the response aborted, so that the client sees it cut short rather than complete.
+func (ew *exportWriter) finish(s *status.Status) {
+	if s.Err() != nil {
+		if ew.sent {
+			log.Printf("[Export] %s: %d %s", ew.format, s.Code(), s.Message())
+			panic(http.ErrAbortHandler)
+		}
+		ew.w.Header().Del("Content-Disposition")
+		http.Error(ew.w, s.Message(), s.Code())
+		return
+	}
+	if err := ew.enc.Flush(); err != nil {
+		log.Printf("[Export] %s: %s", ew.format, err)
+	}
+}
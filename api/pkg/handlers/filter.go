@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// parseFilter reads a publication.Filter from the query parameters ids (comma-separated),
+// author, work, format, language and publisher.
+func parseFilter(query url.Values) (*publication.Filter, error) {
+	f := &publication.Filter{
+		Format:    query.Get("format"),
+		Language:  query.Get("language"),
+		Publisher: query.Get("publisher"),
+	}
+
+	if ids := query.Get("ids"); ids != "" {
+		for _, id := range strings.Split(ids, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(id))
+			if err != nil {
+				return nil, fmt.Errorf("ids: %q is not an id", id)
+			}
+			f.IDs = append(f.IDs, n)
+		}
+	}
+	for param, field := range map[string]*int{"author": &f.AuthorID, "work": &f.WorkID} {
+		if v := query.Get(param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %q is not an id", param, v)
+			}
+			*field = n
+		}
+	}
+	return f, nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// GraphQL handles requests made to /graphql
+func GraphQL(g *graph.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graph.Request
+		switch r.Method {
+		case http.MethodGet:
+			query := r.URL.Query()
+			req.Query = query.Get("query")
+			req.OperationName = query.Get("operationName")
+			if v := query.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					http.Error(w, "variables: "+err.Error(), status.BadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), status.BadRequest)
+				return
+			}
+		}
+		if req.Query == "" {
+			http.Error(w, "query: must not be empty", status.BadRequest)
+			return
+		}
+
+		result := g.Do(r.Context(), &req)
+		bytes, err := json.Marshal(result)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status.OK)
+		w.Write(bytes)
+	})
+}
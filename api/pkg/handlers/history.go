@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/history"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/gorilla/mux"
+)
+
+// AuthorHistory handles requests made to /api/author/{id:[0-9]+}/history
+func AuthorHistory(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, revisions := a.GetHistory(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeJSON(w, r, revisions, lastRevised(revisions))
+	})
+}
+
+// WorkHistory handles requests made to /api/work/{id:[0-9]+}/history
+func WorkHistory(ws *work.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, revisions := ws.GetHistory(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeJSON(w, r, revisions, lastRevised(revisions))
+	})
+}
+
+// lastRevised returns when the latest of the given revisions was made, or the zero time if there are none.
+func lastRevised(revisions history.Revisions) time.Time {
+	if len(revisions) == 0 {
+		return time.Time{}
+	}
+	return revisions[len(revisions)-1].Timestamp
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// maxIdempotencyKey is the length of the longest idempotency key that is accepted.
+const maxIdempotencyKey = 255
+
+// replayedHeader is the header of the responses replayed to retries.
+const replayedHeader = "Idempotent-Replayed"
+
+// maxIdempotentBody is how many bytes the body of a request with an idempotency key may have, since it is read
+// whole to fingerprint the request.
+const maxIdempotentBody = 10 << 20
+
+// Idempotent makes POST requests with an Idempotency-Key header safe to retry. The first response to each key of
+// each authenticated actor is stored, and replayed to the retries made within idempotency.TTL instead of handling
+// them again, whichever version of the API they are made to. Reusing a key for a different request, i.e. one with
+// another route or body, is rejected, and so is a retry made while the first request is still being handled.
+func Idempotent(is *idempotency.Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			actor := actor(r)
+			if r.Method != http.MethodPost || key == "" || actor == "" {
+				// Requests without credentials share no key space, and cannot make changes anyway.
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKey {
+				http.Error(w, "The Idempotency-Key header must be at most 255 characters long", status.BadRequest)
+				return
+			}
+
+			body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBody))
+			if err != nil && len(body) >= maxIdempotentBody {
+				http.Error(w, "The body of a request with an Idempotency-Key must be at most 10 MiB", status.PayloadTooLarge)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), status.BadRequest)
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+			sum := sha256.New()
+			route := unversioned(r.URL.Path)
+			if r.URL.RawQuery != "" {
+				route += "?" + r.URL.RawQuery
+			}
+			io.WriteString(sum, r.Method+" "+route+"\n")
+			sum.Write(body)
+			fingerprint := hex.EncodeToString(sum.Sum(nil))
+
+			if s := is.PostKey(actor, key, fingerprint); s.Code() == status.Conflict {
+				replay(w, is, actor, key, fingerprint)
+				return
+			} else if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
+				return
+			}
+
+			defer func() {
+				if err := recover(); err != nil {
+					// The request left no response to replay, so the key is released for it to be retried.
+					is.DeleteKey(actor, key)
+					panic(err)
+				}
+			}()
+			iw := &idempotentWriter{ResponseWriter: w, code: status.OK}
+			next.ServeHTTP(iw, r)
+			if iw.code >= status.InternalServerError {
+				// The request may well succeed when retried, so the key is released rather than kept for the failure.
+				is.DeleteKey(actor, key)
+				return
+			}
+			is.PatchResponse(actor, key, &idempotency.Response{
+				Status:      iw.code,
+				ContentType: w.Header().Get("Content-Type"),
+				ETag:        w.Header().Get("ETag"),
+				Location:    w.Header().Get("Location"),
+				Body:        iw.body.Bytes(),
+			})
+		})
+	}
+}
+
+// replay writes the stored response to the request made with the key of the given actor, provided that the
+// request had the same fingerprint and has been handled.
+func replay(w http.ResponseWriter, is *idempotency.Service, actor string, key string, fingerprint string) {
+	s, res := is.GetResponse(actor, key)
+	switch {
+	case s.Code() == status.NotFound:
+		// The first request failed and released the key in between.
+		http.Error(w, "The request with this Idempotency-Key failed; retry it", status.Conflict)
+	case s.Err() != nil:
+		http.Error(w, s.Message(), s.Code())
+	case res.Fingerprint != fingerprint:
+		http.Error(w, "The Idempotency-Key has already been used for a different request", status.UnprocessableEntity)
+	case res.Status == 0:
+		http.Error(w, "The request with this Idempotency-Key is still being handled", status.Conflict)
+	default:
+		for name, value := range map[string]string{
+			"Content-Type": res.ContentType,
+			"ETag":         res.ETag,
+			"Location":     res.Location,
+		} {
+			if value != "" {
+				w.Header().Set(name, value)
+			}
+		}
+		w.Header().Set(replayedHeader, "true")
+		w.WriteHeader(res.Status)
+		w.Write(res.Body)
+	}
+}
+
+// idempotentWriter keeps the status code and body of a response, so that they can be replayed.
+type idempotentWriter struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (iw *idempotentWriter) WriteHeader(code int) {
+	iw.code = code
+	iw.ResponseWriter.WriteHeader(code)
+}
+
+func (iw *idempotentWriter) Write(b []byte) (int, error) {
+	iw.body.Write(b)
+	return iw.ResponseWriter.Write(b)
+}
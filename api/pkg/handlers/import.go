@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/marc"
+	"github.com/andrewzulaybar/books/api/pkg/onix"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// ImportCSV handles requests made to /api/import/csv
+func ImportCSV(i *importer.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := i.WithActor(actor(r))
+		query := r.URL.Query()
+		mapping, err := importer.ParseMapping(query["map"])
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+
+		rows, err := importer.ParseCSV(r.Body, mapping)
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, report := i.Import(rows, query.Get("commit") == "true")
+		writeReport(w, s, report)
+	})
+}
+
+// ImportGoodreads handles requests made to /api/import/goodreads
+func ImportGoodreads(i *importer.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := i.WithActor(actor(r))
+		query := r.URL.Query()
+		userID := shelfOwner(w, r)
+		if userID == 0 {
+			return
+		}
+
+		rows, err := importer.ParseGoodreads(r.Body, userID)
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, report := i.Import(rows, query.Get("commit") == "true")
+		writeReport(w, s, report)
+	})
+}
+
+// ImportMARC handles requests made to /api/import/marc
+func ImportMARC(i *importer.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := i.WithActor(actor(r))
+		records, err := marc.Read(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, report := i.Import(marc.ToRows(records), r.URL.Query().Get("commit") == "true")
+		writeReport(w, s, report)
+	})
+}
+
+// ImportONIX handles requests made to /api/import/onix
+func ImportONIX(i *importer.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := i.WithActor(actor(r))
+		products, err := onix.Read(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, report := i.Import(onix.ToRows(products, ""), r.URL.Query().Get("commit") == "true")
+		writeReport(w, s, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, s *status.Status, report *importer.Report) {
+	if report == nil {
+		http.Error(w, s.Message(), s.Code())
+		return
+	}
+
+	bytes, err := json.Marshal(*report)
+	if err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(s.Code())
+	w.Write(bytes)
+}
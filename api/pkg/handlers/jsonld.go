@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/jsonld"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// wantsJSONLD reports whether the request's Accept header prefers JSON-LD to plain JSON.
+// Media ranges are compared by quality, with JSON-LD winning ties only when it is listed first.
+func wantsJSONLD(r *http.Request) bool {
+	best, bestQ := "", 0.0
+	for _, accept := range r.Header.Values("Accept") {
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			if q > bestQ {
+				best, bestQ = mediaType, q
+			}
+		}
+	}
+	return best == jsonld.MediaType
+}
+
+// writeJSONLD writes v as a JSON-LD document, or a 304 Not Modified, as writeConditional does.
+func writeJSONLD(w http.ResponseWriter, r *http.Request, v interface{}, modified time.Time) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	writeConditional(w, r, jsonld.MediaType, bytes, modified)
+}
+
+// baseURL returns the scheme and host the request was made to, e.g. https://example.com.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
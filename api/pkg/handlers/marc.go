@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/andrewzulaybar/books/api/pkg/marc"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// PublicationMARC handles requests made to /api/publication/{id:[0-9]+}/marc
+func PublicationMARC(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, pub := p.GetPublication(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeMARC(w, s, publication.Publications{*pub})
+	})
+}
+
+// ExportMARC handles requests made to /api/export/marc
+func ExportMARC(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), status.BadRequest)
+			return
+		}
+
+		s, pubs := p.FilterPublications(filter)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		writeMARC(w, s, pubs)
+	})
+}
+
+func writeMARC(w http.ResponseWriter, s *status.Status, pubs publication.Publications) {
+	records := make([]marc.Record, len(pubs))
+	for i := range pubs {
+		records[i] = marc.FromPublication(&pubs[i])
+	}
+
+	var buf bytes.Buffer
+	if err := marc.WriteXML(&buf, records); err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/marcxml+xml")
+	w.WriteHeader(s.Code())
+	w.Write(buf.Bytes())
+}
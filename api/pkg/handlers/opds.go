@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/opds"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// Paths of the OPDS catalog.
+const (
+	opdsRoot         = "/opds"
+	opdsSearch       = "/opds/opensearch.xml"
+	opdsPublications = "/opds/publications"
+)
+
+// OPDSRoot handles requests made to /opds
+func OPDSRoot() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feed := newOPDSFeed(r, opds.NavigationType, "Books")
+		feed.AddNavigation("By Author", "/opds/authors", opds.NavigationType, "Publications grouped by author")
+		feed.AddNavigation("By Series", "/opds/series", opds.NavigationType, "Publications grouped by series")
+		feed.AddNavigation("By Genre", "/opds/genres", opds.NavigationType, "Publications grouped by genre")
+		feed.AddNavigation("New", opdsPublications+"?sort=new", opds.AcquisitionType, "Publications added most recently")
+		feed.AddNavigation("All Publications", opdsPublications, opds.AcquisitionType, "Every publication in the catalogue")
+		writeOPDS(w, opds.NavigationType, feed)
+	})
+}
+
+// OPDSAuthors handles requests made to /opds/authors
+func OPDSAuthors(a *author.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, authors := a.GetAuthors()
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+		sort.SliceStable(authors, func(i, j int) bool {
+			return strings.ToLower(authors[i].LastName+authors[i].FirstName) <
+				strings.ToLower(authors[j].LastName+authors[j].FirstName)
+		})
+
+		items := make([][2]string, len(authors))
+		for i, au := range authors {
+			name := au.LastName
+			if au.FirstName != "" {
+				name += ", " + au.FirstName
+			}
+			items[i] = [2]string{name, fmt.Sprintf("%s?author=%d", opdsPublications, au.ID)}
+		}
+		writeOPDSNavigation(w, r, "Authors", items)
+	})
+}
+
+// OPDSSeries handles requests made to /opds/series
+func OPDSSeries(wk *work.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, series := wk.GetSeries()
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		items := make([][2]string, len(series))
+		for i, name := range series {
+			items[i] = [2]string{name, opdsPublications + "?series=" + url.QueryEscape(name)}
+		}
+		writeOPDSNavigation(w, r, "Series", items)
+	})
+}
+
+// OPDSGenres handles requests made to /opds/genres
+func OPDSGenres(g *genre.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, genres := g.GetNames()
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		items := make([][2]string, len(genres))
+		for i, name := range genres {
+			items[i] = [2]string{name, opdsPublications + "?genre=" + url.QueryEscape(name)}
+		}
+		writeOPDSNavigation(w, r, "Genres", items)
+	})
+}
+
+// OPDSPublications handles requests made to /opds/publications
+func OPDSPublications(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		page := opdsPage(query)
+		filter := &publication.Filter{
+			Series: query.Get("series"),
+			Genre:  query.Get("genre"),
+			Search: strings.TrimSpace(query.Get("q")),
+			Newest: query.Get("sort") == "new",
+			// One more than a page is fetched to find out whether there is a next page.
+			Limit:  opds.PageSize + 1,
+			Offset: (page - 1) * opds.PageSize,
+		}
+		if v := query.Get("author"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("author: %q is not an id", v), status.BadRequest)
+				return
+			}
+			filter.AuthorID = id
+		}
+
+		s, pubs := p.FilterPublications(filter)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		title := "Publications"
+		switch {
+		case filter.Search != "":
+			title = fmt.Sprintf("Search results for %q", filter.Search)
+		case filter.Series != "":
+			title = filter.Series
+		case filter.Genre != "":
+			title = filter.Genre
+		case filter.Newest:
+			title = "New"
+		case filter.AuthorID != 0 && len(pubs) > 0:
+			au := pubs[0].Work.Author
+			title = strings.TrimSpace(au.FirstName + " " + au.LastName)
+		}
+
+		feed := newOPDSFeed(r, opds.AcquisitionType, title)
+		hasNext := len(pubs) > opds.PageSize
+		if hasNext {
+			pubs = pubs[:opds.PageSize]
+		}
+		for i := range pubs {
+			feed.AddPublication(&pubs[i])
+		}
+		feed.Paginate(page, hasNext)
+		writeOPDS(w, opds.AcquisitionType, feed)
+	})
+}
+
+// OPDSSearch handles requests made to /opds/opensearch.xml
+func OPDSSearch() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := opds.WriteOpenSearch(&buf, "Books", opdsPublications+"?q={searchTerms}"); err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", opds.OpenSearchType)
+		w.WriteHeader(status.OK)
+		w.Write(buf.Bytes())
+	})
+}
+
+func newOPDSFeed(r *http.Request, kind string, title string) *opds.Feed {
+	return opds.NewFeed(kind, title, r.URL, opdsRoot, opdsSearch, time.Now())
+}
+
+// writeOPDSNavigation writes a page of a navigation feed whose entries link to the given
+// acquisition feeds, each item being a title and a link.
+func writeOPDSNavigation(w http.ResponseWriter, r *http.Request, title string, items [][2]string) {
+	page := opdsPage(r.URL.Query())
+	start := (page - 1) * opds.PageSize
+	if start > len(items) {
+		start = len(items)
+	}
+	end := start + opds.PageSize
+	if end > len(items) {
+		end = len(items)
+	}
+
+	feed := newOPDSFeed(r, opds.NavigationType, title)
+	for _, item := range items[start:end] {
+		feed.AddNavigation(item[0], item[1], opds.AcquisitionType, "")
+	}
+	feed.Paginate(page, end < len(items))
+	writeOPDS(w, opds.NavigationType, feed)
+}
+
+func writeOPDS(w http.ResponseWriter, kind string, feed *opds.Feed) {
+	var buf bytes.Buffer
+	if err := feed.Write(&buf); err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", kind)
+	w.WriteHeader(status.OK)
+	w.Write(buf.Bytes())
+}
+
+// opdsPage returns the page number in the page query parameter, counted from 1.
+func opdsPage(query url.Values) int {
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
@@ -0,0 +1,598 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/audit"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/pkg/history"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
+	"github.com/andrewzulaybar/books/api/pkg/openapi"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// swaggerUI is the page that renders the OpenAPI document with Swagger UI.
+const swaggerUI string = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Books API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>window.ui = SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+`
+
+// OpenAPI handles requests made to /api/{version}/openapi.json
+func OpenAPI(v *Version) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bytes, err := json.MarshalIndent(Spec(v), "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status.OK)
+		w.Write(bytes)
+	})
+}
+
+// SwaggerUI handles requests made to /api/{version}/docs
+func SwaggerUI(v *Version) http.HandlerFunc {
+	api := v.Prefix()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status.OK)
+		fmt.Fprintf(w, swaggerUI, api+"/openapi.json")
+	})
+}
+
+// Spec returns the OpenAPI document describing every route of the given version of the API, along with
+// the unversioned GraphQL and OPDS routes. Schemas are generated from the types that the handlers encode and decode.
+func Spec(v *Version) *openapi.Document {
+	d := openapi.New("Books API", "The catalogue of publications, works and authors, and the shelves of its users.",
+		strings.TrimPrefix(v.Name, "v")+".0.0")
+	api := v.Prefix()
+
+	pub := d.SchemaOf(publication.Publication{})
+	pubs := d.SchemaOf(publication.Publications{})
+	wk := d.SchemaOf(work.Work{})
+	works := d.SchemaOf(work.Works{})
+	au := d.SchemaOf(author.Author{})
+	authors := d.SchemaOf(author.Authors{})
+	d.SchemaOf(location.Location{})
+	ids := d.SchemaNamed("Identifiers", identifiers{})
+	report := d.SchemaOf(importer.Report{})
+
+	idParam := openapi.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}
+	filterParams := []openapi.Parameter{
+		query("ids", "Comma-separated publication ids."),
+		query("author", "The id of the publications' author."),
+		query("work", "The id of the publications' work."),
+		query("language", "The language of the publications, e.g. English."),
+		query("publisher", "The publisher of the publications."),
+	}
+	formatParam := query("format", "The format of the publications, e.g. Hardcover.")
+	ifMatch := openapi.Parameter{Name: "If-Match", In: "header", Description: "The ETag of the entry as last retrieved.",
+		Schema: &openapi.Schema{Type: "string"}}
+	commit := query("commit", "Commits the import when true; otherwise it is a dry run.")
+	cites := citationResponses()
+	atomic := query("atomic", "Applies every item or none when true; otherwise each item is applied on its own.")
+	batchResults := jsonContent("The result of each item, in order.", d.SchemaOf([]BatchResult{}))
+	batchResponses := func() map[string]*openapi.Response {
+		res := responses(status.OK, batchResults, status.BadRequest, status.UnprocessableEntity)
+		res[fmt.Sprint(status.MultiStatus)] = jsonContent("The result of each item, in order, some of which failed.",
+			d.SchemaOf([]BatchResult{}))
+		return res
+	}
+	adminToken := openapi.Parameter{Name: "Authorization", In: "header", Required: true, Description: "Bearer followed by the admin token.",
+		Schema: &openapi.Schema{Type: "string"}}
+	shelfUser := query("user", "The id of the user whose shelves are used. It defaults to the authenticated user, "+
+		"and only the admin may give another user's.")
+	follow := query("follow", "Serves the entry that the requested one was merged into when true, instead of redirecting to it.")
+
+	// Each entity has the same five operations.
+	crud := func(tag string, path string, one *openapi.Schema, many *openapi.Schema) {
+		d.Add(http.MethodGet, path, &openapi.Operation{
+			Summary: fmt.Sprintf("Retrieves every %s.", tag), Tags: []string{tag},
+			Description: conditionalGet,
+			Responses:   withNotModified(responses(status.OK, jsonContent("The list.", many))),
+		})
+		d.Add(http.MethodPost, path, &openapi.Operation{
+			Summary: fmt.Sprintf("Creates a %s.", tag), Tags: []string{tag},
+			RequestBody: jsonBody(one),
+			Responses: responses(status.Created, jsonContent("The created entry.", one),
+				status.Conflict, status.UnprocessableEntity),
+		})
+		deleteMany := responses(status.NoContent, &openapi.Response{Description: "Every entry was deleted."}, status.UnprocessableEntity)
+		deleteMany["200"] = jsonContent("The ids that could not be found; the others were deleted.", ids)
+		d.Add(http.MethodDelete, path, &openapi.Operation{
+			Summary: fmt.Sprintf("Deletes the %s entries matching the given ids.", tag), Tags: []string{tag},
+			Description: softDelete,
+			RequestBody: jsonBody(ids),
+			Responses:   deleteMany,
+		})
+		d.Add(http.MethodGet, path+"/{id}", &openapi.Operation{
+			Summary: fmt.Sprintf("Retrieves the %s matching the given id.", tag), Tags: []string{tag},
+			Description: "With `Accept: application/ld+json`, the entry is described with schema.org terms. " +
+				conditionalGet + " " + redirected,
+			Parameters: []openapi.Parameter{idParam, follow},
+			Responses: withMoved(withNotModified(responses(status.OK, &openapi.Response{
+				Description: "The entry.",
+				Content: map[string]*openapi.MediaType{
+					"application/json":    {Schema: one},
+					"application/ld+json": {Schema: &openapi.Schema{Type: "object"}},
+				},
+			}, status.BadRequest, status.NotFound))),
+		})
+		d.Add(http.MethodPatch, path+"/{id}", &openapi.Operation{
+			Summary: fmt.Sprintf("Updates the %s matching the given id.", tag), Tags: []string{tag},
+			Description: mergePatch + " " + conditionalWrite,
+			Parameters:  []openapi.Parameter{idParam, ifMatch},
+			RequestBody: &openapi.RequestBody{Required: true, Content: map[string]*openapi.MediaType{
+				"application/merge-patch+json": {Schema: one},
+				"application/json":             {Schema: one},
+			}},
+			Responses: responses(status.OK, jsonContent("The updated entry.", one),
+				status.NotFound, status.Conflict, status.PreconditionFailed, status.UnprocessableEntity),
+		})
+		d.Add(http.MethodDelete, path+"/{id}", &openapi.Operation{
+			Summary: fmt.Sprintf("Deletes the %s matching the given id.", tag), Tags: []string{tag},
+			Description: softDelete + " " + conditionalWrite,
+			Parameters:  []openapi.Parameter{idParam, ifMatch},
+			Responses: responses(status.NoContent, &openapi.Response{Description: "The entry was deleted."},
+				status.PreconditionFailed),
+		})
+		d.Add(http.MethodPost, path+"/batch", &openapi.Operation{
+			Summary: fmt.Sprintf("Creates the %s entries in the body.", tag), Tags: []string{tag},
+			Description: batchDescription,
+			Parameters:  []openapi.Parameter{atomic},
+			RequestBody: jsonBody(many),
+			Responses:   batchResponses(),
+		})
+		d.Add(http.MethodPatch, path+"/batch", &openapi.Operation{
+			Summary: fmt.Sprintf("Updates the %s entries matching the ids in the body.", tag), Tags: []string{tag},
+			Description: "Each item is a merge patch with the id of the entry to update. " + mergePatch + " " + batchDescription,
+			Parameters:  []openapi.Parameter{atomic},
+			RequestBody: &openapi.RequestBody{Required: true, Content: map[string]*openapi.MediaType{
+				"application/merge-patch+json": {Schema: many},
+				"application/json":             {Schema: many},
+			}},
+			Responses: batchResponses(),
+		})
+		d.Add(http.MethodPost, path+"/{id}/restore", &openapi.Operation{
+			Summary: fmt.Sprintf("Restores the deleted %s matching the given id.", tag), Tags: []string{tag},
+			Parameters: []openapi.Parameter{idParam},
+			Responses:  responses(status.OK, jsonContent("The restored entry.", one), status.NotFound, status.Conflict),
+		})
+	}
+	crud("publication", api+"/publication", pub, pubs)
+	crud("work", api+"/work", wk, works)
+	crud("author", api+"/author", au, authors)
+
+	// Works and authors can be reviewed and reverted through their history.
+	revisions := d.SchemaOf(history.Revisions{})
+	for _, tag := range []string{"work", "author"} {
+		d.Add(http.MethodGet, api+"/"+tag+"/{id}/history", &openapi.Operation{
+			Summary: fmt.Sprintf("Retrieves the revisions of the %s matching the given id, oldest first.", tag), Tags: []string{tag},
+			Description: "Each revision lists the fields it changed, with their values before and after.",
+			Parameters:  []openapi.Parameter{idParam},
+			Responses:   withNotModified(responses(status.OK, jsonContent("The revisions.", revisions), status.NotFound)),
+		})
+		patch := d.Operation(http.MethodPatch, api+"/"+tag+"/{id}")
+		patch.Description += " With `revert`, the body is ignored and the entry is patched with its fields as they were " +
+			"after the given revision."
+		patch.Parameters = append(patch.Parameters, query("revert", "The id of the revision to revert to."))
+		for code, res := range responses(status.OK, patch.Responses["200"], status.NotFound, status.UnprocessableEntity) {
+			patch.Responses[code] = res
+		}
+	}
+
+	// Works and authors entered more than once can be found and merged.
+	threshold := query("threshold", "The similarity, between 0 and 1, above which entries are proposed; 0.9 by default.")
+	for _, entity := range []struct {
+		tag        string
+		one        *openapi.Schema
+		candidates *openapi.Schema
+		merge      string
+	}{
+		{"work", wk, d.SchemaOf([]dedup.WorkCandidate{}),
+			"Their publications are moved to the work, which also takes on their genres."},
+		{"author", au, d.SchemaOf([]dedup.AuthorCandidate{}),
+			"Their works are moved to the author, or merged into the author's work of the same title."},
+	} {
+		d.Add(http.MethodGet, api+"/"+entity.tag+"/duplicates", &openapi.Operation{
+			Summary: fmt.Sprintf("Proposes pairs of %s entries that may be duplicates, most similar first.", entity.tag),
+			Tags:    []string{entity.tag},
+			Description: "Names and titles are compared ignoring case, accents and punctuation, and scored by their " +
+				"Jaro-Winkler similarity. Works are only compared with the other works of their author. " + adminOnly,
+			Parameters: []openapi.Parameter{adminToken, threshold},
+			Responses: responses(status.OK, jsonContent("The candidate pairs.", entity.candidates),
+				status.BadRequest, status.Unauthorized, status.Forbidden),
+		})
+		d.Add(http.MethodPost, api+"/"+entity.tag+"/{id}/merge", &openapi.Operation{
+			Summary: fmt.Sprintf("Merges the %s entries matching the ids in the body into the one matching the given id.", entity.tag),
+			Tags:    []string{entity.tag},
+			Description: entity.merge + " The merged entries are then deleted, and requests for their ids are " +
+				"redirected to the entry they were merged into. Either every entry is merged or none is. " + adminOnly,
+			Parameters:  []openapi.Parameter{adminToken, idParam},
+			RequestBody: jsonBody(ids),
+			Responses: responses(status.OK, jsonContent("The entry merged into.", entity.one),
+				status.Unauthorized, status.Forbidden, status.NotFound, status.Conflict, status.UnprocessableEntity),
+		})
+	}
+
+	d.Add(http.MethodGet, api+"/work/{id}/publications", &openapi.Operation{
+		Summary: "Retrieves the editions of the work matching the given id, oldest first.",
+		Tags:    []string{"work"},
+		Description: "Each edition is a publication, compared with every publication of the work: whether it is a " +
+			"first edition, whether it is a translation, and how many more pages than the first edition it has.",
+		Parameters: []openapi.Parameter{idParam, formatParam,
+			query("language", "The language of the publications, e.g. English."),
+			query("newest", "Orders the editions newest first when true.")},
+		Responses: withNotModified(responses(status.OK,
+			jsonContent("The editions.", d.SchemaOf(publication.Editions{})), status.BadRequest, status.NotFound)),
+	})
+
+	// Authors have a bibliography, and may have written under other names.
+	alias := d.SchemaOf(author.Alias{})
+	d.Add(http.MethodGet, api+"/author/{id}/bibliography", &openapi.Operation{
+		Summary: "Retrieves the author matching the given id, with their aliases, works and statistics.",
+		Tags:    []string{"author"},
+		Description: "Works are ordered by the date they were first published, each with its number of editions. " +
+			"Languages are those the works have been published in, and the average rating is that of the ratings " +
+			"given to the author's publications on the shelves of users.",
+		Parameters: []openapi.Parameter{idParam},
+		Responses: withNotModified(responses(status.OK,
+			jsonContent("The bibliography.", d.SchemaOf(bibliography.Bibliography{})), status.NotFound)),
+	})
+	d.Add(http.MethodGet, api+"/author/{id}/aliases", &openapi.Operation{
+		Summary:    "Retrieves the aliases of the author matching the given id.",
+		Tags:       []string{"author"},
+		Parameters: []openapi.Parameter{idParam},
+		Responses: withNotModified(responses(status.OK,
+			jsonContent("The aliases.", d.SchemaOf(author.Aliases{})), status.NotFound)),
+	})
+	d.Add(http.MethodPost, api+"/author/{id}/aliases", &openapi.Operation{
+		Summary: "Adds an alias, such as a pen name, to the author matching the given id.",
+		Tags:    []string{"author"},
+		Description: "Searches for publications match their authors' aliases, and authors are found by alias when " +
+			"matching imported publications. Each name can only be the alias of one author.",
+		Parameters:  []openapi.Parameter{idParam},
+		RequestBody: jsonBody(alias),
+		Responses: responses(status.Created, jsonContent("The created alias.", alias),
+			status.NotFound, status.Conflict, status.UnprocessableEntity),
+	})
+	d.Add(http.MethodDelete, api+"/author/{id}/aliases/{aliasId}", &openapi.Operation{
+		Summary: "Removes the alias matching the given alias id from the author matching the given id.",
+		Tags:    []string{"author"},
+		Parameters: []openapi.Parameter{idParam,
+			{Name: "aliasId", In: "path", Required: true, Schema: &openapi.Schema{Type: "integer"}}},
+		Responses: responses(status.NoContent, &openapi.Response{Description: "The alias was removed."}, status.NotFound),
+	})
+
+	d.Add(http.MethodPost, api+"/publication/enrich", &openapi.Operation{
+		Summary:     "Previews the publication with the given ISBN, merged with metadata from Open Library and Google Books.",
+		Tags:        []string{"publication"},
+		Parameters:  []openapi.Parameter{required(query("isbn", "The ISBN-10 or ISBN-13 to look up."))},
+		RequestBody: &openapi.RequestBody{Content: map[string]*openapi.MediaType{"application/json": {Schema: pub}}},
+		Responses: responses(status.OK, jsonContent("The merged publication and the source of each field.", d.SchemaOf(metadata.Preview{})),
+			status.BadRequest, status.NotFound, status.UnprocessableEntity, status.BadGateway),
+	})
+	d.Add(http.MethodGet, api+"/publication/cite", &openapi.Operation{
+		Summary:     "Retrieves citations of the publications matching the given filters.",
+		Tags:        []string{"publication"},
+		Description: "`format` is the citation format, so publications cannot be filtered by format.",
+		Parameters:  append([]openapi.Parameter{citationFormat()}, filterParams...),
+		Responses:   cites,
+	})
+	d.Add(http.MethodGet, api+"/publication/{id}/cite", &openapi.Operation{
+		Summary:    "Retrieves a citation of the publication matching the given id.",
+		Tags:       []string{"publication"},
+		Parameters: []openapi.Parameter{idParam, citationFormat()},
+		Responses:  cites,
+	})
+	d.Add(http.MethodGet, api+"/publication/{id}/marc", &openapi.Operation{
+		Summary:    "Retrieves the publication matching the given id as a MARCXML record.",
+		Tags:       []string{"publication"},
+		Parameters: []openapi.Parameter{idParam},
+		Responses:  responses(status.OK, textContent("The record.", "application/marcxml+xml"), status.NotFound),
+	})
+
+	d.Add(http.MethodGet, api+"/user/{id}/shelf", &openapi.Operation{
+		Summary:     "Retrieves every entry on the shelves of the user matching the given id.",
+		Tags:        []string{"shelf"},
+		Description: "Only that user and the admin may retrieve them.",
+		Parameters:  []openapi.Parameter{idParam},
+		Responses: responses(status.OK, jsonContent("The entries.", d.SchemaOf(shelf.Entries{})),
+			status.Unauthorized, status.Forbidden),
+	})
+
+	imports := []struct {
+		path, summary, mediaType string
+		params                   []openapi.Parameter
+	}{
+		{api + "/import/csv", "Imports the publications in a CSV file.", "text/csv",
+			[]openapi.Parameter{commit, query("map", "Maps a header onto a field, as Header:field. May be repeated.")}},
+		{api + "/import/goodreads", "Imports a Goodreads library export onto the shelves of a user.", "text/csv",
+			[]openapi.Parameter{commit, shelfUser}},
+		{api + "/import/marc", "Imports binary MARC21 or MARCXML records.", "application/marc", []openapi.Parameter{commit}},
+		{api + "/import/onix", "Imports the products of an ONIX 3.0 message.", "application/xml", []openapi.Parameter{commit}},
+	}
+	for _, imp := range imports {
+		d.Add(http.MethodPost, imp.path, &openapi.Operation{
+			Summary:    imp.summary,
+			Tags:       []string{"import"},
+			Parameters: imp.params,
+			RequestBody: &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]*openapi.MediaType{imp.mediaType: {Schema: &openapi.Schema{Type: "string", Format: "binary"}}},
+			},
+			Responses: responses(status.OK, jsonContent("The outcome of each row.", report),
+				status.BadRequest, status.UnprocessableEntity),
+		})
+	}
+
+	d.Operation(http.MethodPost, api+"/import/goodreads").Responses[fmt.Sprint(status.Forbidden)] = errorResponse(status.Forbidden)
+
+	as := query("as", "The format of the export: csv (the default), ndjson or goodreads.")
+	d.Add(http.MethodGet, api+"/export/marc", &openapi.Operation{
+		Summary:    "Retrieves the publications matching the given filters as a MARCXML collection.",
+		Tags:       []string{"export"},
+		Parameters: append([]openapi.Parameter{formatParam}, filterParams...),
+		Responses:  responses(status.OK, textContent("The collection.", "application/marcxml+xml"), status.BadRequest),
+	})
+	d.Add(http.MethodGet, api+"/export/publications", &openapi.Operation{
+		Summary:    "Retrieves the publications matching the given filters.",
+		Tags:       []string{"export"},
+		Parameters: append([]openapi.Parameter{as, formatParam}, filterParams...),
+		Responses:  responses(status.OK, exportContent("The publications."), status.BadRequest),
+	})
+	d.Add(http.MethodGet, api+"/export/shelf", &openapi.Operation{
+		Summary:    "Retrieves every entry on the shelves of a user.",
+		Tags:       []string{"export"},
+		Parameters: []openapi.Parameter{as, shelfUser},
+		Responses:  responses(status.OK, exportContent("The entries."), status.BadRequest, status.Unauthorized, status.Forbidden),
+	})
+
+	d.Add(http.MethodGet, api+"/admin/audit", &openapi.Operation{
+		Summary:     "Retrieves the entries of the audit log matching the given filters, newest first.",
+		Tags:        []string{"admin"},
+		Description: "Every request that may change the catalogue is logged. " + adminOnly,
+		Parameters: []openapi.Parameter{
+			adminToken,
+			query("actor", "Who made the requests."),
+			query("entity", "The entity the requests were made to, e.g. work."),
+			query("id", "The id of an entry the requests concerned."),
+			query("from", "The earliest time of the requests, in RFC 3339 format."),
+			query("to", "The time before which the requests were made, in RFC 3339 format."),
+			query("limit", "The maximum number of entries to retrieve."),
+			query("offset", "The number of entries to skip."),
+		},
+		Responses: responses(status.OK, jsonContent("The entries.", d.SchemaOf(audit.Entries{})),
+			status.BadRequest, status.Unauthorized, status.Forbidden),
+	})
+
+	d.Add(http.MethodGet, api+"/openapi.json", &openapi.Operation{
+		Summary:   "Retrieves this document.",
+		Tags:      []string{"documentation"},
+		Responses: responses(status.OK, jsonContent("The OpenAPI document.", &openapi.Schema{Type: "object"})),
+	})
+	d.Add(http.MethodGet, api+"/docs", &openapi.Operation{
+		Summary:   "Renders this document with Swagger UI.",
+		Tags:      []string{"documentation"},
+		Responses: responses(status.OK, textContent("The page.", "text/html")),
+	})
+
+	graphqlResult := jsonContent("The data and errors of the query.", &openapi.Schema{Type: "object"})
+	d.Add(http.MethodGet, "/graphql", &openapi.Operation{
+		Summary: "Answers a GraphQL query.",
+		Tags:    []string{"graphql"},
+		Parameters: []openapi.Parameter{
+			required(query("query", "The GraphQL query.")),
+			query("operationName", "The operation of the query to execute."),
+			query("variables", "The variables of the query, as a JSON object."),
+		},
+		Responses: responses(status.OK, graphqlResult, status.BadRequest),
+	})
+	d.Add(http.MethodPost, "/graphql", &openapi.Operation{
+		Summary:     "Answers a GraphQL query.",
+		Tags:        []string{"graphql"},
+		RequestBody: jsonBody(d.SchemaNamed("GraphQLRequest", graph.Request{})),
+		Responses:   responses(status.OK, graphqlResult, status.BadRequest),
+	})
+
+	page := query("page", "The page of the feed, counted from 1.")
+	opdsFeeds := []struct {
+		path, summary, kind string
+		params              []openapi.Parameter
+	}{
+		{"/opds", "Retrieves the root navigation feed of the OPDS catalog.", "navigation", nil},
+		{"/opds/authors", "Retrieves a navigation feed of every author.", "navigation", []openapi.Parameter{page}},
+		{"/opds/series", "Retrieves a navigation feed of every series.", "navigation", []openapi.Parameter{page}},
+		{"/opds/genres", "Retrieves a navigation feed of every genre.", "navigation", []openapi.Parameter{page}},
+		{"/opds/publications", "Retrieves an acquisition feed of the publications matching the given filters.", "acquisition",
+			[]openapi.Parameter{
+				query("author", "The id of the publications' author."),
+				query("series", "The series of the publications' work."),
+				query("genre", "A genre of the publications' work."),
+				query("q", "Searches titles and author names."),
+				query("sort", "Puts the most recently added publications first when new."),
+				page,
+			}},
+	}
+	for _, feed := range opdsFeeds {
+		d.Add(http.MethodGet, feed.path, &openapi.Operation{
+			Summary:    feed.summary,
+			Tags:       []string{"opds"},
+			Parameters: feed.params,
+			Responses:  responses(status.OK, textContent("The feed.", "application/atom+xml;profile=opds-catalog;kind="+feed.kind)),
+		})
+	}
+	d.Add(http.MethodGet, "/opds/opensearch.xml", &openapi.Operation{
+		Summary:   "Retrieves the OpenSearch description of the OPDS catalog's search.",
+		Tags:      []string{"opds"},
+		Responses: responses(status.OK, textContent("The description.", "application/opensearchdescription+xml")),
+	})
+
+	// Every POST under the API can be retried safely with an idempotency key.
+	idempotencyKey := openapi.Parameter{Name: "Idempotency-Key", In: "header", Schema: &openapi.Schema{Type: "string"},
+		Description: "A unique key for the request. Retries with the same key within 24 hours receive the first " +
+			"response again, with an `Idempotent-Replayed` header, instead of being handled again. The body of the request " +
+			"may then be at most 10 MiB."}
+	// Every request under the API that may change the catalogue requires credentials.
+	authorization := openapi.Parameter{Name: "Authorization", In: "header", Required: true, Schema: &openapi.Schema{Type: "string"},
+		Description: "Basic followed by the username and password of a user, or Bearer followed by the admin token. " +
+			"The changes made are recorded in the history and the audit log as made by them."}
+	for path, item := range d.Paths {
+		if !strings.HasPrefix(path, api+"/") {
+			continue
+		}
+		for method, op := range *item {
+			if method != strings.ToLower(http.MethodGet) && !hasParameter(op, authorization.Name) {
+				op.Parameters = append(op.Parameters, authorization)
+				if _, ok := op.Responses[fmt.Sprint(status.Unauthorized)]; !ok {
+					op.Responses[fmt.Sprint(status.Unauthorized)] = errorResponse(status.Unauthorized)
+				}
+			}
+			if method == strings.ToLower(http.MethodPost) {
+				op.Parameters = append(op.Parameters, idempotencyKey)
+				for _, code := range []int{status.BadRequest, status.Conflict, status.UnprocessableEntity} {
+					if _, ok := op.Responses[fmt.Sprint(code)]; !ok {
+						op.Responses[fmt.Sprint(code)] = errorResponse(code)
+					}
+				}
+			}
+			op.Deprecated = v.Deprecated()
+			if v.StructuredErrors {
+				structureErrors(d, op)
+			}
+		}
+	}
+	return d
+}
+
+// hasParameter returns whether the operation has a parameter with the given name.
+func hasParameter(op *openapi.Operation, name string) bool {
+	for _, p := range op.Parameters {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// structureErrors describes the error responses of an operation as JSON objects rather than plain text.
+func structureErrors(d *openapi.Document, op *openapi.Operation) {
+	for code := range op.Responses {
+		if n, err := strconv.Atoi(code); err == nil && n >= status.BadRequest {
+			op.Responses[code] = jsonContent(http.StatusText(n)+".", d.SchemaOf(Error{}))
+		}
+	}
+}
+
+// Descriptions shared by the operations on the entities.
+const (
+	conditionalGet = "Responses carry an `ETag` and a `Last-Modified` header. With a matching `If-None-Match` or " +
+		"an `If-Modified-Since` no earlier than the last modification, a 304 Not Modified is returned without a body."
+	softDelete = "Deleted entries are kept, and can be restored, until they are purged after the retention period. " +
+		"Deleting a work deletes its publications too."
+	mergePatch = "The body is a JSON Merge Patch (RFC 7396): the fields it names are set, even to empty values, and " +
+		"those it sets to null are emptied. Embedded entries are replaced rather than merged. Naming a field that " +
+		"cannot be patched, such as the id, or emptying a required one is a 422 Unprocessable Entity."
+	batchDescription = "A batch holds at most 100 items. Each result has the status code the item would have had on " +
+		"its own, and the entry if it succeeded. When some items of a batch that is not atomic fail, the response is a " +
+		"207 Multi-Status. When an atomic batch fails, the response has the status of the item " +
+		"that failed, and the other items are reported as 424 Failed Dependency."
+	redirected = "Requesting an entry that has been merged into another is answered with a 301 Moved Permanently " +
+		"to the other entry, or with the other entry itself and a `Content-Location` header when `follow` is true."
+	adminOnly        = "Requires the admin token as a bearer token, and is forbidden when no admin token is configured."
+	conditionalWrite = "With an `If-Match` header, the request only succeeds if the entry's current ETag matches; " +
+		"otherwise a 412 Precondition Failed is returned."
+)
+
+// withNotModified adds the 304 response of conditional GET requests to the given responses.
+func withNotModified(res map[string]*openapi.Response) map[string]*openapi.Response {
+	res[fmt.Sprint(status.NotModified)] = &openapi.Response{Description: "The representation held by the client is current."}
+	return res
+}
+
+// withMoved adds the 301 response to requests for entries that have been merged into another to the given responses.
+func withMoved(res map[string]*openapi.Response) map[string]*openapi.Response {
+	res[fmt.Sprint(status.MovedPermanently)] = &openapi.Response{Description: "The entry has been merged into the one at the URL in the `Location` header."}
+	return res
+}
+
+// responses returns the responses of an operation that succeeds with the given code and response, and
+// fails with the given error codes or an internal server error.
+func responses(code int, r *openapi.Response, errs ...int) map[string]*openapi.Response {
+	res := map[string]*openapi.Response{fmt.Sprint(code): r}
+	for _, code := range append(errs, status.InternalServerError) {
+		res[fmt.Sprint(code)] = errorResponse(code)
+	}
+	return res
+}
+
+// errorResponse returns the response of an operation that fails with the given code.
+func errorResponse(code int) *openapi.Response {
+	return &openapi.Response{
+		Description: http.StatusText(code) + ". The body is a message explaining why, as plain text.",
+		Content:     map[string]*openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}},
+	}
+}
+
+func jsonContent(description string, schema *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: description, Content: map[string]*openapi.MediaType{"application/json": {Schema: schema}}}
+}
+
+func textContent(description string, mediaType string) *openapi.Response {
+	return &openapi.Response{Description: description, Content: map[string]*openapi.MediaType{mediaType: {Schema: &openapi.Schema{Type: "string"}}}}
+}
+
+func exportContent(description string) *openapi.Response {
+	r := textContent(description, "text/csv")
+	r.Content["application/x-ndjson"] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	return r
+}
+
+func citationResponses() map[string]*openapi.Response {
+	r := textContent("The citations.", "application/x-bibtex")
+	r.Content["application/x-research-info-systems"] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	r.Content["application/vnd.citationstyles.csl+json"] = &openapi.MediaType{Schema: &openapi.Schema{Type: "array"}}
+	return responses(status.OK, r, status.BadRequest, status.NotFound)
+}
+
+func citationFormat() openapi.Parameter {
+	p := query("format", "The citation format: bibtex (the default), ris or csl-json.")
+	p.Schema.Enum = []string{"bibtex", "ris", "csl-json"}
+	return p
+}
+
+func jsonBody(schema *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: map[string]*openapi.MediaType{"application/json": {Schema: schema}}}
+}
+
+func query(name string, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: "string"}}
+}
+
+func required(p openapi.Parameter) openapi.Parameter {
+	p.Required = true
+	return p
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/andrewzulaybar/books/api/pkg/patch"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// decodePatch reads the JSON Merge Patch in the body of the request, and checks that it only names fields that can be
+// patched and does not remove or empty a required one.
+func decodePatch(r *http.Request, fields patch.Fields) (*status.Status, patch.Patch) {
+	p, err := patch.Decode(r.Body)
+	if err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), nil
+	}
+	if err := p.Check(fields); err != nil {
+		return status.New(status.UnprocessableEntity, err.Error()), nil
+	}
+	return status.New(status.OK, ""), p
+}
@@ -5,14 +5,23 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/andrewzulaybar/books/api/pkg/jsonld"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
 	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/gorilla/mux"
 )
 
+// identifiers is the body of requests deleting many entries, and of responses listing those that were not found.
+type identifiers struct {
+	IDs []int `json:"ids"`
+}
+
 // Publication handles requests made to /api/publication/{id:[0-9]+}
-func Publication(p *publication.Service) http.HandlerFunc {
+func Publication(p *publication.Service, rd *redirect.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := p.WithActor(actor(r))
 		vars := mux.Vars(r)
 		id, err := strconv.Atoi(vars["id"])
 		if err != nil {
@@ -22,29 +31,36 @@ func Publication(p *publication.Service) http.HandlerFunc {
 
 		switch r.Method {
 		case http.MethodGet:
+			w.Header().Add("Vary", "Accept")
 			s, pub := p.GetPublication(id)
+			if s.Code() == status.NotFound {
+				if id = followRedirect(w, r, rd, publication.Entity, id, s); id == 0 {
+					return
+				}
+				s, pub = p.GetPublication(id)
+			}
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(*pub)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := p.LastModified([]int{id})
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
+			if wantsJSONLD(r) {
+				writeJSONLD(w, r, jsonld.FromPublication(baseURL(r), pub), modified)
+				return
+			}
+			writeJSON(w, r, *pub, modified)
 		case http.MethodPatch:
-			var pub publication.Publication
-			pub.ID = id
-			if err := json.NewDecoder(r.Body).Decode(&pub); err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, pt := decodePatch(r, publication.Fields)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
 
-			s, updated := p.PatchPublication(&pub)
+			s, updated := patchPublication(p, r, id, pt)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -59,7 +75,7 @@ func Publication(p *publication.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			s := p.DeletePublication(id)
+			s := deletePublication(p, r, id)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -69,9 +85,38 @@ func Publication(p *publication.Service) http.HandlerFunc {
 	})
 }
 
+// RestorePublication handles requests made to /api/publication/{id:[0-9]+}/restore
+func RestorePublication(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := p.WithActor(actor(r))
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, restored := p.RestorePublication(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(*restored)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
 // Publications handles requests made to /api/publication
 func Publications(p *publication.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := p.WithActor(actor(r))
 		switch r.Method {
 		case http.MethodGet:
 			s, pubs := p.GetPublications()
@@ -79,15 +124,12 @@ func Publications(p *publication.Service) http.HandlerFunc {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(pubs)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := p.LastModified(nil)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
+			writeJSON(w, r, pubs, modified)
 		case http.MethodPost:
 			var pub publication.Publication
 			if err := json.NewDecoder(r.Body).Decode(&pub); err != nil {
@@ -110,10 +152,6 @@ func Publications(p *publication.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			type identifiers struct {
-				IDs []int `json:"ids"`
-			}
-
 			var toDelete identifiers
 			if err := json.NewDecoder(r.Body).Decode(&toDelete); err != nil {
 				http.Error(w, err.Error(), status.UnprocessableEntity)
@@ -140,3 +178,80 @@ func Publications(p *publication.Service) http.HandlerFunc {
 		}
 	})
 }
+
+// PublicationBatch handles requests made to /api/publication/batch
+func PublicationBatch(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := p.WithActor(actor(r))
+		s, b := decodeBatch(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		handle := func(p *publication.Service) *status.Status {
+			return b.handle(func(item json.RawMessage) (*status.Status, interface{}) {
+				if r.Method == http.MethodPost {
+					var pub publication.Publication
+					if err := json.Unmarshal(item, &pub); err != nil {
+						return status.New(status.UnprocessableEntity, err.Error()), nil
+					}
+					s, posted := p.PostPublication(&pub)
+					return s, posted
+				}
+
+				s, id, pt := patchItem(item, publication.Fields)
+				if s.Err() != nil {
+					return s, nil
+				}
+				s, updated := patchPublication(p, r, id, pt)
+				return s, updated
+			})
+		}
+		if b.atomic {
+			s = p.Transaction(handle)
+		} else {
+			s = handle(p)
+		}
+		b.write(w, s)
+	})
+}
+
+// patchPublication patches the publication matching the given id with pt, provided that it still matches the
+// request's If-Match header. The publication is locked while it is read, checked and updated, so that no one else
+// can change it in between.
+func patchPublication(p *publication.Service, r *http.Request, id int, pt patch.Patch) (*status.Status, *publication.Publication) {
+	var updated *publication.Publication
+	s := p.Locked(id, func(p *publication.Service) *status.Status {
+		s, current := p.GetPublication(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		if s.Err() != nil || len(pt) == 0 {
+			updated = current
+			return s
+		}
+		if err := pt.Apply(current, publication.Fields); err != nil {
+			return status.New(status.UnprocessableEntity, err.Error())
+		}
+		s, updated = p.PatchPublication(current, pt.Names()...)
+		return s
+	})
+	return s, updated
+}
+
+// deletePublication removes the publication matching the given id, provided that it still matches the request's
+// If-Match header.
+func deletePublication(p *publication.Service, r *http.Request, id int) *status.Status {
+	if r.Header.Get("If-Match") == "" {
+		return p.DeletePublication(id)
+	}
+
+	return p.Locked(id, func(p *publication.Service) *status.Status {
+		s, current := p.GetPublication(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		return p.DeletePublication(id)
+	})
+}
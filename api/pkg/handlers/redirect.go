@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/gorilla/mux"
+)
+
+// followRedirect handles a GET request for the entry of the given entity matching id, which could not be found
+// with status notFound, in case it has been merged into another entry. It returns the id of that entry if the
+// request's follow query parameter is true, so that it is served in place of the one requested. Otherwise, it
+// responds with a 301 Moved Permanently to the URL of that entry, or with notFound if the entry has not been
+// merged, and returns 0.
+func followRedirect(w http.ResponseWriter, r *http.Request, rd *redirect.Service, entity string, id int, notFound *status.Status) int {
+	query := r.URL.Query()
+	follow := false
+	if param := query.Get("follow"); param != "" {
+		var err error
+		if follow, err = strconv.ParseBool(param); err != nil {
+			http.Error(w, "follow: "+strconv.Quote(param)+" is not a boolean", status.BadRequest)
+			return 0
+		}
+	}
+
+	s, newID := rd.GetRedirect(entity, id)
+	if s.Code() == status.NotFound {
+		http.Error(w, notFound.Message(), notFound.Code())
+		return 0
+	} else if s.Err() != nil {
+		http.Error(w, s.Message(), s.Code())
+		return 0
+	}
+
+	location, err := mux.CurrentRoute(r).URL("id", strconv.Itoa(newID))
+	if err != nil {
+		http.Error(w, err.Error(), status.InternalServerError)
+		return 0
+	}
+	query.Del("follow")
+	location.RawQuery = query.Encode()
+	if follow {
+		w.Header().Set("Content-Location", location.String())
+		return newID
+	}
+	http.Redirect(w, r, location.String(), status.MovedPermanently)
+	return 0
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Version is a version of the REST API, served under /api/{Name}. Every version shares the same
+// services; they differ in how responses are encoded.
+type Version struct {
+	Name string
+	// StructuredErrors encodes error responses as JSON objects rather than plain text.
+	StructuredErrors bool
+	// Deprecation and Sunset are when the version was deprecated and when it will stop being served.
+	// Both are zero for current versions.
+	Deprecation time.Time
+	Sunset      time.Time
+	// Successor is the version that clients of a deprecated version should move to.
+	Successor *Version
+}
+
+// Error is the body of error responses of versions with structured errors.
+type Error struct {
+	Status  int    `json:"status"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// Prefix returns the path that the version's routes are served under, e.g. /api/v1.
+func (v *Version) Prefix() string {
+	return "/api/" + v.Name
+}
+
+// Deprecated returns whether the version has been deprecated.
+func (v *Version) Deprecated() bool {
+	return !v.Deprecation.IsZero()
+}
+
+// Middleware adds the Deprecation and Sunset headers to the responses of deprecated versions,
+// with a link to the same route in the successor version, and encodes errors as the version does.
+func (v *Version) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v.Deprecated() {
+			// RFC 9745 and RFC 8594 respectively.
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", v.Deprecation.Unix()))
+			if !v.Sunset.IsZero() {
+				w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if v.Successor != nil {
+				w.Header().Add("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, v.Successor.Prefix(), v.route(r.URL.Path)))
+			}
+		}
+
+		if !v.StructuredErrors {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ew := &errorWriter{ResponseWriter: w}
+		next.ServeHTTP(ew, r)
+		ew.finish()
+	})
+}
+
+// route returns the path of a request without the version's prefix, or the /api prefix that is an alias of v1.
+func (v *Version) route(path string) string {
+	if route := strings.TrimPrefix(path, v.Prefix()); route != path {
+		return route
+	}
+	return strings.TrimPrefix(path, "/api")
+}
+
+// errorWriter holds back the plain text errors written by the handlers, i.e. with http.Error,
+// so that they can be written as JSON objects instead.
+type errorWriter struct {
+	http.ResponseWriter
+	code int
+	buf  bytes.Buffer
+}
+
+func (ew *errorWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest && strings.HasPrefix(ew.Header().Get("Content-Type"), "text/plain") {
+		ew.code = code
+		return
+	}
+	ew.ResponseWriter.WriteHeader(code)
+}
+
+func (ew *errorWriter) Write(b []byte) (int, error) {
+	if ew.code != 0 {
+		return ew.buf.Write(b)
+	}
+	return ew.ResponseWriter.Write(b)
+}
+
+// finish writes the error held back, if any.
+func (ew *errorWriter) finish() {
+	if ew.code == 0 {
+		return
+	}
+	body, err := json.Marshal(Error{
+		Status:  ew.code,
+		Error:   http.StatusText(ew.code),
+		Message: strings.TrimSpace(ew.buf.String()),
+	})
+	if err != nil {
+		body = []byte(fmt.Sprintf(`{"status":%d}`, ew.code))
+	}
+
+	h := ew.ResponseWriter.Header()
+	h.Set("Content-Type", "application/json")
+	h.Del("Content-Length")
+	ew.ResponseWriter.WriteHeader(ew.code)
+	ew.ResponseWriter.Write(body)
+}
@@ -4,15 +4,21 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/andrewzulaybar/books/api/pkg/jsonld"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/andrewzulaybar/books/api/pkg/work"
 	"github.com/gorilla/mux"
 )
 
 // Work handles requests made to /api/work/{id:[0-9]+}
-func Work(ws *work.Service) http.HandlerFunc {
+func Work(ws *work.Service, p *publication.Service, rd *redirect.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws := ws.WithActor(actor(r))
 		vars := mux.Vars(r)
 		id, err := strconv.Atoi(vars["id"])
 		if err != nil {
@@ -22,29 +28,75 @@ func Work(ws *work.Service) http.HandlerFunc {
 
 		switch r.Method {
 		case http.MethodGet:
-			s, work := ws.GetWork(id)
+			w.Header().Add("Vary", "Accept")
+			s, wk := ws.GetWork(id)
+			if s.Code() == status.NotFound {
+				if id = followRedirect(w, r, rd, work.Entity, id, s); id == 0 {
+					return
+				}
+				s, wk = ws.GetWork(id)
+			}
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(*work)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := ws.LastModified([]int{id})
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
-		case http.MethodPatch:
-			var work work.Work
-			work.ID = id
-			if err := json.NewDecoder(r.Body).Decode(&work); err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			if wantsJSONLD(r) {
+				s, pubs := p.FilterPublications(&publication.Filter{WorkID: id})
+				if s.Err() != nil {
+					http.Error(w, s.Message(), s.Code())
+					return
+				}
+				// The document describes the work's publications too, so it changes along with them.
+				if len(pubs) > 0 {
+					ids := make([]int, len(pubs))
+					for i := range pubs {
+						ids[i] = pubs[i].ID
+					}
+					s, pubsModified := p.LastModified(ids)
+					if s.Err() != nil {
+						http.Error(w, s.Message(), s.Code())
+						return
+					}
+					if pubsModified.After(modified) {
+						modified = pubsModified
+					}
+				}
+				writeJSONLD(w, r, jsonld.FromWork(baseURL(r), wk, pubs), modified)
 				return
 			}
+			writeJSON(w, r, *wk, modified)
+		case http.MethodPatch:
+			var p patch.Patch
+			if revert := r.URL.Query().Get("revert"); revert != "" {
+				// Reverting patches the work with its fields as they were after the given revision.
+				revisionID, err := strconv.Atoi(revert)
+				if err != nil {
+					http.Error(w, err.Error(), status.UnprocessableEntity)
+					return
+				}
+				s, revision := ws.GetRevision(id, revisionID)
+				if s.Err() != nil {
+					http.Error(w, s.Message(), s.Code())
+					return
+				}
+				if p, err = patch.From(revision, work.Fields); err != nil {
+					http.Error(w, err.Error(), status.InternalServerError)
+					return
+				}
+			} else {
+				var s *status.Status
+				if s, p = decodePatch(r, work.Fields); s.Err() != nil {
+					http.Error(w, s.Message(), s.Code())
+					return
+				}
+			}
 
-			s, updated := ws.PatchWork(&work)
+			s, updated := patchWork(ws, r, id, p)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -59,7 +111,7 @@ func Work(ws *work.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			s := ws.DeleteWork(id)
+			s := deleteWork(ws, r, id)
 			if s.Err() != nil {
 				http.Error(w, s.Message(), s.Code())
 				return
@@ -69,9 +121,38 @@ func Work(ws *work.Service) http.HandlerFunc {
 	})
 }
 
+// RestoreWork handles requests made to /api/work/{id:[0-9]+}/restore
+func RestoreWork(ws *work.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws := ws.WithActor(actor(r))
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		s, restored := ws.RestoreWork(id)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		bytes, err := json.Marshal(*restored)
+		if err != nil {
+			http.Error(w, err.Error(), status.InternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(s.Code())
+		w.Write(bytes)
+	})
+}
+
 // Works handles requests made to /api/work
 func Works(ws *work.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws := ws.WithActor(actor(r))
 		switch r.Method {
 		case http.MethodGet:
 			s, works := ws.GetWorks()
@@ -79,15 +160,12 @@ func Works(ws *work.Service) http.HandlerFunc {
 				http.Error(w, s.Message(), s.Code())
 				return
 			}
-
-			bytes, err := json.Marshal(works)
-			if err != nil {
-				http.Error(w, err.Error(), status.InternalServerError)
+			s, modified := ws.LastModified(nil)
+			if s.Err() != nil {
+				http.Error(w, s.Message(), s.Code())
 				return
 			}
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(s.Code())
-			w.Write(bytes)
+			writeJSON(w, r, works, modified)
 		case http.MethodPost:
 			var work work.Work
 			if err := json.NewDecoder(r.Body).Decode(&work); err != nil {
@@ -110,10 +188,6 @@ func Works(ws *work.Service) http.HandlerFunc {
 			w.WriteHeader(s.Code())
 			w.Write(bytes)
 		case http.MethodDelete:
-			type identifiers struct {
-				IDs []int `json:"ids"`
-			}
-
 			var toDelete identifiers
 			if err := json.NewDecoder(r.Body).Decode(&toDelete); err != nil {
 				http.Error(w, err.Error(), status.UnprocessableEntity)
@@ -140,3 +214,109 @@ func Works(ws *work.Service) http.HandlerFunc {
 		}
 	})
 }
+
+// WorkBatch handles requests made to /api/work/batch
+func WorkBatch(ws *work.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws := ws.WithActor(actor(r))
+		s, b := decodeBatch(r)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		handle := func(ws *work.Service) *status.Status {
+			return b.handle(func(item json.RawMessage) (*status.Status, interface{}) {
+				if r.Method == http.MethodPost {
+					var wk work.Work
+					if err := json.Unmarshal(item, &wk); err != nil {
+						return status.New(status.UnprocessableEntity, err.Error()), nil
+					}
+					s, posted := ws.PostWork(&wk)
+					return s, posted
+				}
+
+				s, id, p := patchItem(item, work.Fields)
+				if s.Err() != nil {
+					return s, nil
+				}
+				s, updated := patchWork(ws, r, id, p)
+				return s, updated
+			})
+		}
+		if b.atomic {
+			s = ws.Transaction(handle)
+		} else {
+			s = handle(ws)
+		}
+		b.write(w, s)
+	})
+}
+
+// WorkPublications handles requests made to /api/work/{id:[0-9]+}/publications
+func WorkPublications(p *publication.Service) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, err.Error(), status.UnprocessableEntity)
+			return
+		}
+
+		query := r.URL.Query()
+		f := &publication.Filter{WorkID: id, Format: query.Get("format"), Language: query.Get("language"), ByDate: true}
+		if newest := query.Get("newest"); newest != "" {
+			if f.Newest, err = strconv.ParseBool(newest); err != nil {
+				http.Error(w, "newest: "+strconv.Quote(newest)+" is not a boolean", status.BadRequest)
+				return
+			}
+		}
+
+		s, editions := p.GetEditions(f)
+		if s.Err() != nil {
+			http.Error(w, s.Message(), s.Code())
+			return
+		}
+
+		// Editions are compared with publications that may not be listed, so responses only carry an ETag.
+		writeJSON(w, r, editions, time.Time{})
+	})
+}
+
+// patchWork patches the work matching the given id with p, provided that it still matches the request's If-Match
+// header. The work is locked while it is read, checked and updated, so that two editors cannot overwrite each
+// other's changes.
+func patchWork(ws *work.Service, r *http.Request, id int, p patch.Patch) (*status.Status, *work.Work) {
+	var updated *work.Work
+	s := ws.Locked(id, func(ws *work.Service) *status.Status {
+		s, current := ws.GetWork(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		if s.Err() != nil || len(p) == 0 {
+			updated = current
+			return s
+		}
+		if err := p.Apply(current, work.Fields); err != nil {
+			return status.New(status.UnprocessableEntity, err.Error())
+		}
+		s, updated = ws.PatchWork(current, p.Names()...)
+		return s
+	})
+	return s, updated
+}
+
+// deleteWork removes the work matching the given id, provided that it still matches the request's If-Match header.
+func deleteWork(ws *work.Service, r *http.Request, id int) *status.Status {
+	if r.Header.Get("If-Match") == "" {
+		return ws.DeleteWork(id)
+	}
+
+	return ws.Locked(id, func(ws *work.Service) *status.Status {
+		s, current := ws.GetWork(id)
+		if s := checkIfMatch(r, s, current); s.Err() != nil {
+			return s
+		}
+		return ws.DeleteWork(id)
+	})
+}
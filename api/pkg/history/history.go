@@ -0,0 +1,263 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/lib/pq"
+)
+
+// Columns is the comma-separated list of columns found in the revision table.
+const Columns string = "entity, entity_id, action, actor, created_at, before, after"
+
+// Actions recorded in the history.
+const (
+	Post    = "post"
+	Patch   = "patch"
+	Delete  = "delete"
+	Restore = "restore"
+)
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	GetRevision
+	GetRevisions
+	PostRevision
+	PostRevisions
+)
+
+// A Revision represents a single change to an entry of the catalogue: who made it, when, and how the entry's
+// fields changed.
+type Revision struct {
+	ID        int               `json:"id"`
+	Entity    string            `json:"entity"`
+	EntityID  int               `json:"entityId"`
+	Action    string            `json:"action"`
+	Actor     string            `json:"actor"`
+	Timestamp time.Time         `json:"timestamp"`
+	Diff      map[string]Change `json:"diff"`
+
+	// Before and After are the entry as it was before and after the change, or null where it did not exist.
+	// Entries embedded in it, such as a work's author, are reduced to their id.
+	Before json.RawMessage `json:"-"`
+	After  json.RawMessage `json:"-"`
+}
+
+// Revisions represents a list of revisions.
+type Revisions []Revision
+
+// A Change represents the value of a field before and after a revision.
+type Change struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case GetRevision:
+		return fmt.Sprintf("SELECT id, %s FROM revision WHERE id = $1", Columns)
+	case GetRevisions:
+		return fmt.Sprintf(
+			`SELECT id, %s
+                        FROM revision
+                        WHERE entity = $1 AND entity_id = $2
+                        ORDER BY id`,
+			Columns,
+		)
+	case PostRevision:
+		return fmt.Sprintf("INSERT INTO revision (%s) VALUES ($1, $2, $3, $4, now(), $5, $6)", Columns)
+	case PostRevisions:
+		// A deleted entry was as its latest revision left it, and a restored one as it was before it was deleted.
+		snapshots := "latest.after, NULL"
+		if args[0].(string) == Restore {
+			snapshots = "NULL, latest.before"
+		}
+		return fmt.Sprintf(
+			`INSERT INTO revision (%s)
+                        SELECT $1, ids.id, $3, $4, now(), %s
+                        FROM unnest($2::integer[]) AS ids(id)
+                        LEFT JOIN LATERAL (
+                            SELECT before, after
+                            FROM revision
+                            WHERE entity = $1 AND entity_id = ids.id
+                            ORDER BY id DESC
+                            LIMIT 1
+                        ) latest ON true`,
+			Columns, snapshots,
+		)
+	default:
+		return ""
+	}
+}
+
+// GetRevision retrieves the revision from the database matching the given id.
+func (s *Service) GetRevision(id int) (*status.Status, *Revision) {
+	db := s.DB
+	getRevision := s.Query(GetRevision)
+
+	row := db.QueryRow(getRevision, id)
+	revision, err := s.getRevision(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Revision with id = %d does not exist", id)
+			log.Printf("[GetRevision] %s", msg)
+			return status.Newf(status.NotFound, msg), nil
+		}
+		log.Printf("[GetRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), revision
+}
+
+// GetRevisions retrieves the revisions of the entry of the given entity matching the given id, oldest first.
+func (s *Service) GetRevisions(entity string, id int) (*status.Status, Revisions) {
+	db := s.DB
+	getRevisions := s.Query(GetRevisions)
+
+	rows, err := db.Query(getRevisions, entity, id)
+	if err != nil {
+		log.Printf("[GetRevisions] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	revisions := Revisions{}
+	for rows.Next() {
+		revision, err := s.getRevision(rows)
+		if err != nil {
+			log.Printf("[GetRevisions] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		revisions = append(revisions, *revision)
+	}
+	return status.New(status.OK, ""), revisions
+}
+
+// PostRevision records a change made to the entry of the given entity matching the given id, by the actor of the
+// receiver's database. before and after are the entry as it was before and after the change, or nil where it did
+// not exist.
+func (s *Service) PostRevision(entity string, id int, action string, before interface{}, after interface{}) *status.Status {
+	db := s.DB
+	postRevision := s.Query(PostRevision)
+
+	b, err := snapshot(before)
+	if err != nil {
+		log.Printf("[PostRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	a, err := snapshot(after)
+	if err != nil {
+		log.Printf("[PostRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	if _, err := db.Exec(postRevision, entity, id, action, db.Actor(), b, a); err != nil {
+		log.Printf("[PostRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.Created, "")
+}
+
+// PostRevisions records the deletion or restoration of the entries of the given entity matching the given ids, by
+// the actor of the receiver's database, which were deleted or restored along with another entry, such as the
+// publications of a deleted work. Their snapshots are taken from their latest revisions.
+func (s *Service) PostRevisions(entity string, ids []int, action string) *status.Status {
+	if len(ids) == 0 {
+		return status.New(status.Created, "")
+	}
+
+	db := s.DB
+	postRevisions := s.Query(PostRevisions, action)
+
+	if _, err := db.Exec(postRevisions, entity, pq.Array(ids), action, db.Actor()); err != nil {
+		log.Printf("[PostRevisions] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.Created, "")
+}
+
+func (s *Service) getRevision(row interface {
+	Scan(dest ...interface{}) error
+}) (*Revision, error) {
+	var r Revision
+	var before, after []byte
+	if err := row.Scan(
+		&r.ID, &r.Entity, &r.EntityID, &r.Action, &r.Actor, &r.Timestamp, &before, &after,
+	); err != nil {
+		return nil, err
+	}
+	if before != nil {
+		r.Before = json.RawMessage(before)
+	}
+	if after != nil {
+		r.After = json.RawMessage(after)
+	}
+
+	diff, err := diff(r.Before, r.After)
+	if err != nil {
+		return nil, err
+	}
+	r.Diff = diff
+	return &r, nil
+}
+
+// snapshot returns the JSON representation of v to be stored in a revision, or nil if v is nil. Embedded entries are
+// reduced to their id, since they have revisions of their own.
+func snapshot(v interface{}) ([]byte, error) {
+	bytes, err := json.Marshal(v)
+	if err != nil || string(bytes) == "null" {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(bytes, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range fields {
+		if embedded, ok := value.(map[string]interface{}); ok {
+			if id, ok := embedded["id"]; ok {
+				fields[name] = map[string]interface{}{"id": id}
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// diff returns the fields, other than the id, whose values differ between the snapshots before and after.
+func diff(before json.RawMessage, after json.RawMessage) (map[string]Change, error) {
+	var b, a map[string]interface{}
+	if before != nil {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]Change{}
+	for _, fields := range []map[string]interface{}{b, a} {
+		for name := range fields {
+			if name == "id" || reflect.DeepEqual(b[name], a[name]) {
+				continue
+			}
+			changes[name] = Change{Before: b[name], After: a[name]}
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,162 @@
+package idempotency
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// Columns is the comma-separated list of columns found in the idempotency table.
+const Columns string = "actor, key, fingerprint, status, content_type, etag, location, body, created_at"
+
+// TTL is how long the response to a request made with an idempotency key is kept, and replayed to its retries.
+const TTL = 24 * time.Hour
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	DeleteKey
+	GetResponse
+	PatchResponse
+	PostKey
+	PurgeKeys
+)
+
+// A Response is the response to the first request made with an idempotency key.
+type Response struct {
+	// Fingerprint identifies the request, so that a key cannot be reused for a different one.
+	Fingerprint string
+
+	// Status is zero while the request is still being handled.
+	Status      int
+	ContentType string
+	ETag        string
+	Location    string
+	Body        []byte
+}
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case DeleteKey:
+		return "DELETE FROM idempotency WHERE actor = $1 AND key = $2"
+	case GetResponse:
+		return `SELECT fingerprint, status, content_type, etag, location, body
+                        FROM idempotency
+                        WHERE actor = $1 AND key = $2`
+	case PatchResponse:
+		return `UPDATE idempotency SET status = $3, content_type = $4, etag = $5, location = $6, body = $7
+                        WHERE actor = $1 AND key = $2`
+	case PostKey:
+		// A key whose response has expired is taken over as if it had never been used.
+		return fmt.Sprintf(
+			`INSERT INTO idempotency (%s)
+                        VALUES ($1, $2, $3, 0, '', '', '', '', now())
+                        ON CONFLICT (actor, key) DO UPDATE
+                        SET fingerprint = EXCLUDED.fingerprint, status = 0, content_type = '', etag = '', location = '',
+                            body = '', created_at = now()
+                        WHERE idempotency.created_at < $4`,
+			Columns,
+		)
+	case PurgeKeys:
+		return "DELETE FROM idempotency WHERE created_at < $1"
+	default:
+		return ""
+	}
+}
+
+// DeleteKey releases the key of the given actor, so that the request made with it can be retried.
+func (s *Service) DeleteKey(actor string, key string) *status.Status {
+	db := s.DB
+	deleteKey := s.Query(DeleteKey)
+
+	if _, err := db.Exec(deleteKey, actor, key); err != nil {
+		log.Printf("[DeleteKey] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.NoContent, "")
+}
+
+// GetResponse retrieves the response to the request made with the key of the given actor.
+func (s *Service) GetResponse(actor string, key string) (*status.Status, *Response) {
+	db := s.DB
+	getResponse := s.Query(GetResponse)
+
+	var res Response
+	row := db.QueryRow(getResponse, actor, key)
+	if err := row.Scan(&res.Fingerprint, &res.Status, &res.ContentType, &res.ETag, &res.Location, &res.Body); err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Idempotency key %q does not exist", key)
+			log.Printf("[GetResponse] %s", msg)
+			return status.Newf(status.NotFound, msg), nil
+		}
+		log.Printf("[GetResponse] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), &res
+}
+
+// PatchResponse stores the response to the request made with the key of the given actor.
+func (s *Service) PatchResponse(actor string, key string, res *Response) *status.Status {
+	db := s.DB
+	patchResponse := s.Query(PatchResponse)
+
+	_, err := db.Exec(patchResponse, actor, key, res.Status, res.ContentType, res.ETag, res.Location, res.Body)
+	if err != nil {
+		log.Printf("[PatchResponse] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.OK, "")
+}
+
+// PostKey reserves the key of the given actor for the request with the given fingerprint, unless the key has
+// already been used within the TTL, in which case a conflict is returned.
+func (s *Service) PostKey(actor string, key string, fingerprint string) *status.Status {
+	db := s.DB
+	postKey := s.Query(PostKey)
+
+	res, err := db.Exec(postKey, actor, key, fingerprint, time.Now().Add(-TTL))
+	if err != nil {
+		log.Printf("[PostKey] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	numPosted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[PostKey] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if numPosted == 0 {
+		msg := fmt.Sprintf("Idempotency key %q has already been used", key)
+		return status.Newf(status.Conflict, msg)
+	}
+	return status.New(status.Created, "")
+}
+
+// PurgeKeys removes the keys used before the given time, and returns how many were removed.
+func (s *Service) PurgeKeys(before time.Time) (*status.Status, int) {
+	db := s.DB
+	purgeKeys := s.Query(PurgeKeys)
+
+	res, err := db.Exec(purgeKeys, before)
+	if err != nil {
+		log.Printf("[PurgeKeys] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+
+	numPurged, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[PurgeKeys] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+	return status.New(status.OK, ""), int(numPurged)
+}
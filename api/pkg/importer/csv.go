@@ -0,0 +1,201 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// Fields that a CSV column can be mapped to.
+const (
+	Title              = "title"
+	Description        = "description"
+	InitialPubDate     = "initialPubDate"
+	OriginalLanguage   = "originalLanguage"
+	EditionPubDate     = "editionPubDate"
+	Format             = "format"
+	ImageURL           = "imageUrl"
+	ISBN               = "isbn"
+	ISBN13             = "isbn13"
+	Language           = "language"
+	NumPages           = "numPages"
+	Publisher          = "publisher"
+	Edition            = "edition"
+	Author             = "author"
+	AuthorFirstName    = "authorFirstName"
+	AuthorLastName     = "authorLastName"
+	AuthorGender       = "authorGender"
+	AuthorDateOfBirth  = "authorDateOfBirth"
+	AuthorBirthCity    = "authorBirthCity"
+	AuthorBirthCountry = "authorBirthCountry"
+	AuthorBirthRegion  = "authorBirthRegion"
+)
+
+// fields lists every field a column can be mapped to.
+var fields = []string{
+	Title, Description, InitialPubDate, OriginalLanguage, EditionPubDate, Format, ImageURL,
+	ISBN, ISBN13, Language, NumPages, Publisher, Edition, Author, AuthorFirstName, AuthorLastName,
+	AuthorGender, AuthorDateOfBirth, AuthorBirthCity, AuthorBirthCountry, AuthorBirthRegion,
+}
+
+// Mapping maps CSV header names to fields. Headers that are not in the mapping are matched
+// against the field names themselves, ignoring case, spaces and punctuation, so that
+// "Author First Name", "author_first_name" and "authorFirstName" all map to AuthorFirstName.
+type Mapping map[string]string
+
+// ParseMapping parses a list of "header:field" pairs into a Mapping.
+func ParseMapping(pairs []string) (Mapping, error) {
+	m := Mapping{}
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("mapping %q is not of the form header:field", pair)
+		}
+		header, field := pair[:i], pair[i+1:]
+		if !isField(field) {
+			return nil, fmt.Errorf("mapping %q refers to unknown field %q", pair, field)
+		}
+		m[header] = field
+	}
+	return m, nil
+}
+
+// ParseCSV reads the header row and records in r and converts each record into a Row.
+// Records that cannot be converted are returned with Err set rather than aborting the parse.
+func ParseCSV(r io.Reader, m Mapping) (Rows, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = m.field(name)
+	}
+
+	rows := Rows{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err, ok := err.(*csv.ParseError); ok {
+				rows = append(rows, Row{Line: err.StartLine, Err: err})
+				continue
+			}
+			return nil, err
+		}
+		// Quoted fields may span several lines, so the record's line is asked of the reader rather than counted.
+		line, _ := reader.FieldPos(0)
+
+		values := map[string]string{}
+		for i, value := range record {
+			if i < len(columns) && columns[i] != "" {
+				values[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		pub, err := toPublication(values)
+		rows = append(rows, Row{Line: line, Publication: pub, Err: err})
+	}
+	return rows, nil
+}
+
+func (m Mapping) field(header string) string {
+	if field, ok := m[header]; ok {
+		return field
+	}
+	for _, field := range fields {
+		if normalize(field) == normalize(header) {
+			return field
+		}
+	}
+	return ""
+}
+
+func toPublication(values map[string]string) (publication.Publication, error) {
+	var pub publication.Publication
+	wk := &pub.Work
+	au := &wk.Author
+	pob := &au.PlaceOfBirth
+
+	pub.EditionPubDate = values[EditionPubDate]
+	pub.Format = values[Format]
+	pub.ImageURL = values[ImageURL]
+	pub.ISBN = values[ISBN]
+	pub.ISBN13 = values[ISBN13]
+	pub.Language = values[Language]
+	pub.Publisher = values[Publisher]
+	pub.Edition = values[Edition]
+	if n := values[NumPages]; n != "" {
+		numPages, err := strconv.Atoi(n)
+		if err != nil {
+			return pub, fmt.Errorf("%s: %q is not a number", NumPages, n)
+		}
+		pub.NumPages = numPages
+	}
+
+	wk.Title = values[Title]
+	wk.Description = values[Description]
+	wk.InitialPubDate = values[InitialPubDate]
+	wk.OriginalLanguage = values[OriginalLanguage]
+	if wk.OriginalLanguage == "" {
+		wk.OriginalLanguage = pub.Language
+	}
+
+	au.FirstName, au.LastName = SplitName(values[Author])
+	if v := values[AuthorFirstName]; v != "" {
+		au.FirstName = v
+	}
+	if v := values[AuthorLastName]; v != "" {
+		au.LastName = v
+	}
+	au.Gender = values[AuthorGender]
+	au.DateOfBirth = values[AuthorDateOfBirth]
+
+	pob.City = values[AuthorBirthCity]
+	pob.Country = values[AuthorBirthCountry]
+	pob.Region = values[AuthorBirthRegion]
+	return pub, nil
+}
+
+// SplitName splits a full name into a first and last name on its last space,
+// handling "Last, First" order as well.
+func SplitName(name string) (string, string) {
+	name = strings.TrimSpace(name)
+	if i := strings.Index(name, ","); i >= 0 {
+		return strings.TrimSpace(name[i+1:]), strings.TrimSpace(name[:i])
+	}
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return strings.TrimSpace(name[:i]), name[i+1:]
+	}
+	return "", name
+}
+
+func isField(field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+)
+
+func TestParseCSV(t *testing.T) {
+	t.Run("DefaultHeaders", func(t *testing.T) {
+		in := "Title,Author,ISBN 13,Edition Pub Date,Num Pages,Publisher\n" +
+			"Normal People,Sally Rooney,9781984822178,2019-04-16,288,Hogarth\n"
+		rows, err := importer.ParseCSV(strings.NewReader(in), nil)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 1, len(rows))
+
+		row := rows[0]
+		helpers.AssertEqual(t, 2, row.Line)
+		helpers.AssertEqual(t, nil, row.Err)
+		helpers.AssertEqual(t, "Normal People", row.Publication.Work.Title)
+		helpers.AssertEqual(t, "Sally", row.Publication.Work.Author.FirstName)
+		helpers.AssertEqual(t, "Rooney", row.Publication.Work.Author.LastName)
+		helpers.AssertEqual(t, "9781984822178", row.Publication.ISBN13)
+		helpers.AssertEqual(t, "2019-04-16", row.Publication.EditionPubDate)
+		helpers.AssertEqual(t, 288, row.Publication.NumPages)
+		helpers.AssertEqual(t, "Hogarth", row.Publication.Publisher)
+	})
+
+	t.Run("CustomMapping", func(t *testing.T) {
+		mapping, err := importer.ParseMapping([]string{"Name:title", "Surname:authorLastName"})
+		helpers.AssertEqual(t, nil, err)
+
+		in := "Name,Surname\nThe Great Gatsby,Fitzgerald\n"
+		rows, err := importer.ParseCSV(strings.NewReader(in), mapping)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, "The Great Gatsby", rows[0].Publication.Work.Title)
+		helpers.AssertEqual(t, "Fitzgerald", rows[0].Publication.Work.Author.LastName)
+	})
+
+	t.Run("InvalidNumPages", func(t *testing.T) {
+		in := "title,numPages\nA,many\nB,12\n"
+		rows, err := importer.ParseCSV(strings.NewReader(in), nil)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 2, len(rows))
+		helpers.AssertEqual(t, `numPages: "many" is not a number`, rows[0].Err.Error())
+		helpers.AssertEqual(t, nil, rows[1].Err)
+	})
+
+	t.Run("MultilineField", func(t *testing.T) {
+		in := "title,description\nA,\"First line\nsecond line\"\n\nB,\"x\"\n"
+		rows, err := importer.ParseCSV(strings.NewReader(in), nil)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 2, len(rows))
+		helpers.AssertEqual(t, 2, rows[0].Line)
+		helpers.AssertEqual(t, 5, rows[1].Line)
+	})
+
+	t.Run("EmptyInput", func(t *testing.T) {
+		_, err := importer.ParseCSV(strings.NewReader(""), nil)
+		helpers.AssertEqual(t, "reading header: EOF", err.Error())
+	})
+}
+
+func TestParseMapping(t *testing.T) {
+	t.Run("UnknownField", func(t *testing.T) {
+		_, err := importer.ParseMapping([]string{"Name:name"})
+		helpers.AssertEqual(t, `mapping "Name:name" refers to unknown field "name"`, err.Error())
+	})
+
+	t.Run("MissingSeparator", func(t *testing.T) {
+		_, err := importer.ParseMapping([]string{"Name"})
+		helpers.AssertEqual(t, `mapping "Name" is not of the form header:field`, err.Error())
+	})
+}
+
+func TestSplitName(t *testing.T) {
+	t.Run("FirstLast", func(t *testing.T) {
+		first, last := importer.SplitName("F. Scott Fitzgerald")
+		helpers.AssertEqual(t, "F. Scott", first)
+		helpers.AssertEqual(t, "Fitzgerald", last)
+	})
+
+	t.Run("LastCommaFirst", func(t *testing.T) {
+		first, last := importer.SplitName("Rooney, Sally")
+		helpers.AssertEqual(t, "Sally", first)
+		helpers.AssertEqual(t, "Rooney", last)
+	})
+
+	t.Run("SingleName", func(t *testing.T) {
+		first, last := importer.SplitName("Homer")
+		helpers.AssertEqual(t, "", first)
+		helpers.AssertEqual(t, "Homer", last)
+	})
+}
@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+)
+
+// Columns of goodreads_library_export.csv used by ParseGoodreads.
+const (
+	grBookID                  = "Book Id"
+	grTitle                   = "Title"
+	grAuthor                  = "Author"
+	grAuthorLF                = "Author l-f"
+	grISBN                    = "ISBN"
+	grISBN13                  = "ISBN13"
+	grMyRating                = "My Rating"
+	grPublisher               = "Publisher"
+	grBinding                 = "Binding"
+	grNumberOfPages           = "Number of Pages"
+	grYearPublished           = "Year Published"
+	grOriginalPublicationYear = "Original Publication Year"
+	grDateRead                = "Date Read"
+	grBookshelves             = "Bookshelves"
+	grExclusiveShelf          = "Exclusive Shelf"
+)
+
+// seriesSuffix matches the series Goodreads appends to titles, e.g. "Catching Fire (The Hunger Games, #2)".
+var seriesSuffix = regexp.MustCompile(`\s*\([^()]*#[^()]*\)$`)
+
+// ParseGoodreads reads a Goodreads library export and converts each book into a Row that puts
+// the book on the shelves of the user matching userID.
+func ParseGoodreads(r io.Reader, userID int) (Rows, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, name := range []string{grBookID, grTitle, grAuthor, grISBN, grISBN13, grExclusiveShelf} {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("not a Goodreads export: missing column %q", name)
+		}
+	}
+
+	rows := Rows{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if err, ok := err.(*csv.ParseError); ok {
+				rows = append(rows, Row{Line: err.StartLine, Err: err})
+				continue
+			}
+			return nil, err
+		}
+		// Reviews and notes may span several lines.
+		line, _ := reader.FieldPos(0)
+
+		get := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return unquoteFormula(strings.TrimSpace(record[i]))
+			}
+			return ""
+		}
+		pub, entry, err := fromGoodreads(get)
+		if err != nil {
+			err = fmt.Errorf("book %s: %w", get(grBookID), err)
+		}
+		entry.UserID = userID
+		row := Row{Line: line, Publication: pub, Shelf: entry, Err: err}
+		// Ebooks and audiobooks usually have no ISBN, and so cannot be told apart from other publications.
+		if pub.ISBN == "" && pub.ISBN13 == "" {
+			row.Skip = fmt.Sprintf("book %s has no ISBN", get(grBookID))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func fromGoodreads(get func(string) string) (publication.Publication, *shelf.Entry, error) {
+	var pub publication.Publication
+	wk := &pub.Work
+	au := &wk.Author
+	entry := &shelf.Entry{ExclusiveShelf: get(grExclusiveShelf), Bookshelves: []string{}}
+
+	wk.Title = seriesSuffix.ReplaceAllString(get(grTitle), "")
+	au.FirstName, au.LastName = SplitName(get(grAuthor))
+	if lf := get(grAuthorLF); lf != "" {
+		au.FirstName, au.LastName = SplitName(lf)
+	}
+
+	pub.ISBN = get(grISBN)
+	pub.ISBN13 = get(grISBN13)
+	pub.Publisher = get(grPublisher)
+	pub.Format = get(grBinding)
+	if year := get(grYearPublished); year != "" {
+		pub.EditionPubDate = year + "-01-01"
+	}
+	if year := get(grOriginalPublicationYear); year != "" {
+		wk.InitialPubDate = year + "-01-01"
+	}
+	if pub.EditionPubDate == "" {
+		pub.EditionPubDate = wk.InitialPubDate
+	}
+	if n := get(grNumberOfPages); n != "" {
+		numPages, err := strconv.Atoi(n)
+		if err != nil {
+			return pub, entry, fmt.Errorf("%s: %q is not a number", grNumberOfPages, n)
+		}
+		pub.NumPages = numPages
+	}
+
+	if r := get(grMyRating); r != "" {
+		rating, err := strconv.Atoi(r)
+		if err != nil || rating < 0 || rating > 5 {
+			return pub, entry, fmt.Errorf("%s: %q is not a rating from 0 to 5", grMyRating, r)
+		}
+		entry.Rating = rating
+	}
+	entry.DateRead = strings.ReplaceAll(get(grDateRead), "/", "-")
+	for _, name := range strings.Split(get(grBookshelves), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && name != entry.ExclusiveShelf {
+			entry.Bookshelves = append(entry.Bookshelves, name)
+		}
+	}
+
+	switch entry.ExclusiveShelf {
+	case shelf.Read, shelf.CurrentlyReading, shelf.ToRead:
+	default:
+		return pub, entry, fmt.Errorf("%s: unknown shelf %q", grExclusiveShelf, entry.ExclusiveShelf)
+	}
+	return pub, entry, nil
+}
+
+// unquoteFormula strips the spreadsheet formula quoting Goodreads puts around ISBNs, e.g. ="0743273567".
+func unquoteFormula(s string) string {
+	if strings.HasPrefix(s, `="`) && strings.HasSuffix(s, `"`) && len(s) >= 3 {
+		return s[2 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+)
+
+const goodreadsHeader = "Book Id,Title,Author,Author l-f,Additional Authors,ISBN,ISBN13,My Rating," +
+	"Average Rating,Publisher,Binding,Number of Pages,Year Published,Original Publication Year," +
+	"Date Read,Date Added,Bookshelves,Bookshelves with positions,Exclusive Shelf,My Review\n"
+
+func TestParseGoodreads(t *testing.T) {
+	t.Run("ReadBook", func(t *testing.T) {
+		in := goodreadsHeader +
+			`4671,The Great Gatsby,F. Scott Fitzgerald,"Fitzgerald, F. Scott",,"=""0743273567""",` +
+			`"=""9780743273565""",5,3.93,Scribner,Paperback,180,2004,1925,2019/05/04,2019/04/01,` +
+			`"classics, favorites","classics (#1), favorites (#2)",read,` + "\n"
+		rows, err := importer.ParseGoodreads(strings.NewReader(in), 1)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 1, len(rows))
+
+		row := rows[0]
+		pub := row.Publication
+		helpers.AssertEqual(t, nil, row.Err)
+		helpers.AssertEqual(t, "The Great Gatsby", pub.Work.Title)
+		helpers.AssertEqual(t, "F. Scott", pub.Work.Author.FirstName)
+		helpers.AssertEqual(t, "Fitzgerald", pub.Work.Author.LastName)
+		helpers.AssertEqual(t, "0743273567", pub.ISBN)
+		helpers.AssertEqual(t, "9780743273565", pub.ISBN13)
+		helpers.AssertEqual(t, "Paperback", pub.Format)
+		helpers.AssertEqual(t, 180, pub.NumPages)
+		helpers.AssertEqual(t, "2004-01-01", pub.EditionPubDate)
+		helpers.AssertEqual(t, "1925-01-01", pub.Work.InitialPubDate)
+
+		want := &shelf.Entry{
+			UserID:         1,
+			ExclusiveShelf: shelf.Read,
+			Rating:         5,
+			DateRead:       "2019-05-04",
+			Bookshelves:    []string{"classics", "favorites"},
+		}
+		helpers.AssertEqual(t, want, row.Shelf)
+	})
+
+	t.Run("SeriesTitleAndMissingISBN", func(t *testing.T) {
+		in := goodreadsHeader +
+			`6148028,"Catching Fire (The Hunger Games, #2)",Suzanne Collins,"Collins, Suzanne",,"=""""",` +
+			`"=""""",0,4.30,Scholastic,Hardcover,391,2009,2009,,2020/01/01,to-read,to-read (#3),to-read,` + "\n"
+		rows, err := importer.ParseGoodreads(strings.NewReader(in), 1)
+		helpers.AssertEqual(t, nil, err)
+
+		row := rows[0]
+		helpers.AssertEqual(t, nil, row.Err)
+		helpers.AssertEqual(t, "Catching Fire", row.Publication.Work.Title)
+		helpers.AssertEqual(t, "", row.Publication.ISBN13)
+		helpers.AssertEqual(t, "book 6148028 has no ISBN", row.Skip)
+		helpers.AssertEqual(t, shelf.ToRead, row.Shelf.ExclusiveShelf)
+		helpers.AssertEqual(t, []string{}, row.Shelf.Bookshelves)
+	})
+
+	t.Run("KindleEdition", func(t *testing.T) {
+		in := goodreadsHeader +
+			`4671,The Great Gatsby,F. Scott Fitzgerald,"Fitzgerald, F. Scott",,"=""0743273567""",` +
+			`"=""9780743273565""",5,3.93,Scribner,Paperback,180,2004,1925,,2019/04/01,,,read,` + "\n" +
+			`18490,Frankenstein,Mary Shelley,"Shelley, Mary",,"=""""","=""""",4,3.85,Penguin,Kindle Edition,` +
+			`260,2013,1818,,2019/04/02,,,read,` + "\n"
+		rows, err := importer.ParseGoodreads(strings.NewReader(in), 1)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 2, len(rows))
+		helpers.AssertEqual(t, "", rows[0].Skip)
+		helpers.AssertEqual(t, nil, rows[1].Err)
+		helpers.AssertEqual(t, "book 18490 has no ISBN", rows[1].Skip)
+	})
+
+	t.Run("UnknownShelf", func(t *testing.T) {
+		in := goodreadsHeader + `1,A,B C,"C, B",,,,0,0,,,,,,,,,,abandoned,` + "\n"
+		rows, err := importer.ParseGoodreads(strings.NewReader(in), 1)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, `book 1: Exclusive Shelf: unknown shelf "abandoned"`, rows[0].Err.Error())
+	})
+
+	t.Run("NotAGoodreadsExport", func(t *testing.T) {
+		_, err := importer.ParseGoodreads(strings.NewReader("title,isbn\n"), 1)
+		helpers.AssertEqual(t, `not a Goodreads export: missing column "Book Id"`, err.Error())
+	})
+}
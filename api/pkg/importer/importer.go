@@ -0,0 +1,418 @@
+package importer
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/isbn"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// Outcomes of importing a single row.
+const (
+	Created = "created"
+	Matched = "matched"
+	Updated = "updated"
+	Deleted = "deleted"
+	Skipped = "skipped"
+	Failed  = "failed"
+)
+
+// Action is what a row asks to be done with its publication.
+type Action int
+
+// Actions a row can ask for. The zero value, Create, creates the publication unless its ISBN
+// is already in the catalogue. Update overwrites the fields given in the row, creating the
+// publication if it does not exist, and Delete removes it.
+const (
+	Create Action = iota
+	Update
+	Delete
+)
+
+// DefaultDateOfBirth is the date of birth given to authors whose date of birth is unknown.
+// It matches the default applied by (author.Service).PostAuthor.
+const DefaultDateOfBirth string = "1970-01-01T00:00:00Z"
+
+// MaxDescription is the length of the work.description column.
+const MaxDescription int = 5000
+
+// UnknownLocation is the place of birth given to new authors whose place of birth is unknown.
+var UnknownLocation = location.Location{City: "Unknown", Country: "Unknown", Region: "Unknown"}
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// Row is a single record to be imported, along with where it came from in the source.
+// If Shelf is set, the imported publication is also put on the shelves of Shelf.UserID,
+// and any Genres are added to the publication's work. If Skip is set, the row is reported
+// as skipped for that reason instead of being imported, without failing the import.
+type Row struct {
+	Source      string
+	Line        int
+	Action      Action
+	Publication publication.Publication
+	Genres      genre.Genres
+	Shelf       *shelf.Entry
+	Skip        string
+	Err         error
+}
+
+// Rows represents a list of rows.
+type Rows []Row
+
+// Result describes what happened to a single row during an import.
+type Result struct {
+	Source      string                   `json:"source,omitempty"`
+	Line        int                      `json:"line"`
+	Status      string                   `json:"status"`
+	Author      string                   `json:"author,omitempty"`
+	Work        string                   `json:"work,omitempty"`
+	Message     string                   `json:"message,omitempty"`
+	Publication *publication.Publication `json:"publication,omitempty"`
+	Shelf       *shelf.Entry             `json:"shelf,omitempty"`
+}
+
+// Report summarizes an import.
+type Report struct {
+	DryRun  bool     `json:"dryRun"`
+	Created int      `json:"created"`
+	Matched int      `json:"matched"`
+	Updated int      `json:"updated"`
+	Deleted int      `json:"deleted"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Results []Result `json:"results"`
+}
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+
+	PublicationService publication.Service
+	GenreService       genre.Service
+	ShelfService       shelf.Service
+}
+
+// WithActor returns a copy of the receiver whose changes are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{
+		DB:                 s.DB.WithActor(actor),
+		PublicationService: *s.PublicationService.WithActor(actor),
+		GenreService:       s.GenreService,
+		ShelfService:       s.ShelfService,
+	}
+}
+
+// Import resolves or creates the publication, work and author of each row inside a single transaction.
+// The transaction is only committed if commit is true and every row succeeded; otherwise it is rolled
+// back, which makes commit = false a dry run.
+func (s *Service) Import(rows Rows, commit bool) (*status.Status, *Report) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		log.Printf("[Import] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer tx.Rollback()
+
+	ps := s.PublicationService.WithTx(tx)
+	gs := s.GenreService.WithTx(tx)
+	ss := s.ShelfService.WithTx(tx)
+	report := &Report{DryRun: !commit, Results: []Result{}}
+	for _, row := range rows {
+		res := s.importRow(tx, ps, gs, ss, row)
+		switch res.Status {
+		case Created:
+			report.Created++
+		case Matched:
+			report.Matched++
+		case Updated:
+			report.Updated++
+		case Deleted:
+			report.Deleted++
+		case Skipped:
+			report.Skipped++
+		case Failed:
+			report.Failed++
+		}
+		report.Results = append(report.Results, res)
+	}
+
+	if report.Failed > 0 {
+		msg := fmt.Sprintf("%d of %d rows could not be imported", report.Failed, len(rows))
+		log.Printf("[Import] %s", msg)
+		if commit {
+			return status.New(status.UnprocessableEntity, msg), report
+		}
+		return status.New(status.OK, msg), report
+	}
+
+	if !commit {
+		return status.New(status.OK, ""), report
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("[Import] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.Created, ""), report
+}
+
+// importRow runs inside its own savepoint so that a failed row leaves the transaction usable.
+func (s *Service) importRow(tx *sql.Tx, ps *publication.Service, gs *genre.Service, ss *shelf.Service, row Row) Result {
+	res := Result{Source: row.Source, Line: row.Line}
+	if row.Err != nil {
+		res.Status = Failed
+		res.Message = row.Err.Error()
+		return res
+	}
+	if row.Skip != "" {
+		res.Status = Skipped
+		res.Message = row.Skip
+		return res
+	}
+
+	if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+		res.Status = Failed
+		res.Message = err.Error()
+		return res
+	}
+
+	pub := row.Publication
+	var err error
+	switch row.Action {
+	case Update:
+		err = s.update(ps, &pub, &res)
+	case Delete:
+		err = s.delete(ps, &pub, &res)
+	default:
+		err = s.resolve(ps, &pub, &res)
+	}
+	if err == nil && row.Action != Delete && len(row.Genres) > 0 {
+		err = gs.PostGenres(pub.Work.ID, row.Genres).Err()
+	}
+	if err == nil && row.Action != Delete && row.Shelf != nil {
+		entry := *row.Shelf
+		entry.Publication = pub
+		stat, put := ss.PutEntry(&entry)
+		if err = stat.Err(); err == nil {
+			res.Shelf = put
+		}
+	}
+	if err != nil {
+		tx.Exec("ROLLBACK TO SAVEPOINT import_row")
+		return Result{Source: row.Source, Line: row.Line, Status: Failed, Message: err.Error()}
+	}
+
+	if _, err := tx.Exec("RELEASE SAVEPOINT import_row"); err != nil {
+		res.Status = Failed
+		res.Message = err.Error()
+		return res
+	}
+	if res.Status != Skipped {
+		res.Publication = &pub
+	}
+	return res
+}
+
+// update overwrites the publication matching pub's ISBN, and its work, with the non-zero fields of pub.
+// The author is left as it is. If there is no such publication, it is created instead.
+func (s *Service) update(ps *publication.Service, pub *publication.Publication, res *Result) error {
+	if err := identify(pub); err != nil {
+		return err
+	}
+
+	stat, found := ps.FindPublication(pub.ISBN13)
+	if stat.Code() == status.NotFound {
+		return s.resolve(ps, pub, res)
+	} else if stat.Err() != nil {
+		return stat.Err()
+	}
+
+	wk := pub.Work
+	wk.ID = found.Work.ID
+	wk.Author = author.Author{}
+	if stat, _ := ps.WorkService.PatchWork(&wk); stat.Err() != nil {
+		return stat.Err()
+	}
+
+	pb := *pub
+	pb.ID = found.ID
+	pb.Work = work.Work{}
+	if stat, _ := ps.PatchPublication(&pb); stat.Err() != nil {
+		return stat.Err()
+	}
+
+	stat, updated := ps.GetPublication(found.ID)
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+	*pub = *updated
+	res.Status = Updated
+	return nil
+}
+
+// delete removes the publication matching pub's ISBN, skipping the row if there is no such publication.
+func (s *Service) delete(ps *publication.Service, pub *publication.Publication, res *Result) error {
+	if err := identify(pub); err != nil {
+		return err
+	}
+
+	stat, found := ps.FindPublication(pub.ISBN13)
+	if stat.Code() == status.NotFound {
+		res.Status = Skipped
+		res.Message = fmt.Sprintf("No publication with ISBN %s to delete", pub.ISBN13)
+		return nil
+	} else if stat.Err() != nil {
+		return stat.Err()
+	}
+
+	if stat := ps.DeletePublication(found.ID); stat.Err() != nil {
+		return stat.Err()
+	}
+	*pub = *found
+	res.Status = Deleted
+	return nil
+}
+
+func (s *Service) resolve(ps *publication.Service, pub *publication.Publication, res *Result) error {
+	if err := validate(pub); err != nil {
+		return err
+	}
+
+	if stat, found := ps.FindPublication(pub.ISBN13); stat.Code() == status.OK {
+		*pub = *found
+		res.Status = Matched
+		res.Message = fmt.Sprintf("Duplicate of publication with id = %d by ISBN", found.ID)
+		return nil
+	} else if stat.Code() != status.NotFound {
+		return stat.Err()
+	}
+
+	// An author whose date of birth is unknown is matched by name alone.
+	ws := &ps.WorkService
+	au := &pub.Work.Author
+	if stat, found := ws.AuthorService.FindAuthor(au.FirstName, au.LastName, au.DateOfBirth); stat.Code() == status.OK {
+		au.ID = found.ID
+		res.Author = Matched
+	} else if stat.Code() != status.NotFound {
+		return stat.Err()
+	} else {
+		if au.DateOfBirth == "" {
+			au.DateOfBirth = DefaultDateOfBirth
+		}
+		if au.PlaceOfBirth == (location.Location{}) {
+			au.PlaceOfBirth = UnknownLocation
+		}
+		stat, created := ws.AuthorService.PostAuthor(au)
+		if stat.Err() != nil {
+			return stat.Err()
+		}
+		au.ID = created.ID
+		res.Author = Created
+	}
+
+	wk := &pub.Work
+	if stat, found := ws.FindWork(wk.Title, au.ID); stat.Code() == status.OK {
+		wk.ID = found.ID
+		res.Work = Matched
+	} else if stat.Code() != status.NotFound {
+		return stat.Err()
+	} else {
+		stat, created := ws.PostWork(wk)
+		if stat.Err() != nil {
+			return stat.Err()
+		}
+		wk.ID = created.ID
+		res.Work = Created
+	}
+
+	stat, created := ps.PostPublication(pub)
+	if stat.Err() != nil {
+		return stat.Err()
+	}
+	created.Work = pub.Work
+	*pub = *created
+	res.Status = Created
+	return nil
+}
+
+// validate checks the fields required by the publication, work and author tables, deriving
+// whichever of them can be derived from the others.
+func validate(pub *publication.Publication) error {
+	wk := &pub.Work
+	au := &wk.Author
+
+	if wk.Title == "" {
+		return errors.New("missing title")
+	}
+	if au.LastName == "" {
+		return errors.New("missing author last name")
+	}
+	if err := identify(pub); err != nil {
+		return err
+	}
+
+	if pub.EditionPubDate == "" {
+		return errors.New("missing edition publication date")
+	}
+	if wk.InitialPubDate == "" {
+		wk.InitialPubDate = pub.EditionPubDate
+	}
+	if pub.ImageURL == "" {
+		pub.ImageURL = CoverURL(pub.ISBN13)
+	}
+	return nil
+}
+
+// identify checks that pub has a valid ISBN, deriving the ISBN-10 from the ISBN-13 or vice versa. Publications
+// whose ISBN-13 has no ISBN-10 equivalent, i.e. does not have the 978 prefix, are left without an ISBN-10.
+func identify(pub *publication.Publication) error {
+	if pub.ISBN13 == "" && pub.ISBN == "" {
+		return errors.New("missing ISBN")
+	}
+	if pub.ISBN13 == "" {
+		isbn13, err := isbn.To13(pub.ISBN)
+		if err != nil {
+			return err
+		}
+		pub.ISBN13 = isbn13
+	}
+	if pub.ISBN == "" && strings.HasPrefix(isbn.Normalize(pub.ISBN13), "978") {
+		isbn10, err := isbn.To10(pub.ISBN13)
+		if err != nil {
+			return err
+		}
+		pub.ISBN = isbn10
+	}
+	if !isbn.Valid(pub.ISBN13) || (pub.ISBN != "" && !isbn.Valid(pub.ISBN)) {
+		return fmt.Errorf("%w: %q, %q", isbn.ErrInvalid, pub.ISBN, pub.ISBN13)
+	}
+	pub.ISBN, pub.ISBN13 = isbn.Normalize(pub.ISBN), isbn.Normalize(pub.ISBN13)
+	return nil
+}
+
+// CoverURL returns the Open Library cover image URL for the given ISBN-13.
+func CoverURL(isbn13 string) string {
+	return fmt.Sprintf("https://covers.openlibrary.org/b/isbn/%s-L.jpg", isbn13)
+}
+
+// PlainText converts an HTML description into plain text that fits in work.description.
+func PlainText(description string) string {
+	text := html.UnescapeString(tags.ReplaceAllString(description, " "))
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > MaxDescription {
+		text = string(runes[:MaxDescription])
+	}
+	return text
+}
@@ -0,0 +1,99 @@
+package isbn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalid is returned when a value is not a well-formed ISBN-10 or ISBN-13.
+var ErrInvalid = errors.New("invalid ISBN")
+
+// Normalize strips hyphens and whitespace from s and upper-cases a trailing 'x' check digit.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == 'x' || r == 'X':
+			b.WriteRune('X')
+		}
+	}
+	return b.String()
+}
+
+// Valid reports whether s, once normalized, is a valid ISBN-10 or ISBN-13.
+func Valid(s string) bool {
+	s = Normalize(s)
+	switch len(s) {
+	case 10:
+		return isDigits(s[:9]) && s[9] == checkDigit10(s[:9])
+	case 13:
+		return isDigits(s) && s[12] == checkDigit13(s[:12])
+	default:
+		return false
+	}
+}
+
+// To10 converts the given ISBN to its ISBN-10 form. Only ISBN-13s with the 978 prefix have one.
+func To10(s string) (string, error) {
+	s = Normalize(s)
+	if !Valid(s) {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	if len(s) == 10 {
+		return s, nil
+	}
+	if !strings.HasPrefix(s, "978") {
+		return "", fmt.Errorf("%w: %q has no ISBN-10 equivalent", ErrInvalid, s)
+	}
+	body := s[3:12]
+	return body + string(checkDigit10(body)), nil
+}
+
+// To13 converts the given ISBN to its ISBN-13 form.
+func To13(s string) (string, error) {
+	s = Normalize(s)
+	if !Valid(s) {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	if len(s) == 13 {
+		return s, nil
+	}
+	body := "978" + s[:9]
+	return body + string(checkDigit13(body)), nil
+}
+
+func isDigits(s string) bool {
+	for i := range s {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
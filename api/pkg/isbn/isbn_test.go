@@ -0,0 +1,64 @@
+package isbn_test
+
+import (
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/isbn"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("Hyphens", func(t *testing.T) {
+		got := isbn.Normalize("978-0-7432-7356-5")
+		helpers.AssertEqual(t, "9780743273565", got)
+	})
+
+	t.Run("LowercaseCheckDigit", func(t *testing.T) {
+		got := isbn.Normalize(" 0-8044-2957-x ")
+		helpers.AssertEqual(t, "080442957X", got)
+	})
+}
+
+func TestValid(t *testing.T) {
+	t.Run("ISBN10", func(t *testing.T) {
+		helpers.AssertEqual(t, true, isbn.Valid("0743273567"))
+		helpers.AssertEqual(t, true, isbn.Valid("080442957X"))
+		helpers.AssertEqual(t, false, isbn.Valid("0743273568"))
+	})
+
+	t.Run("ISBN13", func(t *testing.T) {
+		helpers.AssertEqual(t, true, isbn.Valid("9780743273565"))
+		helpers.AssertEqual(t, false, isbn.Valid("9780743273566"))
+	})
+
+	t.Run("WrongLength", func(t *testing.T) {
+		helpers.AssertEqual(t, false, isbn.Valid("12345"))
+		helpers.AssertEqual(t, false, isbn.Valid(""))
+	})
+}
+
+func TestTo10(t *testing.T) {
+	t.Run("FromISBN13", func(t *testing.T) {
+		got, err := isbn.To10("9781984822178")
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, "1984822179", got)
+	})
+
+	t.Run("NoEquivalent", func(t *testing.T) {
+		_, err := isbn.To10("9791034303984")
+		helpers.AssertEqual(t, false, err == nil)
+	})
+}
+
+func TestTo13(t *testing.T) {
+	t.Run("FromISBN10", func(t *testing.T) {
+		got, err := isbn.To13("0-7352-2429-3")
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, "9780735224292", got)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := isbn.To13("0735224294")
+		helpers.AssertEqual(t, false, err == nil)
+	})
+}
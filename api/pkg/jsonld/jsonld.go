@@ -0,0 +1,206 @@
+package jsonld
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+// MediaType is the media type of JSON-LD documents.
+const MediaType string = "application/ld+json"
+
+// Context is the JSON-LD context of every document: the schema.org vocabulary.
+const Context string = "https://schema.org"
+
+// bookFormats maps publication formats onto schema.org BookFormatType values.
+var bookFormats = map[string]string{
+	"hardcover":     "https://schema.org/Hardcover",
+	"paperback":     "https://schema.org/Paperback",
+	"mass market":   "https://schema.org/Paperback",
+	"ebook":         "https://schema.org/EBook",
+	"kindle":        "https://schema.org/EBook",
+	"audiobook":     "https://schema.org/AudiobookFormat",
+	"audio cd":      "https://schema.org/AudiobookFormat",
+	"graphic novel": "https://schema.org/GraphicNovel",
+}
+
+// Book is a schema.org Book. A work is a Book whose editions are its workExample,
+// and each publication is a Book that is an exampleOfWork.
+type Book struct {
+	Context       string        `json:"@context,omitempty"`
+	Type          string        `json:"@type"`
+	ID            string        `json:"@id"`
+	Name          string        `json:"name"`
+	Author        *Person       `json:"author,omitempty"`
+	Description   string        `json:"description,omitempty"`
+	DatePublished string        `json:"datePublished,omitempty"`
+	InLanguage    string        `json:"inLanguage,omitempty"`
+	BookFormat    string        `json:"bookFormat,omitempty"`
+	BookEdition   string        `json:"bookEdition,omitempty"`
+	ISBN          string        `json:"isbn,omitempty"`
+	NumberOfPages int           `json:"numberOfPages,omitempty"`
+	Publisher     *Organization `json:"publisher,omitempty"`
+	Image         string        `json:"image,omitempty"`
+	IsPartOf      *BookSeries   `json:"isPartOf,omitempty"`
+	Position      float64       `json:"position,omitempty"`
+	ExampleOfWork *Book         `json:"exampleOfWork,omitempty"`
+	WorkExample   []Book        `json:"workExample,omitempty"`
+}
+
+// BookSeries is a schema.org BookSeries.
+type BookSeries struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// Organization is a schema.org Organization, used for publishers.
+type Organization struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// Person is a schema.org Person.
+type Person struct {
+	Context    string `json:"@context,omitempty"`
+	Type       string `json:"@type"`
+	ID         string `json:"@id"`
+	Name       string `json:"name"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	Gender     string `json:"gender,omitempty"`
+	BirthDate  string `json:"birthDate,omitempty"`
+	BirthPlace *Place `json:"birthPlace,omitempty"`
+}
+
+// Place is a schema.org Place.
+type Place struct {
+	Type    string         `json:"@type"`
+	Name    string         `json:"name"`
+	Address *PostalAddress `json:"address,omitempty"`
+}
+
+// PostalAddress is a schema.org PostalAddress.
+type PostalAddress struct {
+	Type            string `json:"@type"`
+	AddressLocality string `json:"addressLocality,omitempty"`
+	AddressRegion   string `json:"addressRegion,omitempty"`
+	AddressCountry  string `json:"addressCountry,omitempty"`
+}
+
+// FromPublication returns the given publication as a Book that is an example of its work.
+// Identifiers are the URLs of the API's own resources under base, e.g. https://example.com.
+func FromPublication(base string, pub *publication.Publication) *Book {
+	book := edition(base, pub)
+	book.Context = Context
+	book.Author = person(base, &pub.Work.Author)
+	book.ExampleOfWork = &Book{
+		Type: "Book",
+		ID:   fmt.Sprintf("%s/api/work/%d", base, pub.Work.ID),
+		Name: pub.Work.Title,
+	}
+	return book
+}
+
+// FromWork returns the given work as a Book, with the given publications as its editions.
+func FromWork(base string, wk *work.Work, pubs publication.Publications) *Book {
+	book := &Book{
+		Context:       Context,
+		Type:          "Book",
+		ID:            fmt.Sprintf("%s/api/work/%d", base, wk.ID),
+		Name:          wk.Title,
+		Author:        person(base, &wk.Author),
+		Description:   wk.Description,
+		DatePublished: day(wk.InitialPubDate),
+		InLanguage:    tag(wk.OriginalLanguage),
+	}
+	if wk.Series != "" {
+		book.IsPartOf = &BookSeries{Type: "BookSeries", Name: wk.Series}
+		book.Position = wk.SeriesIndex
+	}
+	for i := range pubs {
+		book.WorkExample = append(book.WorkExample, *edition(base, &pubs[i]))
+	}
+	return book
+}
+
+// FromAuthor returns the given author as a Person, with their place of birth.
+func FromAuthor(base string, au *author.Author) *Person {
+	p := person(base, au)
+	p.Context = Context
+	p.GivenName = au.FirstName
+	p.FamilyName = au.LastName
+	p.Gender = au.Gender
+	p.BirthDate = day(au.DateOfBirth)
+	p.BirthPlace = place(&au.PlaceOfBirth)
+	return p
+}
+
+// edition returns the properties of the given publication that are particular to its edition.
+func edition(base string, pub *publication.Publication) *Book {
+	book := &Book{
+		Type:          "Book",
+		ID:            fmt.Sprintf("%s/api/publication/%d", base, pub.ID),
+		Name:          pub.Work.Title,
+		DatePublished: day(pub.EditionPubDate),
+		InLanguage:    tag(pub.Language),
+		BookFormat:    bookFormats[strings.ToLower(pub.Format)],
+		BookEdition:   pub.Edition,
+		ISBN:          pub.ISBN13,
+		NumberOfPages: pub.NumPages,
+		Image:         pub.ImageURL,
+	}
+	if pub.Publisher != "" {
+		book.Publisher = &Organization{Type: "Organization", Name: pub.Publisher}
+	}
+	return book
+}
+
+func person(base string, au *author.Author) *Person {
+	return &Person{
+		Type: "Person",
+		ID:   fmt.Sprintf("%s/api/author/%d", base, au.ID),
+		Name: strings.TrimSpace(au.FirstName + " " + au.LastName),
+	}
+}
+
+func place(loc *location.Location) *Place {
+	var parts []string
+	for _, part := range []string{loc.City, loc.Region, loc.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &Place{
+		Type: "Place",
+		Name: strings.Join(parts, ", "),
+		Address: &PostalAddress{
+			Type:            "PostalAddress",
+			AddressLocality: loc.City,
+			AddressRegion:   loc.Region,
+			AddressCountry:  loc.Country,
+		},
+	}
+}
+
+func tag(name string) string {
+	if name == "" {
+		return ""
+	}
+	return language.Tag(name)
+}
+
+// day returns the date part of a timestamp as stored in the database.
+func day(timestamp string) string {
+	if len(timestamp) > 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
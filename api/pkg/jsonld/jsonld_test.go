@@ -0,0 +1,111 @@
+package jsonld_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/jsonld"
+	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+const base = "https://books.example.com"
+
+func testPublication() publication.Publication {
+	pub := publication.Publication{
+		ID:             4,
+		EditionPubDate: "2004-09-30T00:00:00Z",
+		Format:         "Paperback",
+		ImageURL:       "https://example.com/gatsby.jpg",
+		ISBN13:         "9780743273565",
+		Language:       "English",
+		NumPages:       180,
+		Publisher:      "Scribner",
+		Work: work.Work{
+			ID:               2,
+			Title:            "The Great Gatsby",
+			InitialPubDate:   "1925-04-10T00:00:00Z",
+			OriginalLanguage: "English",
+		},
+	}
+	pub.Work.Author.ID = 1
+	pub.Work.Author.FirstName = "F. Scott"
+	pub.Work.Author.LastName = "Fitzgerald"
+	pub.Work.Author.DateOfBirth = "1896-09-24T00:00:00Z"
+	pub.Work.Author.PlaceOfBirth = location.Location{City: "St. Paul", Region: "Minnesota", Country: "United States"}
+	return pub
+}
+
+// decode round trips v through JSON, so the tests look at the document as clients see it.
+func decode(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	bytes, err := json.Marshal(v)
+	helpers.AssertEqual(t, nil, err)
+
+	var doc map[string]interface{}
+	helpers.AssertEqual(t, nil, json.Unmarshal(bytes, &doc))
+	return doc
+}
+
+func TestFromPublication(t *testing.T) {
+	pub := testPublication()
+	doc := decode(t, jsonld.FromPublication(base, &pub))
+
+	helpers.AssertEqual(t, "https://schema.org", doc["@context"])
+	helpers.AssertEqual(t, "Book", doc["@type"])
+	helpers.AssertEqual(t, base+"/api/publication/4", doc["@id"])
+	helpers.AssertEqual(t, "9780743273565", doc["isbn"])
+	helpers.AssertEqual(t, "https://schema.org/Paperback", doc["bookFormat"])
+	helpers.AssertEqual(t, 180.0, doc["numberOfPages"])
+	helpers.AssertEqual(t, "en", doc["inLanguage"])
+	helpers.AssertEqual(t, "2004-09-30", doc["datePublished"])
+	helpers.AssertEqual(t, map[string]interface{}{"@type": "Organization", "name": "Scribner"}, doc["publisher"])
+	helpers.AssertEqual(t, base+"/api/work/2", doc["exampleOfWork"].(map[string]interface{})["@id"])
+	helpers.AssertEqual(t, "F. Scott Fitzgerald", doc["author"].(map[string]interface{})["name"])
+}
+
+func TestFromWork(t *testing.T) {
+	hardcover, ebook := testPublication(), testPublication()
+	hardcover.Format = "Hardcover"
+	ebook.ID, ebook.Format, ebook.ISBN13 = 5, "Ebook", "9781439567715"
+	wk := hardcover.Work
+	wk.Series, wk.SeriesIndex = "Jazz Age", 1
+
+	doc := decode(t, jsonld.FromWork(base, &wk, publication.Publications{hardcover, ebook}))
+
+	helpers.AssertEqual(t, base+"/api/work/2", doc["@id"])
+	helpers.AssertEqual(t, "1925-04-10", doc["datePublished"])
+	helpers.AssertEqual(t, map[string]interface{}{"@type": "BookSeries", "name": "Jazz Age"}, doc["isPartOf"])
+	helpers.AssertEqual(t, 1.0, doc["position"])
+
+	editions := doc["workExample"].([]interface{})
+	helpers.AssertEqual(t, 2, len(editions))
+	helpers.AssertEqual(t, "https://schema.org/Hardcover", editions[0].(map[string]interface{})["bookFormat"])
+	helpers.AssertEqual(t, "https://schema.org/EBook", editions[1].(map[string]interface{})["bookFormat"])
+	helpers.AssertEqual(t, "9781439567715", editions[1].(map[string]interface{})["isbn"])
+	helpers.AssertEqual(t, nil, editions[1].(map[string]interface{})["@context"])
+}
+
+func TestFromAuthor(t *testing.T) {
+	pub := testPublication()
+	doc := decode(t, jsonld.FromAuthor(base, &pub.Work.Author))
+
+	helpers.AssertEqual(t, "Person", doc["@type"])
+	helpers.AssertEqual(t, base+"/api/author/1", doc["@id"])
+	helpers.AssertEqual(t, "Fitzgerald", doc["familyName"])
+	helpers.AssertEqual(t, "1896-09-24", doc["birthDate"])
+
+	birthPlace := doc["birthPlace"].(map[string]interface{})
+	helpers.AssertEqual(t, "Place", birthPlace["@type"])
+	helpers.AssertEqual(t, "St. Paul, Minnesota, United States", birthPlace["name"])
+	helpers.AssertEqual(t, "United States", birthPlace["address"].(map[string]interface{})["addressCountry"])
+
+	t.Run("UnknownBirthPlace", func(t *testing.T) {
+		au := pub.Work.Author
+		au.PlaceOfBirth = location.Location{}
+		helpers.AssertEqual(t, nil, decode(t, jsonld.FromAuthor(base, &au))["birthPlace"])
+	})
+}
@@ -0,0 +1,78 @@
+package language
+
+import "strings"
+
+// A language is identified by its English name, as stored in the publication and work tables,
+// by its ISO 639-1 code, and by its ISO 639-2 bibliographic and terminologic codes, which are
+// usually the same.
+type language struct {
+	name   string
+	alpha2 string
+	bib    string
+	term   string
+}
+
+var languages = []language{
+	{"Arabic", "ar", "ara", "ara"},
+	{"Chinese", "zh", "chi", "zho"},
+	{"Czech", "cs", "cze", "ces"},
+	{"Danish", "da", "dan", "dan"},
+	{"Dutch", "nl", "dut", "nld"},
+	{"English", "en", "eng", "eng"},
+	{"Finnish", "fi", "fin", "fin"},
+	{"French", "fr", "fre", "fra"},
+	{"German", "de", "ger", "deu"},
+	{"Greek", "el", "gre", "ell"},
+	{"Hebrew", "he", "heb", "heb"},
+	{"Hindi", "hi", "hin", "hin"},
+	{"Hungarian", "hu", "hun", "hun"},
+	{"Icelandic", "is", "ice", "isl"},
+	{"Italian", "it", "ita", "ita"},
+	{"Japanese", "ja", "jpn", "jpn"},
+	{"Korean", "ko", "kor", "kor"},
+	{"Latin", "la", "lat", "lat"},
+	{"Norwegian", "no", "nor", "nor"},
+	{"Persian", "fa", "per", "fas"},
+	{"Polish", "pl", "pol", "pol"},
+	{"Portuguese", "pt", "por", "por"},
+	{"Russian", "ru", "rus", "rus"},
+	{"Spanish", "es", "spa", "spa"},
+	{"Swedish", "sv", "swe", "swe"},
+	{"Turkish", "tr", "tur", "tur"},
+	{"Ukrainian", "uk", "ukr", "ukr"},
+	{"Vietnamese", "vi", "vie", "vie"},
+}
+
+// Name returns the English name of the language with the given ISO 639-1 or ISO 639-2 code.
+// Codes it does not know are returned unchanged.
+func Name(code string) string {
+	c := strings.ToLower(strings.TrimSpace(code))
+	for _, l := range languages {
+		if l.alpha2 == c || l.bib == c || l.term == c {
+			return l.name
+		}
+	}
+	return code
+}
+
+// Code returns the ISO 639-2 bibliographic code of the language with the given English name.
+// Names it does not know are returned unchanged.
+func Code(name string) string {
+	for _, l := range languages {
+		if strings.EqualFold(l.name, strings.TrimSpace(name)) {
+			return l.bib
+		}
+	}
+	return name
+}
+
+// Tag returns the BCP 47 tag of the language with the given English name, which is its ISO 639-1 code.
+// Names it does not know are returned unchanged.
+func Tag(name string) string {
+	for _, l := range languages {
+		if strings.EqualFold(l.name, strings.TrimSpace(name)) {
+			return l.alpha2
+		}
+	}
+	return name
+}
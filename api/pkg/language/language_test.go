@@ -0,0 +1,48 @@
+package language_test
+
+import (
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+)
+
+func TestName(t *testing.T) {
+	for code, want := range map[string]string{
+		"eng": "English",
+		"fre": "French",
+		"fra": "French",
+		"de":  "German",
+		"ZH":  "Chinese",
+		"xyz": "xyz",
+	} {
+		t.Run(code, func(t *testing.T) {
+			helpers.AssertEqual(t, want, language.Name(code))
+		})
+	}
+}
+
+func TestCode(t *testing.T) {
+	for name, want := range map[string]string{
+		"English":  "eng",
+		"german":   "ger",
+		"Klingon":  "Klingon",
+		" French ": "fre",
+	} {
+		t.Run(name, func(t *testing.T) {
+			helpers.AssertEqual(t, want, language.Code(name))
+		})
+	}
+}
+
+func TestTag(t *testing.T) {
+	for name, want := range map[string]string{
+		"English": "en",
+		"chinese": "zh",
+		"Klingon": "Klingon",
+	} {
+		t.Run(name, func(t *testing.T) {
+			helpers.AssertEqual(t, want, language.Tag(name))
+		})
+	}
+}
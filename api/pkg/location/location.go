@@ -6,10 +6,14 @@ import (
 	"log"
 
 	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/history"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/lib/pq"
 )
 
+// Entity is the name that the changes made to locations are recorded under in the history.
+const Entity string = "location"
+
 // Columns is the comma-separated list of columns found in the location table.
 const Columns string = "city, country, region"
 
@@ -38,19 +42,27 @@ type Service struct {
 	DB postgres.DB
 }
 
+// WithTx returns a copy of the receiver whose statements are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx)}
+}
+
+// WithActor returns a copy of the receiver whose changes are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{DB: s.DB.WithActor(actor)}
+}
+
 // Query returns a SQL statement based on the postgres.Query value passed in.
 func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 	switch query {
 	case DeleteLocation:
 		return "DELETE FROM location WHERE ID = $1"
 	case FindLocation:
-		city := args[0].(string)
-		country := args[1].(string)
 		return fmt.Sprintf(
 			`SELECT id, %s
                         FROM location
-                        WHERE city = '%s' AND country = '%s'`,
-			Columns, city, country,
+                        WHERE city = $1 AND country = $2`,
+			Columns,
 		)
 	case GetLocation:
 		return fmt.Sprintf("SELECT id, %s FROM location WHERE id = $1", Columns)
@@ -68,35 +80,23 @@ func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 
 // DeleteLocation removes the entry in the location table matching the given id.
 func (s *Service) DeleteLocation(id int) *status.Status {
-	db := s.DB
-	deleteLocation := s.Query(DeleteLocation)
-
-	res, err := db.Exec(deleteLocation, id)
-	if err != nil {
-		log.Printf("[DeleteLocation] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-
-	numDeleted, err := res.RowsAffected()
-	if err != nil {
-		log.Printf("[DeleteLocation] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-	if numDeleted == 0 {
-		msg := fmt.Sprintf("Location with id = %d does not exist", id)
-		log.Printf("[DeleteLocation] %s", msg)
-		return status.New(status.OK, msg)
-	}
-	return status.New(status.NoContent, "")
+	return s.transaction(func(s *Service) *status.Status {
+		_, before := s.GetLocation(id)
+		stat := s.deleteLocation(id)
+		if stat.Code() != status.NoContent {
+			return stat
+		}
+		return s.record(history.Delete, id, before, nil, stat)
+	})
 }
 
 // FindLocation retrieves the location from the database matching the given city and country.
 func (s *Service) FindLocation(city string, country string) (*status.Status, *Location) {
 	db := s.DB
-	findLocation := s.Query(FindLocation, city, country)
+	findLocation := s.Query(FindLocation)
 
 	var location Location
-	row := db.QueryRow(findLocation)
+	row := db.QueryRow(findLocation, city, country)
 	if err := row.Scan(&location.ID, &location.City, &location.Country, &location.Region); err != nil {
 		if err == sql.ErrNoRows {
 			msg := fmt.Sprintf("Location ('%s', '%s') does not exist", city, country)
@@ -130,6 +130,45 @@ func (s *Service) GetLocation(id int) (*status.Status, *Location) {
 
 // PostLocation creates an entry in the location table with the given attributes.
 func (s *Service) PostLocation(location *Location) (*status.Status, *Location) {
+	var posted *Location
+	stat := s.transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, posted = s.postLocation(location); stat.Code() != status.Created {
+			return stat
+		}
+		return s.record(history.Post, posted.ID, nil, posted, stat)
+	})
+	if stat.Code() == status.Conflict && posted == nil {
+		// The location taking the city and country can only be looked up once the failed insert is rolled back.
+		_, posted = s.FindLocation(location.City, location.Country)
+	}
+	return stat, posted
+}
+
+func (s *Service) deleteLocation(id int) *status.Status {
+	db := s.DB
+	deleteLocation := s.Query(DeleteLocation)
+
+	res, err := db.Exec(deleteLocation, id)
+	if err != nil {
+		log.Printf("[DeleteLocation] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	numDeleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[DeleteLocation] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if numDeleted == 0 {
+		msg := fmt.Sprintf("Location with id = %d does not exist", id)
+		log.Printf("[DeleteLocation] %s", msg)
+		return status.New(status.OK, msg)
+	}
+	return status.New(status.NoContent, "")
+}
+
+func (s *Service) postLocation(location *Location) (*status.Status, *Location) {
 	if location.ID != 0 {
 		if s, l := s.GetLocation(location.ID); s.Code() == status.OK {
 			msg := fmt.Sprintf("Location with id = %d already exists", location.ID)
@@ -145,12 +184,35 @@ func (s *Service) PostLocation(location *Location) (*status.Status, *Location) {
 	row := db.QueryRow(postLocation, location.City, location.Country, location.Region)
 	if err := row.Scan(&loc.ID, &loc.City, &loc.Country, &loc.Region); err != nil {
 		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
-			_, l := s.FindLocation(location.City, location.Country)
 			log.Printf("[PostLocation] %s", err)
-			return status.New(status.Conflict, err.Error()), l
+			return status.New(status.Conflict, err.Error()), nil
 		}
 		log.Printf("[PostLocation] %s", err)
 		return status.New(status.UnprocessableEntity, err.Error()), nil
 	}
 	return status.New(status.Created, ""), &loc
 }
+
+// record records a change made to the location matching the given id in the history, and returns stat, the status
+// of the change, if it succeeds.
+func (s *Service) record(action string, id int, before *Location, after *Location, stat *status.Status) *status.Status {
+	hs := &history.Service{DB: s.DB}
+	if s := hs.PostRevision(Entity, id, action, before, after); s.Err() != nil {
+		return s
+	}
+	return stat
+}
+
+// transaction calls fn with a copy of the receiver whose statements are executed inside a transaction, so that
+// changes are recorded in the history along with them, as described by postgres.DB.Transaction.
+func (s *Service) transaction(fn func(*Service) *status.Status) *status.Status {
+	var stat *status.Status
+	if err := s.DB.Transaction(func(tx *sql.Tx) error {
+		stat = fn(s.WithTx(tx))
+		return stat.Err()
+	}); err != nil && (stat == nil || stat.Err() == nil) {
+		log.Printf("[transaction] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return stat
+}
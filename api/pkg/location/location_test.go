@@ -3,7 +3,7 @@ package location_test
 import (
 	"testing"
 
-	"github.com/andrewzulaybar/books/api/pkg/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
 	"github.com/andrewzulaybar/books/api/pkg/location"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/andrewzulaybar/books/api/test/data"
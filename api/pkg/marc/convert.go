@@ -0,0 +1,165 @@
+package marc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/isbn"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// Leader is the leader of exported records: a new, language material, monograph record in UTF-8.
+// Its record length and base address are filled in by WriteBinary.
+const Leader string = "00000nam a2200000 i 4500"
+
+var (
+	year      = regexp.MustCompile(`\d{4}`)
+	pages     = regexp.MustCompile(`(\d+)\s*(pages|p\b|p\.)`)
+	qualifier = regexp.MustCompile(`\(([^)]*)\)`)
+)
+
+// ToPublication maps the fields of the given record onto a publication, its work and their author:
+// 020 ISBN, 041 language, 100 author, 245 title, 250 edition, 260/264 publisher and date,
+// 300 pages and 520 summary.
+func ToPublication(rec *Record) publication.Publication {
+	var pub publication.Publication
+	wk := &pub.Work
+	au := &wk.Author
+
+	for _, f := range rec.Fields("020") {
+		// Fields carrying only a cancelled or invalid ISBN in $z have no $a.
+		a := f.Subfield("a")
+		if len(strings.Fields(a)) == 0 {
+			continue
+		}
+		number := isbn.Normalize(strings.Fields(a)[0])
+		switch {
+		case len(number) == 13 && pub.ISBN13 == "":
+			pub.ISBN13 = number
+		case len(number) == 10 && pub.ISBN == "":
+			pub.ISBN = number
+		default:
+			continue
+		}
+		if pub.Format == "" {
+			q := f.Subfield("q")
+			if m := qualifier.FindStringSubmatch(a); q == "" && m != nil {
+				q = m[1]
+			}
+			pub.Format = strings.Title(trim(strings.Trim(q, "()")))
+		}
+	}
+
+	code := rec.Subfield("041", "a")
+	if f008 := rec.ControlField("008"); code == "" && len(f008) >= 38 {
+		code = f008[35:38]
+	}
+	pub.Language = language.Name(trim(code))
+	wk.OriginalLanguage = pub.Language
+
+	au.FirstName, au.LastName = importer.SplitName(trim(rec.Subfield("100", "a")))
+	if y := year.FindString(rec.Subfield("100", "d")); y != "" {
+		au.DateOfBirth = y + "-01-01"
+	}
+
+	wk.Title = trim(rec.Subfield("245", "a"))
+	if subtitle := trim(rec.Subfield("245", "b")); subtitle != "" {
+		wk.Title += ": " + subtitle
+	}
+	wk.Description = trim(rec.Subfield("520", "a"))
+	pub.Edition = trim(rec.Subfield("250", "a"))
+
+	imprint := rec.Fields("260")
+	for _, f := range rec.Fields("264") {
+		if f.Ind2 == "1" {
+			imprint = []DataField{f}
+		}
+	}
+	if len(imprint) > 0 {
+		pub.Publisher = trim(imprint[0].Subfield("b"))
+		if y := year.FindString(imprint[0].Subfield("c")); y != "" {
+			pub.EditionPubDate = y + "-01-01"
+		}
+	}
+	if m := pages.FindStringSubmatch(rec.Subfield("300", "a")); m != nil {
+		pub.NumPages, _ = strconv.Atoi(m[1])
+	}
+	return pub
+}
+
+// ToRows converts the given records into rows to be imported, numbering them from 1.
+func ToRows(records []Record) importer.Rows {
+	rows := importer.Rows{}
+	for i := range records {
+		rows = append(rows, importer.Row{Line: i + 1, Publication: ToPublication(&records[i])})
+	}
+	return rows
+}
+
+// FromPublication creates a record from the given publication, the inverse of ToPublication.
+func FromPublication(pub *publication.Publication) Record {
+	wk := &pub.Work
+	au := &wk.Author
+	rec := Record{Leader: Leader}
+
+	date1 := "uuuu"
+	if len(pub.EditionPubDate) >= 4 {
+		date1 = pub.EditionPubDate[:4]
+	}
+	code := language.Code(pub.Language)
+	if len(code) != 3 {
+		code = "und"
+	}
+	rec.ControlFields = []ControlField{
+		{Tag: "001", Value: strconv.Itoa(pub.ID)},
+		{Tag: "008", Value: fmt.Sprintf("||||||s%s    xx %17s%s d", date1, "", code)},
+	}
+
+	add := func(tag string, ind1 string, ind2 string, subfields ...string) {
+		f := DataField{Tag: tag, Ind1: ind1, Ind2: ind2}
+		for i := 0; i+1 < len(subfields); i += 2 {
+			if subfields[i+1] != "" {
+				f.Subfields = append(f.Subfields, Subfield{Code: subfields[i], Value: subfields[i+1]})
+			}
+		}
+		if len(f.Subfields) > 0 {
+			rec.DataFields = append(rec.DataFields, f)
+		}
+	}
+
+	add("020", " ", " ", "a", pub.ISBN13, "q", strings.ToLower(pub.Format))
+	add("020", " ", " ", "a", pub.ISBN, "q", strings.ToLower(pub.Format))
+	if code != "und" {
+		add("041", "0", " ", "a", code)
+	}
+
+	name := au.LastName
+	if au.FirstName != "" {
+		name += ", " + au.FirstName
+	}
+	var dates string
+	if len(au.DateOfBirth) >= 4 && !strings.HasPrefix(au.DateOfBirth, importer.DefaultDateOfBirth[:10]) {
+		dates = au.DateOfBirth[:4] + "-"
+	}
+	add("100", "1", " ", "a", name, "d", dates)
+	add("245", "1", "0", "a", wk.Title)
+	add("250", " ", " ", "a", pub.Edition)
+	add("264", " ", "1", "b", pub.Publisher, "c", strings.TrimPrefix(date1, "uuuu"))
+	if pub.NumPages > 0 {
+		add("300", " ", " ", "a", fmt.Sprintf("%d pages", pub.NumPages))
+	}
+	add("520", " ", " ", "a", wk.Description)
+	if pub.ImageURL != "" {
+		add("856", "4", "2", "3", "Cover image", "u", pub.ImageURL)
+	}
+	return rec
+}
+
+// trim removes the whitespace and ISBD punctuation that MARC puts around subfield values.
+func trim(s string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,.="))
+}
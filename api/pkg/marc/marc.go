@@ -0,0 +1,279 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Namespace is the XML namespace of MARCXML documents.
+const Namespace string = "http://www.loc.gov/MARC21/slim"
+
+// Delimiters used by binary (ISO 2709) MARC21 records.
+const (
+	subfieldDelimiter byte = 0x1F
+	fieldTerminator   byte = 0x1E
+	recordTerminator  byte = 0x1D
+)
+
+// ErrMalformed is returned when a binary record does not follow the ISO 2709 structure.
+var ErrMalformed = errors.New("malformed MARC record")
+
+// Collection is a list of records, the root element of a MARCXML document.
+type Collection struct {
+	XMLName xml.Name `xml:"http://www.loc.gov/MARC21/slim collection"`
+	Records []Record `xml:"record"`
+}
+
+// Record is a single bibliographic record.
+type Record struct {
+	Leader        string         `xml:"leader"`
+	ControlFields []ControlField `xml:"controlfield"`
+	DataFields    []DataField    `xml:"datafield"`
+}
+
+// ControlField is a field with tag 001 to 009, which holds a single value.
+type ControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// DataField is a field with tag 010 to 999, which holds two indicators and a list of subfields.
+type DataField struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+// Subfield is a single coded value within a data field.
+type Subfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ControlField returns the value of the first control field with the given tag.
+func (r *Record) ControlField(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields returns every data field with the given tag.
+func (r *Record) Fields(tag string) []DataField {
+	var fields []DataField
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Subfield returns the value of the first subfield with the given code in the first data field with the given tag.
+func (r *Record) Subfield(tag string, code string) string {
+	for _, f := range r.Fields(tag) {
+		if v := f.Subfield(code); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// Subfield returns the value of the first subfield with the given code.
+func (f *DataField) Subfield(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+	return ""
+}
+
+// Read reads every record in r, which may be either binary MARC21 or MARCXML.
+func Read(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return []Record{}, nil
+			}
+			return nil, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+			continue
+		case '<':
+			return ReadXML(br)
+		default:
+			return ReadBinary(br)
+		}
+	}
+}
+
+// ReadXML reads every record element in the MARCXML document r, whether or not it is wrapped in a collection.
+func ReadXML(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok && start.Name.Local == "record" {
+			var rec Record
+			if err := dec.DecodeElement(&rec, &start); err != nil {
+				return nil, err
+			}
+			records = append(records, rec)
+		}
+	}
+}
+
+// WriteXML writes the given records to w as a MARCXML collection.
+func WriteXML(w io.Writer, records []Record) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(Collection{Records: records}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadBinary reads every ISO 2709 record in r. Records are expected to be encoded in UTF-8.
+func ReadBinary(r io.Reader) ([]Record, error) {
+	records := []Record{}
+	length := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(r, length); err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+		n, err := strconv.Atoi(string(length))
+		if err != nil || n < 25 {
+			return nil, fmt.Errorf("%w: invalid record length %q", ErrMalformed, length)
+		}
+
+		data := make([]byte, n)
+		copy(data, length)
+		if _, err := io.ReadFull(r, data[5:]); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		rec, err := parseBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *rec)
+	}
+}
+
+func parseBinary(data []byte) (*Record, error) {
+	if data[len(data)-1] != recordTerminator {
+		return nil, fmt.Errorf("%w: missing record terminator", ErrMalformed)
+	}
+
+	leader := string(data[:24])
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base < 25 || base > len(data) {
+		return nil, fmt.Errorf("%w: invalid base address %q", ErrMalformed, leader[12:17])
+	}
+
+	rec := &Record{Leader: leader}
+	directory := data[24 : base-1]
+	if len(directory)%12 != 0 {
+		return nil, fmt.Errorf("%w: invalid directory length %d", ErrMalformed, len(directory))
+	}
+	for i := 0; i < len(directory); i += 12 {
+		entry := string(directory[i : i+12])
+		tag := entry[:3]
+		length, err1 := strconv.Atoi(entry[3:7])
+		start, err2 := strconv.Atoi(entry[7:12])
+		if err1 != nil || err2 != nil || base+start+length > len(data) || length < 1 {
+			return nil, fmt.Errorf("%w: invalid directory entry %q", ErrMalformed, entry)
+		}
+
+		field := data[base+start : base+start+length-1]
+		if tag < "010" {
+			rec.ControlFields = append(rec.ControlFields, ControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+		if len(field) < 2 {
+			return nil, fmt.Errorf("%w: field %s is missing its indicators", ErrMalformed, tag)
+		}
+
+		df := DataField{Tag: tag, Ind1: string(field[0]), Ind2: string(field[1])}
+		for _, sf := range bytes.Split(field[2:], []byte{subfieldDelimiter}) {
+			if len(sf) == 0 {
+				continue
+			}
+			df.Subfields = append(df.Subfields, Subfield{Code: string(sf[0]), Value: string(sf[1:])})
+		}
+		rec.DataFields = append(rec.DataFields, df)
+	}
+	return rec, nil
+}
+
+// WriteBinary writes the given records to w as ISO 2709 records, computing each record's
+// length, base address and directory.
+func WriteBinary(w io.Writer, records []Record) error {
+	for _, rec := range records {
+		var directory, fields bytes.Buffer
+		add := func(tag string, data []byte) {
+			fmt.Fprintf(&directory, "%s%04d%05d", tag, len(data)+1, fields.Len())
+			fields.Write(data)
+			fields.WriteByte(fieldTerminator)
+		}
+
+		for _, f := range rec.ControlFields {
+			add(f.Tag, []byte(f.Value))
+		}
+		for _, f := range rec.DataFields {
+			var data bytes.Buffer
+			data.WriteString(indicator(f.Ind1) + indicator(f.Ind2))
+			for _, sf := range f.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteString(sf.Code + sf.Value)
+			}
+			add(f.Tag, data.Bytes())
+		}
+		directory.WriteByte(fieldTerminator)
+
+		leader := []byte(fmt.Sprintf("%-24s", rec.Leader)[:24])
+		base := 24 + directory.Len()
+		copy(leader[0:5], fmt.Sprintf("%05d", base+fields.Len()+1))
+		copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+		for _, b := range [][]byte{leader, directory.Bytes(), fields.Bytes(), {recordTerminator}} {
+			if _, err := w.Write(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func indicator(ind string) string {
+	if ind == "" {
+		return " "
+	}
+	return ind[:1]
+}
@@ -0,0 +1,172 @@
+package marc_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/marc"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+const gatsbyXML = `<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>00000cam a2200000 i 4500</leader>
+    <controlfield tag="001">12345</controlfield>
+    <controlfield tag="008">040101s2004    nyu           000 1 eng d</controlfield>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="a">9780743273565</subfield>
+      <subfield code="q">paperback</subfield>
+    </datafield>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="a">0743273567 (pbk.)</subfield>
+    </datafield>
+    <datafield tag="100" ind1="1" ind2=" ">
+      <subfield code="a">Fitzgerald, F. Scott</subfield>
+      <subfield code="q">(Francis Scott),</subfield>
+      <subfield code="d">1896-1940.</subfield>
+    </datafield>
+    <datafield tag="245" ind1="1" ind2="4">
+      <subfield code="a">The great Gatsby /</subfield>
+      <subfield code="c">F. Scott Fitzgerald.</subfield>
+    </datafield>
+    <datafield tag="250" ind1=" " ind2=" ">
+      <subfield code="a">Scribner trade pbk. ed.</subfield>
+    </datafield>
+    <datafield tag="264" ind1=" " ind2="1">
+      <subfield code="a">New York :</subfield>
+      <subfield code="b">Scribner,</subfield>
+      <subfield code="c">[2004]</subfield>
+    </datafield>
+    <datafield tag="300" ind1=" " ind2=" ">
+      <subfield code="a">180 pages ;</subfield>
+      <subfield code="c">21 cm</subfield>
+    </datafield>
+  </record>
+</collection>
+`
+
+func TestRead(t *testing.T) {
+	t.Run("XML", func(t *testing.T) {
+		records, err := marc.Read(strings.NewReader(gatsbyXML))
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 1, len(records))
+		helpers.AssertEqual(t, "12345", records[0].ControlField("001"))
+		helpers.AssertEqual(t, "Scribner,", records[0].Subfield("264", "b"))
+		helpers.AssertEqual(t, 2, len(records[0].Fields("020")))
+	})
+
+	t.Run("BinaryRoundTrip", func(t *testing.T) {
+		records, err := marc.ReadXML(strings.NewReader(gatsbyXML))
+		helpers.AssertEqual(t, nil, err)
+
+		var buf bytes.Buffer
+		err = marc.WriteBinary(&buf, append(records, records...))
+		helpers.AssertEqual(t, nil, err)
+
+		got, err := marc.Read(&buf)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 2, len(got))
+		helpers.AssertEqual(t, records[0].ControlFields, got[1].ControlFields)
+		helpers.AssertEqual(t, records[0].DataFields, got[1].DataFields)
+		helpers.AssertEqual(t, "00", got[0].Leader[:2])
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := marc.Read(strings.NewReader("00042nam a2200000 i 4500garbage"))
+		helpers.AssertEqual(t, true, errors.Is(err, marc.ErrMalformed))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		records, err := marc.Read(strings.NewReader("\n"))
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 0, len(records))
+	})
+}
+
+func TestToPublication(t *testing.T) {
+	t.Run("Record", func(t *testing.T) {
+		records, err := marc.ReadXML(strings.NewReader(gatsbyXML))
+		helpers.AssertEqual(t, nil, err)
+
+		pub := marc.ToPublication(&records[0])
+		helpers.AssertEqual(t, "9780743273565", pub.ISBN13)
+		helpers.AssertEqual(t, "0743273567", pub.ISBN)
+		helpers.AssertEqual(t, "Paperback", pub.Format)
+		helpers.AssertEqual(t, "English", pub.Language)
+		helpers.AssertEqual(t, "Scribner", pub.Publisher)
+		helpers.AssertEqual(t, "Scribner trade pbk. ed", pub.Edition)
+		helpers.AssertEqual(t, "2004-01-01", pub.EditionPubDate)
+		helpers.AssertEqual(t, 180, pub.NumPages)
+		helpers.AssertEqual(t, "The great Gatsby", pub.Work.Title)
+		helpers.AssertEqual(t, "F. Scott", pub.Work.Author.FirstName)
+		helpers.AssertEqual(t, "Fitzgerald", pub.Work.Author.LastName)
+		helpers.AssertEqual(t, "1896-01-01", pub.Work.Author.DateOfBirth)
+	})
+
+	t.Run("CancelledISBN", func(t *testing.T) {
+		xml := `<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>00000cam a2200000 i 4500</leader>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="z">9780743273560</subfield>
+    </datafield>
+    <datafield tag="020" ind1=" " ind2=" ">
+      <subfield code="a">0743273567</subfield>
+    </datafield>
+    <datafield tag="245" ind1="1" ind2="4">
+      <subfield code="a">The great Gatsby /</subfield>
+    </datafield>
+  </record>
+</collection>
+`
+		records, err := marc.ReadXML(strings.NewReader(xml))
+		helpers.AssertEqual(t, nil, err)
+
+		pub := marc.ToPublication(&records[0])
+		helpers.AssertEqual(t, "", pub.ISBN13)
+		helpers.AssertEqual(t, "0743273567", pub.ISBN)
+		helpers.AssertEqual(t, "The great Gatsby", pub.Work.Title)
+	})
+}
+
+func TestFromPublication(t *testing.T) {
+	pub := &publication.Publication{
+		ID:             7,
+		EditionPubDate: "2004-09-30T00:00:00Z",
+		Format:         "Paperback",
+		ImageURL:       "https://covers.openlibrary.org/b/isbn/9780743273565-L.jpg",
+		ISBN:           "0743273567",
+		ISBN13:         "9780743273565",
+		Language:       "English",
+		NumPages:       180,
+		Publisher:      "Scribner",
+		Work: work.Work{
+			Title: "The Great Gatsby",
+		},
+	}
+	pub.Work.Author.FirstName = "F. Scott"
+	pub.Work.Author.LastName = "Fitzgerald"
+
+	rec := marc.FromPublication(pub)
+	helpers.AssertEqual(t, "7", rec.ControlField("001"))
+	helpers.AssertEqual(t, 40, len(rec.ControlField("008")))
+	helpers.AssertEqual(t, "eng", rec.ControlField("008")[35:38])
+	helpers.AssertEqual(t, "Fitzgerald, F. Scott", rec.Subfield("100", "a"))
+	helpers.AssertEqual(t, "", rec.Subfield("250", "a"))
+
+	got := marc.ToPublication(&rec)
+	helpers.AssertEqual(t, pub.ISBN13, got.ISBN13)
+	helpers.AssertEqual(t, pub.ISBN, got.ISBN)
+	helpers.AssertEqual(t, pub.Format, got.Format)
+	helpers.AssertEqual(t, pub.Language, got.Language)
+	helpers.AssertEqual(t, pub.Publisher, got.Publisher)
+	helpers.AssertEqual(t, pub.NumPages, got.NumPages)
+	helpers.AssertEqual(t, "2004-01-01", got.EditionPubDate)
+	helpers.AssertEqual(t, pub.Work.Title, got.Work.Title)
+	helpers.AssertEqual(t, pub.Work.Author.LastName, got.Work.Author.LastName)
+}
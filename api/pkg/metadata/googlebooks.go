@@ -0,0 +1,104 @@
+package metadata
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// DefaultGoogleBooksURL is the base URL of the Google Books API.
+const DefaultGoogleBooksURL string = "https://www.googleapis.com/books/v1"
+
+// GoogleBooks looks up metadata from the volumes of Google Books. Key is optional.
+type GoogleBooks struct {
+	BaseURL string
+	Key     string
+	Client  *http.Client
+}
+
+type gbVolumes struct {
+	TotalItems int `json:"totalItems"`
+	Items      []struct {
+		VolumeInfo gbVolumeInfo `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+type gbVolumeInfo struct {
+	Title               string   `json:"title"`
+	Subtitle            string   `json:"subtitle"`
+	Authors             []string `json:"authors"`
+	Publisher           string   `json:"publisher"`
+	PublishedDate       string   `json:"publishedDate"`
+	Description         string   `json:"description"`
+	PageCount           int      `json:"pageCount"`
+	Language            string   `json:"language"`
+	IndustryIdentifiers []struct {
+		Type       string `json:"type"`
+		Identifier string `json:"identifier"`
+	} `json:"industryIdentifiers"`
+	ImageLinks struct {
+		SmallThumbnail string `json:"smallThumbnail"`
+		Thumbnail      string `json:"thumbnail"`
+	} `json:"imageLinks"`
+}
+
+// Name returns the name of the provider.
+func (g *GoogleBooks) Name() string {
+	return "googlebooks"
+}
+
+// Lookup searches for the volume with the given ISBN-13, taking the first result.
+func (g *GoogleBooks) Lookup(isbn13 string) (*publication.Publication, error) {
+	query := url.Values{"q": {"isbn:" + isbn13}}
+	if g.Key != "" {
+		query.Set("key", g.Key)
+	}
+
+	base := g.BaseURL
+	if base == "" {
+		base = DefaultGoogleBooksURL
+	}
+
+	var volumes gbVolumes
+	if err := get(g.Client, strings.TrimSuffix(base, "/")+"/volumes?"+query.Encode(), &volumes); err != nil {
+		return nil, err
+	}
+	if volumes.TotalItems == 0 || len(volumes.Items) == 0 {
+		return nil, ErrNotFound
+	}
+
+	info := volumes.Items[0].VolumeInfo
+	pub := &publication.Publication{
+		EditionPubDate: date(info.PublishedDate),
+		ISBN13:         isbn13,
+		Language:       language.Name(info.Language),
+		NumPages:       info.PageCount,
+		Publisher:      info.Publisher,
+	}
+	for _, id := range info.IndustryIdentifiers {
+		if id.Type == "ISBN_10" {
+			pub.ISBN = id.Identifier
+		}
+	}
+
+	image := info.ImageLinks.Thumbnail
+	if image == "" {
+		image = info.ImageLinks.SmallThumbnail
+	}
+	pub.ImageURL = strings.Replace(image, "http://", "https://", 1)
+
+	wk := &pub.Work
+	wk.Title = info.Title
+	if info.Subtitle != "" {
+		wk.Title += ": " + info.Subtitle
+	}
+	wk.Description = importer.PlainText(info.Description)
+	if len(info.Authors) > 0 {
+		wk.Author.FirstName, wk.Author.LastName = importer.SplitName(info.Authors[0])
+	}
+	return pub, nil
+}
@@ -0,0 +1,176 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/isbn"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// ErrNotFound is returned by providers that have no metadata for an ISBN.
+var ErrNotFound = errors.New("no metadata found")
+
+// defaultClient is used by providers that are not given a client of their own.
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Layouts of the dates that providers return, from most to least precise.
+var layouts = []string{
+	"2006-01-02", "January 2, 2006", "Jan 2, 2006", "2 January 2006", "2 Jan 2006",
+	"2006-01", "January 2006", "Jan 2006",
+}
+
+var year = regexp.MustCompile(`\b\d{4}\b`)
+
+// Provider looks up the metadata of a publication by its ISBN-13. The returned publication
+// holds whichever fields of the publication, its work and their author the provider knows.
+type Provider interface {
+	Name() string
+	Lookup(isbn13 string) (*publication.Publication, error)
+}
+
+// Preview is a publication merged from the metadata of one or more providers, yet to be saved.
+type Preview struct {
+	Publication publication.Publication `json:"publication"`
+	Sources     []string                `json:"sources"`
+}
+
+// Service wraps the providers that metadata is fetched from, in order of precedence.
+type Service struct {
+	Providers []Provider
+}
+
+// Enrich looks up the given ISBN with every provider and fills in the fields that pub leaves empty,
+// taking each field from the first provider that has it. Nothing is saved.
+func (s *Service) Enrich(number string, pub *publication.Publication) (*status.Status, *Preview) {
+	number = isbn.Normalize(number)
+	if !isbn.Valid(number) {
+		msg := fmt.Sprintf("%q is not a valid ISBN", number)
+		log.Printf("[Enrich] %s", msg)
+		return status.New(status.UnprocessableEntity, msg), nil
+	}
+
+	preview := &Preview{Publication: *pub, Sources: []string{}}
+	merged := &preview.Publication
+	var err error
+	switch len(number) {
+	case 10:
+		merged.ISBN = number
+		merged.ISBN13, err = isbn.To13(number)
+	default:
+		merged.ISBN13 = number
+		merged.ISBN, err = isbn.To10(number)
+	}
+	if err != nil {
+		// ISBN-13s with the 979 prefix have no ISBN-10.
+		merged.ISBN = ""
+	}
+
+	var failures []string
+	for _, p := range s.Providers {
+		found, err := p.Lookup(merged.ISBN13)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[Enrich] %s: %s", p.Name(), err)
+			failures = append(failures, fmt.Sprintf("%s: %s", p.Name(), err))
+			continue
+		}
+		Merge(merged, found)
+		preview.Sources = append(preview.Sources, p.Name())
+	}
+
+	if len(preview.Sources) == 0 {
+		if len(failures) > 0 {
+			return status.New(status.BadGateway, strings.Join(failures, "; ")), nil
+		}
+		msg := fmt.Sprintf("No metadata found for ISBN %s", merged.ISBN13)
+		log.Printf("[Enrich] %s", msg)
+		return status.New(status.NotFound, msg), nil
+	}
+	return status.New(status.OK, strings.Join(failures, "; ")), preview
+}
+
+// Merge sets every empty field of dst, its work and their author to the matching field of src.
+func Merge(dst *publication.Publication, src *publication.Publication) {
+	fill(&dst.EditionPubDate, src.EditionPubDate)
+	fill(&dst.Format, src.Format)
+	fill(&dst.ImageURL, src.ImageURL)
+	fill(&dst.ISBN, src.ISBN)
+	fill(&dst.ISBN13, src.ISBN13)
+	fill(&dst.Language, src.Language)
+	fill(&dst.Publisher, src.Publisher)
+	fill(&dst.Edition, src.Edition)
+	if dst.NumPages == 0 {
+		dst.NumPages = src.NumPages
+	}
+
+	dw, sw := &dst.Work, &src.Work
+	fill(&dw.Description, sw.Description)
+	fill(&dw.InitialPubDate, sw.InitialPubDate)
+	fill(&dw.OriginalLanguage, sw.OriginalLanguage)
+	fill(&dw.Title, sw.Title)
+	if dw.Series == "" && sw.Series != "" {
+		dw.Series, dw.SeriesIndex = sw.Series, sw.SeriesIndex
+	}
+
+	da, sa := &dw.Author, &sw.Author
+	if da.FirstName == "" && da.LastName == "" {
+		da.FirstName, da.LastName = sa.FirstName, sa.LastName
+	}
+	fill(&da.Gender, sa.Gender)
+	fill(&da.DateOfBirth, sa.DateOfBirth)
+	fill(&da.PlaceOfBirth.City, sa.PlaceOfBirth.City)
+	fill(&da.PlaceOfBirth.Country, sa.PlaceOfBirth.Country)
+	fill(&da.PlaceOfBirth.Region, sa.PlaceOfBirth.Region)
+}
+
+func fill(dst *string, src string) {
+	if *dst == "" {
+		*dst = src
+	}
+}
+
+// get fetches url and decodes its JSON body into v, returning ErrNotFound for 404 responses.
+func get(client *http.Client, url string, v interface{}) error {
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// date converts a date in any of the layouts providers use into YYYY-MM-DD, filling in the
+// first month or day for partial dates and falling back to the first year it contains.
+func date(s string) string {
+	s = strings.TrimSpace(s)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if y := year.FindString(s); y != "" {
+		return y + "-01-01"
+	}
+	return ""
+}
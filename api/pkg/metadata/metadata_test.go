@@ -0,0 +1,174 @@
+package metadata_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+const gatsby = "9780743273565"
+
+// serve returns a server that responds to each path with the given JSON body, and 404 otherwise.
+func serve(t *testing.T, bodies map[string]string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if q := r.URL.Query().Get("q"); q != "" && q != "isbn:"+gatsby {
+			w.Write([]byte(`{"totalItems": 0}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func openLibrary(t *testing.T) *httptest.Server {
+	return serve(t, map[string]string{
+		"/isbn/" + gatsby + ".json": `{
+			"title": "The Great Gatsby",
+			"publishers": ["Scribner"],
+			"publish_date": "September 30, 2004",
+			"number_of_pages": 180,
+			"physical_format": "paperback",
+			"covers": [8432047],
+			"languages": [{"key": "/languages/eng"}],
+			"works": [{"key": "/works/OL468431W"}]
+		}`,
+		"/works/OL468431W.json": `{
+			"title": "The Great Gatsby",
+			"description": {"type": "/type/text", "value": "The story of Jay Gatsby."},
+			"first_publish_date": "1925",
+			"authors": [{"author": {"key": "/authors/OL27349A"}}]
+		}`,
+		"/authors/OL27349A.json": `{"name": "F. Scott Fitzgerald", "birth_date": "24 September 1896"}`,
+	})
+}
+
+func googleBooks(t *testing.T) *httptest.Server {
+	return serve(t, map[string]string{
+		"/volumes": `{
+			"totalItems": 1,
+			"items": [{"volumeInfo": {
+				"title": "The Great Gatsby",
+				"authors": ["F. Scott Fitzgerald"],
+				"publisher": "Simon and Schuster",
+				"publishedDate": "2004-09-30",
+				"description": "<p>A <b>classic</b> of American literature.</p>",
+				"pageCount": 192,
+				"language": "en",
+				"industryIdentifiers": [
+					{"type": "ISBN_10", "identifier": "0743273567"},
+					{"type": "ISBN_13", "identifier": "9780743273565"}
+				],
+				"imageLinks": {"thumbnail": "http://books.google.com/books/content?id=iXn5U2IzVH0C"}
+			}}]
+		}`,
+	})
+}
+
+func TestOpenLibrary(t *testing.T) {
+	srv := openLibrary(t)
+	defer srv.Close()
+	ol := &metadata.OpenLibrary{BaseURL: srv.URL}
+
+	t.Run("Found", func(t *testing.T) {
+		pub, err := ol.Lookup(gatsby)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, "2004-09-30", pub.EditionPubDate)
+		helpers.AssertEqual(t, "Paperback", pub.Format)
+		helpers.AssertEqual(t, "https://covers.openlibrary.org/b/id/8432047-L.jpg", pub.ImageURL)
+		helpers.AssertEqual(t, "English", pub.Language)
+		helpers.AssertEqual(t, 180, pub.NumPages)
+		helpers.AssertEqual(t, "Scribner", pub.Publisher)
+		helpers.AssertEqual(t, "The story of Jay Gatsby.", pub.Work.Description)
+		helpers.AssertEqual(t, "1925-01-01", pub.Work.InitialPubDate)
+		helpers.AssertEqual(t, "Fitzgerald", pub.Work.Author.LastName)
+		helpers.AssertEqual(t, "1896-09-24", pub.Work.Author.DateOfBirth)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := ol.Lookup("9780000000002")
+		helpers.AssertEqual(t, true, errors.Is(err, metadata.ErrNotFound))
+	})
+}
+
+func TestGoogleBooks(t *testing.T) {
+	srv := googleBooks(t)
+	defer srv.Close()
+	gb := &metadata.GoogleBooks{BaseURL: srv.URL}
+
+	t.Run("Found", func(t *testing.T) {
+		pub, err := gb.Lookup(gatsby)
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, "0743273567", pub.ISBN)
+		helpers.AssertEqual(t, "2004-09-30", pub.EditionPubDate)
+		helpers.AssertEqual(t, "English", pub.Language)
+		helpers.AssertEqual(t, 192, pub.NumPages)
+		helpers.AssertEqual(t, "https://books.google.com/books/content?id=iXn5U2IzVH0C", pub.ImageURL)
+		helpers.AssertEqual(t, "A classic of American literature.", pub.Work.Description)
+		helpers.AssertEqual(t, "F. Scott", pub.Work.Author.FirstName)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := gb.Lookup("9780000000002")
+		helpers.AssertEqual(t, true, errors.Is(err, metadata.ErrNotFound))
+	})
+}
+
+func TestEnrich(t *testing.T) {
+	ol, gb := openLibrary(t), googleBooks(t)
+	defer ol.Close()
+	defer gb.Close()
+
+	m := &metadata.Service{Providers: []metadata.Provider{
+		&metadata.OpenLibrary{BaseURL: ol.URL},
+		&metadata.GoogleBooks{BaseURL: gb.URL},
+	}}
+
+	t.Run("Merge", func(t *testing.T) {
+		pub := &publication.Publication{Publisher: "Scribner Paperback Fiction"}
+		gotStatus, preview := m.Enrich("0-7432-7356-7", pub)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, []string{"openlibrary", "googlebooks"}, preview.Sources)
+
+		got := preview.Publication
+		helpers.AssertEqual(t, "0743273567", got.ISBN)
+		helpers.AssertEqual(t, gatsby, got.ISBN13)
+		helpers.AssertEqual(t, "Scribner Paperback Fiction", got.Publisher)
+		helpers.AssertEqual(t, 180, got.NumPages)
+		helpers.AssertEqual(t, "The story of Jay Gatsby.", got.Work.Description)
+		helpers.AssertEqual(t, "1896-09-24", got.Work.Author.DateOfBirth)
+	})
+
+	t.Run("InvalidISBN", func(t *testing.T) {
+		gotStatus, _ := m.Enrich("0743273568", &publication.Publication{})
+		helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		gotStatus, _ := m.Enrich("9780000000002", &publication.Publication{})
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+	})
+
+	t.Run("ProviderDown", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}))
+		defer down.Close()
+
+		m := &metadata.Service{Providers: []metadata.Provider{&metadata.OpenLibrary{BaseURL: down.URL}}}
+		gotStatus, _ := m.Enrich(gatsby, &publication.Publication{})
+		helpers.AssertEqual(t, status.BadGateway, gotStatus.Code())
+	})
+}
@@ -0,0 +1,145 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// DefaultOpenLibraryURL is the base URL of the Open Library API.
+const DefaultOpenLibraryURL string = "https://openlibrary.org"
+
+// openLibraryCoverURL is the URL of a large Open Library cover image, given its id.
+const openLibraryCoverURL string = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+
+// OpenLibrary looks up metadata from the edition, work and author records of Open Library.
+type OpenLibrary struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+type olKey struct {
+	Key string `json:"key"`
+}
+
+// olText is a text field, which Open Library stores either as a string or as a typed value.
+type olText string
+
+func (t *olText) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*t = olText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*t = olText(v.Value)
+	return nil
+}
+
+type olEdition struct {
+	Title          string   `json:"title"`
+	Subtitle       string   `json:"subtitle"`
+	Publishers     []string `json:"publishers"`
+	PublishDate    string   `json:"publish_date"`
+	NumberOfPages  int      `json:"number_of_pages"`
+	PhysicalFormat string   `json:"physical_format"`
+	EditionName    string   `json:"edition_name"`
+	Covers         []int    `json:"covers"`
+	Languages      []olKey  `json:"languages"`
+	Works          []olKey  `json:"works"`
+	Authors        []olKey  `json:"authors"`
+}
+
+type olWork struct {
+	Title            string `json:"title"`
+	Description      olText `json:"description"`
+	FirstPublishDate string `json:"first_publish_date"`
+	Authors          []struct {
+		Author olKey `json:"author"`
+	} `json:"authors"`
+}
+
+type olAuthor struct {
+	Name      string `json:"name"`
+	BirthDate string `json:"birth_date"`
+}
+
+// Name returns the name of the provider.
+func (o *OpenLibrary) Name() string {
+	return "openlibrary"
+}
+
+// Lookup fetches the edition with the given ISBN-13, followed by its work and the work's first author.
+func (o *OpenLibrary) Lookup(isbn13 string) (*publication.Publication, error) {
+	var ed olEdition
+	if err := get(o.Client, o.url("/isbn/%s.json", isbn13), &ed); err != nil {
+		return nil, err
+	}
+
+	pub := &publication.Publication{
+		EditionPubDate: date(ed.PublishDate),
+		Format:         strings.Title(strings.ToLower(ed.PhysicalFormat)),
+		ISBN13:         isbn13,
+		NumPages:       ed.NumberOfPages,
+		Edition:        ed.EditionName,
+	}
+	if len(ed.Publishers) > 0 {
+		pub.Publisher = ed.Publishers[0]
+	}
+	if len(ed.Covers) > 0 && ed.Covers[0] > 0 {
+		pub.ImageURL = fmt.Sprintf(openLibraryCoverURL, ed.Covers[0])
+	}
+	if len(ed.Languages) > 0 {
+		pub.Language = language.Name(strings.TrimPrefix(ed.Languages[0].Key, "/languages/"))
+	}
+
+	wk := &pub.Work
+	wk.Title = ed.Title
+	if ed.Subtitle != "" {
+		wk.Title += ": " + ed.Subtitle
+	}
+
+	authorKey := ""
+	if len(ed.Authors) > 0 {
+		authorKey = ed.Authors[0].Key
+	}
+	if len(ed.Works) > 0 {
+		var w olWork
+		if err := get(o.Client, o.url("%s.json", ed.Works[0].Key), &w); err != nil && err != ErrNotFound {
+			return nil, err
+		}
+		wk.Description = importer.PlainText(string(w.Description))
+		wk.InitialPubDate = date(w.FirstPublishDate)
+		if authorKey == "" && len(w.Authors) > 0 {
+			authorKey = w.Authors[0].Author.Key
+		}
+	}
+
+	if authorKey != "" {
+		var a olAuthor
+		if err := get(o.Client, o.url("%s.json", authorKey), &a); err != nil && err != ErrNotFound {
+			return nil, err
+		}
+		wk.Author.FirstName, wk.Author.LastName = importer.SplitName(a.Name)
+		wk.Author.DateOfBirth = date(a.BirthDate)
+	}
+	return pub, nil
+}
+
+func (o *OpenLibrary) url(format string, a ...interface{}) string {
+	base := o.BaseURL
+	if base == "" {
+		base = DefaultOpenLibraryURL
+	}
+	return strings.TrimSuffix(base, "/") + fmt.Sprintf(format, a...)
+}
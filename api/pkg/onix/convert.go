@@ -0,0 +1,258 @@
+package onix
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/language"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// formats maps product form codes (code list 150) onto publication formats.
+var formats = map[string]string{
+	"BA": "Book",
+	"BB": "Hardcover",
+	"BC": "Paperback",
+	"BH": "Board Book",
+	"EA": "Ebook",
+	"ED": "Ebook",
+	"AC": "Audio CD",
+	"AJ": "Audiobook",
+}
+
+// actions maps notification types onto import actions. Early and advance notifications
+// announce new products, while confirmed and update notifications replace existing ones.
+var actions = map[string]importer.Action{
+	EarlyNotification:    importer.Create,
+	AdvanceNotification:  importer.Create,
+	ConfirmedOnPublished: importer.Update,
+	UpdateNotification:   importer.Update,
+	DeleteNotification:   importer.Delete,
+}
+
+// ReadDir reads every .xml feed file in dir and converts its products into rows, in order of
+// file name, which is the order that feeds are expected to be applied in.
+func ReadDir(dir string) (importer.Rows, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	rows := importer.Rows{}
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		products, err := Read(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rows = append(rows, ToRows(products, filepath.Base(path))...)
+	}
+	return rows, nil
+}
+
+// ToRows converts the given products into rows to be imported, numbering them from 1 within source.
+func ToRows(products []Product, source string) importer.Rows {
+	rows := importer.Rows{}
+	for i := range products {
+		row := ToRow(&products[i])
+		row.Source = source
+		row.Line = i + 1
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ToRow converts the given product into a row whose action follows the product's notification type.
+func ToRow(p *Product) importer.Row {
+	action, ok := actions[p.NotificationType]
+	if !ok {
+		err := fmt.Errorf("record %q: unsupported notification type %q", p.RecordReference, p.NotificationType)
+		return importer.Row{Err: err}
+	}
+	return importer.Row{Action: action, Publication: ToPublication(p)}
+}
+
+// ToPublication maps the product identifiers, title, author, edition, languages, page count,
+// publisher, publication date, description and front cover of the given product onto a publication.
+func ToPublication(p *Product) publication.Publication {
+	var pub publication.Publication
+	wk := &pub.Work
+	au := &wk.Author
+	dd := &p.DescriptiveDetail
+
+	for _, id := range p.ProductIdentifiers {
+		switch id.ProductIDType {
+		case "15":
+			pub.ISBN13 = id.IDValue
+		case "02":
+			pub.ISBN = id.IDValue
+		case "03":
+			if pub.ISBN13 == "" && (strings.HasPrefix(id.IDValue, "978") || strings.HasPrefix(id.IDValue, "979")) {
+				pub.ISBN13 = id.IDValue
+			}
+		}
+	}
+
+	pub.Format = formats[dd.ProductForm]
+	if pub.Format == "" && strings.HasPrefix(dd.ProductForm, "E") {
+		pub.Format = "Ebook"
+	}
+	pub.Edition = strings.TrimSpace(dd.EditionStatement)
+
+	for _, td := range dd.TitleDetails {
+		if td.TitleType != "01" {
+			continue
+		}
+		for _, te := range td.TitleElements {
+			switch te.TitleElementLevel {
+			case "01":
+				wk.Title = te.title()
+			case "02":
+				wk.Series, wk.SeriesIndex = te.title(), te.partNumber()
+			}
+		}
+	}
+	for _, c := range dd.Collections {
+		for _, td := range c.TitleDetails {
+			for _, te := range td.TitleElements {
+				if te.TitleElementLevel == "02" && wk.Series == "" {
+					wk.Series, wk.SeriesIndex = te.title(), te.partNumber()
+				}
+			}
+		}
+	}
+	if wk.Series == "" {
+		wk.SeriesIndex = 0
+	}
+
+	if c := author(dd.Contributors); c != nil {
+		switch {
+		case c.KeyNames != "":
+			au.FirstName, au.LastName = strings.TrimSpace(c.NamesBeforeKey), strings.TrimSpace(c.KeyNames)
+		case c.PersonNameInverted != "":
+			au.FirstName, au.LastName = importer.SplitName(c.PersonNameInverted)
+		default:
+			au.FirstName, au.LastName = importer.SplitName(c.PersonName)
+		}
+		for _, d := range c.ContributorDates {
+			if d.ContributorDateRole == "50" {
+				au.DateOfBirth = d.Date.String()
+			}
+		}
+	}
+
+	for _, l := range dd.Languages {
+		switch l.LanguageRole {
+		case "01":
+			pub.Language = language.Name(l.LanguageCode)
+		case "02":
+			wk.OriginalLanguage = language.Name(l.LanguageCode)
+		}
+	}
+	if wk.OriginalLanguage == "" {
+		wk.OriginalLanguage = pub.Language
+	}
+
+	for _, e := range dd.Extents {
+		if e.ExtentUnit != "03" || (pub.NumPages != 0 && e.ExtentType != "00") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimSpace(e.ExtentValue)); err == nil {
+			pub.NumPages = n
+		}
+	}
+
+	pd := &p.PublishingDetail
+	for _, pb := range pd.Publishers {
+		if pb.PublishingRole == "01" || pub.Publisher == "" {
+			pub.Publisher = strings.TrimSpace(pb.PublisherName)
+		}
+	}
+	if pub.Publisher == "" && len(pd.Imprints) > 0 {
+		pub.Publisher = strings.TrimSpace(pd.Imprints[0].ImprintName)
+	}
+	for _, d := range pd.PublishingDates {
+		if d.PublishingDateRole == "01" {
+			pub.EditionPubDate = d.Date.String()
+		}
+	}
+
+	cd := &p.CollateralDetail
+	for _, tc := range cd.TextContents {
+		if tc.TextType == "03" || (tc.TextType == "02" && wk.Description == "") {
+			wk.Description = importer.PlainText(tc.Text.Value)
+		}
+	}
+	for _, sr := range cd.SupportingResources {
+		if sr.ResourceContentType == "01" && sr.ResourceMode == "03" && len(sr.ResourceVersions) > 0 {
+			pub.ImageURL = strings.TrimSpace(sr.ResourceVersions[0].ResourceLink)
+		}
+	}
+	return pub
+}
+
+// String returns the date as YYYY-MM-DD, filling in the first month or day for partial dates.
+func (d Date) String() string {
+	var digits strings.Builder
+	for _, r := range d.Value {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+
+	s := digits.String()
+	switch {
+	case len(s) >= 8:
+		return s[:4] + "-" + s[4:6] + "-" + s[6:8]
+	case len(s) >= 6:
+		return s[:4] + "-" + s[4:6] + "-01"
+	case len(s) >= 4:
+		return s[:4] + "-01-01"
+	default:
+		return ""
+	}
+}
+
+// author returns the first author (role A01) in sequence, or the first contributor if there is none.
+func author(contributors []Contributor) *Contributor {
+	var first *Contributor
+	for i := range contributors {
+		c := &contributors[i]
+		for _, role := range c.ContributorRoles {
+			if role == "A01" && (first == nil || c.SequenceNumber < first.SequenceNumber) {
+				first = c
+			}
+		}
+	}
+	if first == nil && len(contributors) > 0 {
+		first = &contributors[0]
+	}
+	return first
+}
+
+func (te *TitleElement) title() string {
+	title := strings.TrimSpace(te.TitleText)
+	if title == "" {
+		title = strings.TrimSpace(te.TitlePrefix + " " + te.TitleWithoutPrefix)
+	}
+	if subtitle := strings.TrimSpace(te.Subtitle); subtitle != "" && te.TitleElementLevel == "01" {
+		title += ": " + subtitle
+	}
+	return title
+}
+
+func (te *TitleElement) partNumber() float64 {
+	n, _ := strconv.ParseFloat(strings.TrimSpace(te.PartNumber), 64)
+	return n
+}
@@ -0,0 +1,202 @@
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Namespace is the XML namespace of ONIX 3.0 messages that use reference names.
+const Namespace string = "http://ns.editeur.org/onix/3.0/reference"
+
+// Notification types (code list 1) that a product may carry.
+const (
+	EarlyNotification    = "01"
+	AdvanceNotification  = "02"
+	ConfirmedOnPublished = "03"
+	UpdateNotification   = "04"
+	DeleteNotification   = "05"
+)
+
+// ErrUnsupported is returned for messages that are not ONIX 3.0 with reference names.
+var ErrUnsupported = errors.New("unsupported ONIX message")
+
+// Product is a single ONIX product record. Only the composites that map onto publications,
+// works and authors are decoded; everything else in the record is ignored.
+type Product struct {
+	RecordReference    string              `xml:"RecordReference"`
+	NotificationType   string              `xml:"NotificationType"`
+	ProductIdentifiers []ProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  DescriptiveDetail   `xml:"DescriptiveDetail"`
+	CollateralDetail   CollateralDetail    `xml:"CollateralDetail"`
+	PublishingDetail   PublishingDetail    `xml:"PublishingDetail"`
+}
+
+// ProductIdentifier is an identifier of a product, such as its ISBN-13 (type 15).
+type ProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDValue       string `xml:"IDValue"`
+}
+
+// DescriptiveDetail describes the form and content of a product.
+type DescriptiveDetail struct {
+	ProductForm      string        `xml:"ProductForm"`
+	Collections      []Collection  `xml:"Collection"`
+	TitleDetails     []TitleDetail `xml:"TitleDetail"`
+	Contributors     []Contributor `xml:"Contributor"`
+	EditionStatement string        `xml:"EditionStatement"`
+	Languages        []Language    `xml:"Language"`
+	Extents          []Extent      `xml:"Extent"`
+}
+
+// Collection is a series or set that a product belongs to.
+type Collection struct {
+	CollectionType string        `xml:"CollectionType"`
+	TitleDetails   []TitleDetail `xml:"TitleDetail"`
+}
+
+// TitleDetail is a title of a product or collection, of type 01 for the distinctive title.
+type TitleDetail struct {
+	TitleType     string         `xml:"TitleType"`
+	TitleElements []TitleElement `xml:"TitleElement"`
+}
+
+// TitleElement is a part of a title, at level 01 for the product and 02 for its collection.
+type TitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	PartNumber         string `xml:"PartNumber"`
+	TitleText          string `xml:"TitleText"`
+	TitlePrefix        string `xml:"TitlePrefix"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix"`
+	Subtitle           string `xml:"Subtitle"`
+}
+
+// Contributor is a person or corporate body responsible for the content of a product.
+type Contributor struct {
+	SequenceNumber     int               `xml:"SequenceNumber"`
+	ContributorRoles   []string          `xml:"ContributorRole"`
+	PersonName         string            `xml:"PersonName"`
+	PersonNameInverted string            `xml:"PersonNameInverted"`
+	NamesBeforeKey     string            `xml:"NamesBeforeKey"`
+	KeyNames           string            `xml:"KeyNames"`
+	ContributorDates   []ContributorDate `xml:"ContributorDate"`
+}
+
+// ContributorDate is a date associated with a contributor, of role 50 for their date of birth.
+type ContributorDate struct {
+	ContributorDateRole string `xml:"ContributorDateRole"`
+	Date                Date   `xml:"Date"`
+}
+
+// Language is a language of a product, of role 01 for the language of the text and 02 for
+// the original language of a translation.
+type Language struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"`
+}
+
+// Extent is a measure of the size of a product, such as its page count (type 00, unit 03).
+type Extent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue string `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+// CollateralDetail holds the descriptive texts and resources that promote a product.
+type CollateralDetail struct {
+	TextContents        []TextContent        `xml:"TextContent"`
+	SupportingResources []SupportingResource `xml:"SupportingResource"`
+}
+
+// TextContent is a text about a product, of type 03 for its description.
+type TextContent struct {
+	TextType string `xml:"TextType"`
+	Text     Text   `xml:"Text"`
+}
+
+// Text is a block of text, which may hold XHTML markup.
+type Text struct {
+	Format string `xml:"textformat,attr"`
+	Value  string `xml:",innerxml"`
+}
+
+// SupportingResource is a resource about a product, of content type 01 for its front cover.
+type SupportingResource struct {
+	ResourceContentType string            `xml:"ResourceContentType"`
+	ResourceMode        string            `xml:"ResourceMode"`
+	ResourceVersions    []ResourceVersion `xml:"ResourceVersion"`
+}
+
+// ResourceVersion is a version of a supporting resource and where it can be found.
+type ResourceVersion struct {
+	ResourceForm string `xml:"ResourceForm"`
+	ResourceLink string `xml:"ResourceLink"`
+}
+
+// PublishingDetail describes who published a product and when.
+type PublishingDetail struct {
+	Imprints        []Imprint        `xml:"Imprint"`
+	Publishers      []Publisher      `xml:"Publisher"`
+	PublishingDates []PublishingDate `xml:"PublishingDate"`
+}
+
+// Imprint is the brand under which a product is published.
+type Imprint struct {
+	ImprintName string `xml:"ImprintName"`
+}
+
+// Publisher is a publisher of a product, of role 01 for the main publisher.
+type Publisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+// PublishingDate is a date in the publishing history of a product, of role 01 for its publication date.
+type PublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               Date   `xml:"Date"`
+}
+
+// Date is an ONIX date, formatted as YYYYMMDD unless its dateformat says otherwise.
+type Date struct {
+	Format string `xml:"dateformat,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// Read reads every product in the ONIX 3.0 message r.
+func Read(r io.Reader) ([]Product, error) {
+	products := []Product{}
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return products, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "ONIXmessage":
+			return nil, fmt.Errorf("%w: short tags are not supported", ErrUnsupported)
+		case "ONIXMessage":
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "release" && !strings.HasPrefix(attr.Value, "3.") {
+					return nil, fmt.Errorf("%w: release %s", ErrUnsupported, attr.Value)
+				}
+			}
+		case "Product":
+			var p Product
+			if err := dec.DecodeElement(&p, &start); err != nil {
+				return nil, err
+			}
+			products = append(products, p)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package onix_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/onix"
+)
+
+const feed = `<?xml version="1.0" encoding="UTF-8"?>
+<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">
+  <Header>
+    <Sender><SenderName>Scribner</SenderName></Sender>
+    <SentDateTime>20200101</SentDateTime>
+  </Header>
+  <Product>
+    <RecordReference>com.scribner.9780743273565</RecordReference>
+    <NotificationType>03</NotificationType>
+    <ProductIdentifier>
+      <ProductIDType>01</ProductIDType>
+      <IDValue>SCR-0001</IDValue>
+    </ProductIdentifier>
+    <ProductIdentifier>
+      <ProductIDType>15</ProductIDType>
+      <IDValue>9780743273565</IDValue>
+    </ProductIdentifier>
+    <DescriptiveDetail>
+      <ProductComposition>00</ProductComposition>
+      <ProductForm>BC</ProductForm>
+      <TitleDetail>
+        <TitleType>01</TitleType>
+        <TitleElement>
+          <TitleElementLevel>01</TitleElementLevel>
+          <TitlePrefix>The</TitlePrefix>
+          <TitleWithoutPrefix>Great Gatsby</TitleWithoutPrefix>
+        </TitleElement>
+      </TitleDetail>
+      <Contributor>
+        <SequenceNumber>2</SequenceNumber>
+        <ContributorRole>A15</ContributorRole>
+        <PersonName>Matthew J. Bruccoli</PersonName>
+      </Contributor>
+      <Contributor>
+        <SequenceNumber>1</SequenceNumber>
+        <ContributorRole>A01</ContributorRole>
+        <NamesBeforeKey>F. Scott</NamesBeforeKey>
+        <KeyNames>Fitzgerald</KeyNames>
+        <ContributorDate>
+          <ContributorDateRole>50</ContributorDateRole>
+          <Date>18960924</Date>
+        </ContributorDate>
+      </Contributor>
+      <EditionStatement>Scribner trade paperback edition</EditionStatement>
+      <Language>
+        <LanguageRole>01</LanguageRole>
+        <LanguageCode>eng</LanguageCode>
+      </Language>
+      <Extent>
+        <ExtentType>11</ExtentType>
+        <ExtentValue>192</ExtentValue>
+        <ExtentUnit>03</ExtentUnit>
+      </Extent>
+      <Extent>
+        <ExtentType>00</ExtentType>
+        <ExtentValue>180</ExtentValue>
+        <ExtentUnit>03</ExtentUnit>
+      </Extent>
+    </DescriptiveDetail>
+    <CollateralDetail>
+      <TextContent>
+        <TextType>03</TextType>
+        <ContentAudience>00</ContentAudience>
+        <Text textformat="05"><p>The story of the <em>fabulously</em> wealthy Jay Gatsby &amp; his love.</p></Text>
+      </TextContent>
+      <SupportingResource>
+        <ResourceContentType>01</ResourceContentType>
+        <ContentAudience>00</ContentAudience>
+        <ResourceMode>03</ResourceMode>
+        <ResourceVersion>
+          <ResourceForm>02</ResourceForm>
+          <ResourceLink>https://example.com/covers/9780743273565.jpg</ResourceLink>
+        </ResourceVersion>
+      </SupportingResource>
+    </CollateralDetail>
+    <PublishingDetail>
+      <Imprint><ImprintName>Scribner</ImprintName></Imprint>
+      <Publisher>
+        <PublishingRole>01</PublishingRole>
+        <PublisherName>Simon &amp; Schuster</PublisherName>
+      </Publisher>
+      <PublishingDate>
+        <PublishingDateRole>01</PublishingDateRole>
+        <Date dateformat="00">20040930</Date>
+      </PublishingDate>
+    </PublishingDetail>
+  </Product>
+  <Product>
+    <RecordReference>com.scribner.9780684801223</RecordReference>
+    <NotificationType>05</NotificationType>
+    <ProductIdentifier>
+      <ProductIDType>15</ProductIDType>
+      <IDValue>9780684801223</IDValue>
+    </ProductIdentifier>
+  </Product>
+  <Product>
+    <RecordReference>com.scribner.test</RecordReference>
+    <NotificationType>88</NotificationType>
+  </Product>
+</ONIXMessage>
+`
+
+func TestRead(t *testing.T) {
+	t.Run("Products", func(t *testing.T) {
+		products, err := onix.Read(strings.NewReader(feed))
+		helpers.AssertEqual(t, nil, err)
+		helpers.AssertEqual(t, 3, len(products))
+		helpers.AssertEqual(t, "com.scribner.9780743273565", products[0].RecordReference)
+		helpers.AssertEqual(t, onix.DeleteNotification, products[1].NotificationType)
+	})
+
+	t.Run("ShortTags", func(t *testing.T) {
+		_, err := onix.Read(strings.NewReader(`<ONIXmessage release="3.0"><product></product></ONIXmessage>`))
+		helpers.AssertEqual(t, true, errors.Is(err, onix.ErrUnsupported))
+	})
+
+	t.Run("Release2", func(t *testing.T) {
+		_, err := onix.Read(strings.NewReader(`<ONIXMessage release="2.1"><Product></Product></ONIXMessage>`))
+		helpers.AssertEqual(t, true, errors.Is(err, onix.ErrUnsupported))
+	})
+}
+
+func TestToRows(t *testing.T) {
+	products, err := onix.Read(strings.NewReader(feed))
+	helpers.AssertEqual(t, nil, err)
+	rows := onix.ToRows(products, "feed.xml")
+	helpers.AssertEqual(t, 3, len(rows))
+
+	t.Run("Update", func(t *testing.T) {
+		row := rows[0]
+		pub := row.Publication
+		helpers.AssertEqual(t, nil, row.Err)
+		helpers.AssertEqual(t, "feed.xml", row.Source)
+		helpers.AssertEqual(t, 1, row.Line)
+		helpers.AssertEqual(t, importer.Update, row.Action)
+		helpers.AssertEqual(t, "9780743273565", pub.ISBN13)
+		helpers.AssertEqual(t, "Paperback", pub.Format)
+		helpers.AssertEqual(t, "Scribner trade paperback edition", pub.Edition)
+		helpers.AssertEqual(t, "English", pub.Language)
+		helpers.AssertEqual(t, 180, pub.NumPages)
+		helpers.AssertEqual(t, "Simon & Schuster", pub.Publisher)
+		helpers.AssertEqual(t, "2004-09-30", pub.EditionPubDate)
+		helpers.AssertEqual(t, "https://example.com/covers/9780743273565.jpg", pub.ImageURL)
+		helpers.AssertEqual(t, "The Great Gatsby", pub.Work.Title)
+		helpers.AssertEqual(t, "English", pub.Work.OriginalLanguage)
+		helpers.AssertEqual(t, "The story of the fabulously wealthy Jay Gatsby & his love.", pub.Work.Description)
+		helpers.AssertEqual(t, "F. Scott", pub.Work.Author.FirstName)
+		helpers.AssertEqual(t, "Fitzgerald", pub.Work.Author.LastName)
+		helpers.AssertEqual(t, "1896-09-24", pub.Work.Author.DateOfBirth)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		row := rows[1]
+		helpers.AssertEqual(t, nil, row.Err)
+		helpers.AssertEqual(t, importer.Delete, row.Action)
+		helpers.AssertEqual(t, "9780684801223", row.Publication.ISBN13)
+	})
+
+	t.Run("UnsupportedNotificationType", func(t *testing.T) {
+		row := rows[2]
+		helpers.AssertEqual(t, true, row.Err != nil)
+		helpers.AssertEqual(t, 3, row.Line)
+	})
+}
+
+func TestReadDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "onix")
+	helpers.AssertEqual(t, nil, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"20200102.xml", "20200101.xml"} {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(feed), 0644)
+		helpers.AssertEqual(t, nil, err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "README.txt"), []byte("not a feed"), 0644)
+	helpers.AssertEqual(t, nil, err)
+
+	rows, err := onix.ReadDir(dir)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, 6, len(rows))
+	helpers.AssertEqual(t, "20200101.xml", rows[0].Source)
+	helpers.AssertEqual(t, "20200102.xml", rows[3].Source)
+}
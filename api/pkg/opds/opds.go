@@ -0,0 +1,262 @@
+package opds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+// Media types of OPDS catalog documents.
+const (
+	NavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	OpenSearchType  = "application/opensearchdescription+xml"
+)
+
+// Link relations defined by OPDS.
+const (
+	RelAcquisitionBorrow = "http://opds-spec.org/acquisition/borrow"
+	RelImage             = "http://opds-spec.org/image"
+	RelThumbnail         = "http://opds-spec.org/image/thumbnail"
+)
+
+// PageSize is the number of entries in each page of a feed.
+const PageSize int = 25
+
+// borrowURL is where publications can be borrowed, since the catalogue does not hold the books themselves.
+const borrowURL string = "https://openlibrary.org/isbn/%s"
+
+// Feed is an OPDS catalog feed: an Atom feed whose entries are either links to other feeds
+// (a navigation feed) or publications (an acquisition feed).
+type Feed struct {
+	XMLName      xml.Name `xml:"feed"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	XmlnsDC      string   `xml:"xmlns:dc,attr"`
+	XmlnsOPDS    string   `xml:"xmlns:opds,attr"`
+	XmlnsSearch  string   `xml:"xmlns:opensearch,attr"`
+	ID           string   `xml:"id"`
+	Title        string   `xml:"title"`
+	Updated      string   `xml:"updated"`
+	ItemsPerPage int      `xml:"opensearch:itemsPerPage,omitempty"`
+	Links        []Link   `xml:"link"`
+	Entries      []Entry  `xml:"entry"`
+
+	kind string
+	self *url.URL
+}
+
+// Entry is a single entry of a feed.
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    string     `xml:"updated"`
+	Authors    []Author   `xml:"author"`
+	Identifier string     `xml:"dc:identifier,omitempty"`
+	Language   string     `xml:"dc:language,omitempty"`
+	Publisher  string     `xml:"dc:publisher,omitempty"`
+	Issued     string     `xml:"dc:issued,omitempty"`
+	Categories []Category `xml:"category"`
+	Summary    *Text      `xml:"summary"`
+	Content    *Text      `xml:"content"`
+	Links      []Link     `xml:"link"`
+}
+
+// Author is the author of an entry.
+type Author struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Category is a subject of an entry.
+type Category struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr,omitempty"`
+}
+
+// Text is an Atom text construct.
+type Text struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Link is an Atom link.
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+// NewFeed returns an empty feed of the given kind, NavigationType or AcquisitionType, served at self.
+// Every feed links to itself, to the root of the catalog at start and to the OpenSearch description at search.
+func NewFeed(kind string, title string, self *url.URL, start string, search string, updated time.Time) *Feed {
+	return &Feed{
+		Xmlns:       "http://www.w3.org/2005/Atom",
+		XmlnsDC:     "http://purl.org/dc/terms/",
+		XmlnsOPDS:   "http://opds-spec.org/2010/catalog",
+		XmlnsSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		ID:          "urn:books:opds:" + self.RequestURI(),
+		Title:       title,
+		Updated:     updated.UTC().Format(time.RFC3339),
+		Links: []Link{
+			{Rel: "self", Href: self.RequestURI(), Type: kind},
+			{Rel: "start", Href: start, Type: NavigationType},
+			{Rel: "search", Href: search, Type: OpenSearchType},
+		},
+		kind: kind,
+		self: self,
+	}
+}
+
+// AddNavigation adds an entry linking to the feed of the given kind at href.
+func (f *Feed) AddNavigation(title string, href string, kind string, content string) {
+	entry := Entry{
+		ID:      "urn:books:opds:" + href,
+		Title:   title,
+		Updated: f.Updated,
+		Links:   []Link{{Rel: "subsection", Href: href, Type: kind}},
+	}
+	if content != "" {
+		entry.Content = &Text{Type: "text", Value: content}
+	}
+	f.Entries = append(f.Entries, entry)
+}
+
+// AddPublication adds an entry for the given publication, with its cover and a link to borrow it.
+func (f *Feed) AddPublication(pub *publication.Publication) {
+	wk := &pub.Work
+	au := &wk.Author
+
+	entry := Entry{
+		ID:         "urn:isbn:" + pub.ISBN13,
+		Title:      wk.Title,
+		Updated:    f.Updated,
+		Identifier: "urn:isbn:" + pub.ISBN13,
+		Language:   pub.Language,
+		Publisher:  pub.Publisher,
+		Issued:     day(pub.EditionPubDate),
+		Links: []Link{
+			{Rel: RelAcquisitionBorrow, Href: fmt.Sprintf(borrowURL, pub.ISBN13), Type: "text/html"},
+			{Rel: "alternate", Href: fmt.Sprintf("/api/publication/%d", pub.ID), Type: "application/json"},
+		},
+	}
+	if name := strings.TrimSpace(au.FirstName + " " + au.LastName); name != "" {
+		entry.Authors = []Author{{Name: name, URI: fmt.Sprintf("/opds/publications?author=%d", au.ID)}}
+	}
+	if wk.Series != "" {
+		label := wk.Series
+		if wk.SeriesIndex > 0 {
+			label += " #" + strconv.FormatFloat(wk.SeriesIndex, 'f', -1, 64)
+		}
+		entry.Categories = []Category{{Term: wk.Series, Label: label}}
+	}
+	if wk.Description != "" {
+		entry.Summary = &Text{Type: "text", Value: wk.Description}
+	}
+	if pub.ImageURL != "" {
+		t := imageType(pub.ImageURL)
+		entry.Links = append(entry.Links,
+			Link{Rel: RelImage, Href: pub.ImageURL, Type: t},
+			Link{Rel: RelThumbnail, Href: pub.ImageURL, Type: t},
+		)
+	}
+	f.Entries = append(f.Entries, entry)
+}
+
+// Paginate adds first, previous and next links to the feed, whose entries are the given page,
+// counted from 1. The page number is kept in the page query parameter of the feed's URL.
+func (f *Feed) Paginate(page int, hasNext bool) {
+	link := func(rel string, n int) {
+		u := *f.self
+		q := u.Query()
+		q.Set("page", strconv.Itoa(n))
+		u.RawQuery = q.Encode()
+		f.Links = append(f.Links, Link{Rel: rel, Href: u.RequestURI(), Type: f.kind})
+	}
+
+	f.ItemsPerPage = PageSize
+	link("first", 1)
+	if page > 1 {
+		link("previous", page-1)
+	}
+	if hasNext {
+		link("next", page+1)
+	}
+}
+
+// Write writes the feed to w as an XML document.
+func (f *Feed) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// OpenSearch is an OpenSearch description document, which tells clients how to search the catalog.
+type OpenSearch struct {
+	XMLName     xml.Name `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName   string   `xml:"ShortName"`
+	Description string   `xml:"Description"`
+	URL         struct {
+		Type     string `xml:"type,attr"`
+		Template string `xml:"template,attr"`
+	} `xml:"Url"`
+}
+
+// WriteOpenSearch writes an OpenSearch description whose results are the acquisition feed at template,
+// in which {searchTerms} is replaced with the search terms.
+func WriteOpenSearch(w io.Writer, name string, template string) error {
+	desc := OpenSearch{ShortName: name, Description: "Search " + name + " by title or author"}
+	desc.URL.Type = AcquisitionType
+	desc.URL.Template = template
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(desc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// imageType guesses the media type of an image from the extension of its URL.
+func imageType(href string) string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "image/jpeg"
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// day returns the date part of a timestamp as stored in the database.
+func day(timestamp string) string {
+	if len(timestamp) > 10 {
+		return timestamp[:10]
+	}
+	return timestamp
+}
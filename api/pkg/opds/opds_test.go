@@ -0,0 +1,126 @@
+package opds_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/opds"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+)
+
+var updated = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// parsed is the subset of a feed that the tests look at, decoded back from its XML.
+type parsed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []opds.Link `xml:"link"`
+	Entries []struct {
+		ID         string      `xml:"id"`
+		Title      string      `xml:"title"`
+		Identifier string      `xml:"http://purl.org/dc/terms/ identifier"`
+		Issued     string      `xml:"http://purl.org/dc/terms/ issued"`
+		Summary    string      `xml:"summary"`
+		Links      []opds.Link `xml:"link"`
+		Category   []struct {
+			Label string `xml:"label,attr"`
+		} `xml:"category"`
+	} `xml:"entry"`
+}
+
+func decode(t *testing.T, feed *opds.Feed) parsed {
+	t.Helper()
+
+	var buf bytes.Buffer
+	helpers.AssertEqual(t, nil, feed.Write(&buf))
+
+	var p parsed
+	helpers.AssertEqual(t, nil, xml.Unmarshal(buf.Bytes(), &p))
+	return p
+}
+
+func links(ls []opds.Link) map[string]string {
+	m := map[string]string{}
+	for _, l := range ls {
+		m[l.Rel] = l.Href
+	}
+	return m
+}
+
+func TestNavigation(t *testing.T) {
+	self, _ := url.Parse("/opds/series?page=2")
+	feed := opds.NewFeed(opds.NavigationType, "Series", self, "/opds", "/opds/opensearch.xml", updated)
+	feed.AddNavigation("Discworld", "/opds/publications?series=Discworld", opds.AcquisitionType, "")
+	feed.Paginate(2, true)
+
+	p := decode(t, feed)
+	helpers.AssertEqual(t, "2020-01-02T03:04:05Z", p.Updated)
+	helpers.AssertEqual(t, 1, len(p.Entries))
+	helpers.AssertEqual(t, "/opds/publications?series=Discworld", p.Entries[0].Links[0].Href)
+	helpers.AssertEqual(t, map[string]string{
+		"self":     "/opds/series?page=2",
+		"start":    "/opds",
+		"search":   "/opds/opensearch.xml",
+		"first":    "/opds/series?page=1",
+		"previous": "/opds/series?page=1",
+		"next":     "/opds/series?page=3",
+	}, links(p.Links))
+}
+
+func TestAcquisition(t *testing.T) {
+	pub := &publication.Publication{
+		ID:             4,
+		EditionPubDate: "2004-09-30T00:00:00Z",
+		ImageURL:       "https://example.com/covers/gatsby.png",
+		ISBN13:         "9780743273565",
+		Work: work.Work{
+			Title:       "The Great Gatsby",
+			Description: "Jay Gatsby & Daisy <Buchanan>",
+			Series:      "Jazz Age",
+			SeriesIndex: 1,
+		},
+	}
+
+	self, _ := url.Parse("/opds/publications?q=gatsby")
+	feed := opds.NewFeed(opds.AcquisitionType, "Search", self, "/opds", "/opds/opensearch.xml", updated)
+	feed.AddPublication(pub)
+	feed.Paginate(1, false)
+
+	p := decode(t, feed)
+	helpers.AssertEqual(t, 1, len(p.Entries))
+
+	entry := p.Entries[0]
+	helpers.AssertEqual(t, "urn:isbn:9780743273565", entry.ID)
+	helpers.AssertEqual(t, "urn:isbn:9780743273565", entry.Identifier)
+	helpers.AssertEqual(t, "2004-09-30", entry.Issued)
+	helpers.AssertEqual(t, "Jay Gatsby & Daisy <Buchanan>", entry.Summary)
+	helpers.AssertEqual(t, "Jazz Age #1", entry.Category[0].Label)
+
+	got := links(entry.Links)
+	helpers.AssertEqual(t, "https://openlibrary.org/isbn/9780743273565", got[opds.RelAcquisitionBorrow])
+	helpers.AssertEqual(t, "https://example.com/covers/gatsby.png", got[opds.RelImage])
+	helpers.AssertEqual(t, "https://example.com/covers/gatsby.png", got[opds.RelThumbnail])
+	helpers.AssertEqual(t, "image/png", entry.Links[2].Type)
+
+	feedLinks := links(p.Links)
+	helpers.AssertEqual(t, "/opds/publications?page=1&q=gatsby", feedLinks["first"])
+	helpers.AssertEqual(t, "", feedLinks["next"])
+}
+
+func TestWriteOpenSearch(t *testing.T) {
+	var buf bytes.Buffer
+	err := opds.WriteOpenSearch(&buf, "Books", "/opds/publications?q={searchTerms}")
+	helpers.AssertEqual(t, nil, err)
+
+	var got opds.OpenSearch
+	helpers.AssertEqual(t, nil, xml.Unmarshal(buf.Bytes(), &got))
+	helpers.AssertEqual(t, "Books", got.ShortName)
+	helpers.AssertEqual(t, opds.AcquisitionType, got.URL.Type)
+	helpers.AssertEqual(t, "/opds/publications?q={searchTerms}", got.URL.Template)
+}
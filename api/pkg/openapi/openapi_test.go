@@ -0,0 +1,80 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/openapi"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+)
+
+type base struct {
+	Created time.Time `json:"created"`
+}
+
+type node struct {
+	base
+	Name     string            `json:"name"`
+	Weight   float64           `json:"weight,omitempty"`
+	Hidden   string            `json:"-"`
+	Children []*node           `json:"children"`
+	Labels   map[string]string `json:"labels"`
+	private  int
+}
+
+func TestSchemaOf(t *testing.T) {
+	d := openapi.New("Test", "", "1.0.0")
+
+	t.Run("Ref", func(t *testing.T) {
+		helpers.AssertEqual(t, &openapi.Schema{Ref: "#/components/schemas/node"}, d.SchemaOf(node{}))
+		helpers.AssertEqual(t, &openapi.Schema{Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/node"}}, d.SchemaOf([]node{}))
+	})
+
+	t.Run("Properties", func(t *testing.T) {
+		want := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{
+			"created":  {Type: "string", Format: "date-time"},
+			"name":     {Type: "string"},
+			"weight":   {Type: "number"},
+			"children": {Type: "array", Items: &openapi.Schema{Ref: "#/components/schemas/node"}},
+			"labels":   {Type: "object", AdditionalProperties: &openapi.Schema{Type: "string"}},
+		}}
+		helpers.AssertEqual(t, want, d.Components.Schemas["node"])
+	})
+
+	t.Run("Named", func(t *testing.T) {
+		helpers.AssertEqual(t, &openapi.Schema{Ref: "#/components/schemas/Base"}, d.SchemaNamed("Base", base{}))
+		helpers.AssertEqual(t, &openapi.Schema{Ref: "#/components/schemas/Base"}, d.SchemaOf(base{}))
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		d.SchemaOf(publication.Publication{})
+		for _, name := range []string{"Publication", "Work", "Author", "Location"} {
+			if d.Components.Schemas[name] == nil {
+				t.Errorf("%s is not in the components", name)
+			}
+		}
+		pub := d.Components.Schemas["Publication"]
+		helpers.AssertEqual(t, &openapi.Schema{Type: "string"}, pub.Properties["isbn13"])
+		helpers.AssertEqual(t, &openapi.Schema{Type: "integer", Format: "int32"}, pub.Properties["numPages"])
+		helpers.AssertEqual(t, &openapi.Schema{Ref: "#/components/schemas/Work"}, pub.Properties["work"])
+	})
+}
+
+func TestAdd(t *testing.T) {
+	d := openapi.New("Test", "", "1.0.0")
+	op := &openapi.Operation{Summary: "Retrieves a thing."}
+	d.Add("GET", "/thing/{id}", op)
+
+	helpers.AssertEqual(t, op, d.Operation("get", "/thing/{id}"))
+	helpers.AssertEqual(t, (*openapi.Operation)(nil), d.Operation("POST", "/thing/{id}"))
+
+	bytes, err := json.Marshal(d)
+	helpers.AssertEqual(t, nil, err)
+
+	var got map[string]interface{}
+	helpers.AssertEqual(t, nil, json.Unmarshal(bytes, &got))
+	helpers.AssertEqual(t, openapi.Version, got["openapi"])
+	helpers.AssertEqual(t, "Retrieves a thing.", got["paths"].(map[string]interface{})["/thing/{id}"].(map[string]interface{})["get"].(map[string]interface{})["summary"])
+}
@@ -0,0 +1,210 @@
+// Package patch implements JSON Merge Patch, as described by RFC 7396, for the entries of the catalogue.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// A Field describes a field of an entry that can be patched.
+type Field struct {
+	// Column is the column that the field is stored in.
+	Column string
+
+	// Required is set for fields that must always have a value, so that they cannot be removed or emptied.
+	Required bool
+
+	// Embedded is set for fields holding another entry, such as a work's author. A patch either refers to the
+	// entry by its id or describes a new one, so the entry is replaced as a whole rather than merged.
+	Embedded bool
+}
+
+// Fields maps the names of the fields of an entry that can be patched onto their descriptions.
+type Fields map[string]Field
+
+// Select returns the values of the columns of the fields with the given names, out of values, which maps every
+// column onto its value. Without names, it returns the values that are not empty instead, for callers that cannot
+// tell an empty field from one that was not given, such as those given a struct.
+func (f Fields) Select(values map[string]interface{}, names ...string) map[string]interface{} {
+	selected := map[string]interface{}{}
+	if len(names) == 0 {
+		for column, value := range values {
+			if !reflect.ValueOf(value).IsZero() {
+				selected[column] = value
+			}
+		}
+		return selected
+	}
+	for _, name := range names {
+		if field, ok := f[name]; ok {
+			selected[field.Column] = values[field.Column]
+		}
+	}
+	return selected
+}
+
+// A Patch is a JSON Merge Patch: it sets each field it names to the given value, or removes the field where the
+// value is null, and patches the fields of objects in the same way.
+type Patch map[string]interface{}
+
+// Decode reads a patch from r, which must hold a JSON object.
+func Decode(r io.Reader) (Patch, error) {
+	var p Patch
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("A merge patch must be a JSON object")
+	}
+	return p, nil
+}
+
+// From returns the patch that sets every field of v, an entry, to its current value.
+func From(v interface{}, fields Fields) (Patch, error) {
+	doc, err := toMap(v)
+	if err != nil {
+		return nil, err
+	}
+
+	p := Patch{}
+	for name := range fields {
+		if value, ok := doc[name]; ok {
+			p[name] = value
+		}
+	}
+	return p, nil
+}
+
+// Names returns the names of the fields set or removed by the receiver, in alphabetical order.
+func (p Patch) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check returns an error if the receiver names a field that cannot be patched, or removes or empties a required one.
+func (p Patch) Check(fields Fields) error {
+	for _, name := range p.Names() {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("Field %q cannot be patched", name)
+		}
+		if field.Required && empty(p[name]) {
+			return fmt.Errorf("Field %q is required", name)
+		}
+	}
+	return nil
+}
+
+// Apply patches v, a pointer to an entry, with the receiver. v is left as it was if the result does not fit it,
+// e.g. because a value has the wrong type or an embedded entry has a field it does not know.
+func (p Patch) Apply(v interface{}, fields Fields) error {
+	doc, err := toMap(v)
+	if err != nil {
+		return err
+	}
+	for name, field := range fields {
+		if _, ok := p[name]; ok && field.Embedded {
+			delete(doc, name)
+		}
+	}
+
+	merged, err := json.Marshal(merge(doc, map[string]interface{}(p)))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	patched := reflect.New(reflect.TypeOf(v).Elem())
+	if err := dec.Decode(patched.Interface()); err != nil {
+		return err
+	}
+	reflect.ValueOf(v).Elem().Set(patched.Elem())
+	return nil
+}
+
+// Assignments returns the assignments of the SET clause of an UPDATE statement that sets each column of values to
+// its value, e.g. "series = 'Dune', series_index = 1", in alphabetical order of column.
+func Assignments(values map[string]interface{}) string {
+	columns := make([]string, 0, len(values))
+	for column := range values {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	assignments := make([]string, len(columns))
+	for i, column := range columns {
+		switch value := values[column].(type) {
+		case string:
+			assignments[i] = fmt.Sprintf("%s = %s", column, pq.QuoteLiteral(value))
+		case float64:
+			assignments[i] = fmt.Sprintf("%s = %g", column, value)
+		default:
+			assignments[i] = fmt.Sprintf("%s = %d", column, value)
+		}
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// merge returns the result of patching target with patch, as described by RFC 7396.
+func merge(target interface{}, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for name, value := range p {
+		if value == nil {
+			delete(t, name)
+		} else {
+			t[name] = merge(t[name], value)
+		}
+	}
+	return t
+}
+
+// toMap returns the fields of the JSON representation of v.
+func toMap(v interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(encoded))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// empty returns whether value removes or empties a field.
+func empty(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case map[string]interface{}:
+		return len(value) == 0
+	}
+	return false
+}
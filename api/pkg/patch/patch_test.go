@@ -0,0 +1,154 @@
+package patch_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
+)
+
+type person struct {
+	ID   int     `json:"id"`
+	Name string  `json:"name"`
+	Born string  `json:"born"`
+	Rank float64 `json:"rank"`
+}
+
+type book struct {
+	ID     int     `json:"id"`
+	Title  string  `json:"title"`
+	Series string  `json:"series"`
+	Index  float64 `json:"index"`
+	Author person  `json:"author"`
+}
+
+var fields = patch.Fields{
+	"title":  {Column: "title", Required: true},
+	"series": {Column: "series"},
+	"index":  {Column: "series_index"},
+	"author": {Column: "author_id", Required: true, Embedded: true},
+}
+
+func decode(t *testing.T, body string) patch.Patch {
+	p, err := patch.Decode(strings.NewReader(body))
+	helpers.AssertEqual(t, nil, err)
+	return p
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Object", func(t *testing.T) {
+		p := decode(t, `{"series": null, "index": 0}`)
+		helpers.AssertEqual(t, []string{"index", "series"}, p.Names())
+	})
+
+	t.Run("NotAnObject", func(t *testing.T) {
+		for _, body := range []string{`null`, `[]`, `"title"`, `{`} {
+			_, err := patch.Decode(strings.NewReader(body))
+			helpers.AssertEqual(t, false, err == nil)
+		}
+	})
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("Patchable", func(t *testing.T) {
+		helpers.AssertEqual(t, nil, decode(t, `{"title": "Emma", "series": null, "index": 0}`).Check(fields))
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		err := decode(t, `{"title": "Emma", "subtitle": "A Novel"}`).Check(fields)
+		helpers.AssertEqual(t, `Field "subtitle" cannot be patched`, err.Error())
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		err := decode(t, `{"id": 2}`).Check(fields)
+		helpers.AssertEqual(t, `Field "id" cannot be patched`, err.Error())
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		for _, body := range []string{`{"title": null}`, `{"title": ""}`, `{"author": {}}`} {
+			err := decode(t, body).Check(fields)
+			helpers.AssertEqual(t, false, err == nil)
+		}
+	})
+}
+
+func TestApply(t *testing.T) {
+	current := func() *book {
+		return &book{ID: 1, Title: "Dune", Series: "Dune", Index: 1, Author: person{ID: 3, Name: "Frank Herbert", Rank: 2}}
+	}
+
+	t.Run("ZeroValues", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, nil, decode(t, `{"series": "", "index": 0}`).Apply(b, fields))
+		want := current()
+		want.Series, want.Index = "", 0
+		helpers.AssertEqual(t, want, b)
+	})
+
+	t.Run("Null", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, nil, decode(t, `{"series": null, "index": null}`).Apply(b, fields))
+		want := current()
+		want.Series, want.Index = "", 0
+		helpers.AssertEqual(t, want, b)
+	})
+
+	t.Run("Untouched", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, nil, decode(t, `{"title": "Children of Dune"}`).Apply(b, fields))
+		want := current()
+		want.Title = "Children of Dune"
+		helpers.AssertEqual(t, want, b)
+	})
+
+	t.Run("EmbeddedIsReplaced", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, nil, decode(t, `{"author": {"name": "Brian Herbert"}}`).Apply(b, fields))
+		want := current()
+		want.Author = person{Name: "Brian Herbert"}
+		helpers.AssertEqual(t, want, b)
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, false, decode(t, `{"index": "first"}`).Apply(b, fields) == nil)
+		helpers.AssertEqual(t, current(), b)
+	})
+
+	t.Run("UnknownEmbeddedField", func(t *testing.T) {
+		b := current()
+		helpers.AssertEqual(t, false, decode(t, `{"author": {"id": 4, "nickname": "Frank"}}`).Apply(b, fields) == nil)
+		helpers.AssertEqual(t, current(), b)
+	})
+}
+
+func TestFrom(t *testing.T) {
+	b := &book{ID: 1, Title: "Dune", Author: person{ID: 3}}
+	p, err := patch.From(b, fields)
+	helpers.AssertEqual(t, nil, err)
+	helpers.AssertEqual(t, []string{"author", "index", "series", "title"}, p.Names())
+
+	patched := &book{ID: 1, Title: "Emma", Series: "Dune", Index: 2, Author: person{ID: 5, Name: "Jane Austen"}}
+	helpers.AssertEqual(t, nil, p.Apply(patched, fields))
+	helpers.AssertEqual(t, b, patched)
+}
+
+func TestSelect(t *testing.T) {
+	values := map[string]interface{}{"title": "Dune", "series": "", "series_index": 0.0, "author_id": 3}
+
+	t.Run("Named", func(t *testing.T) {
+		got := fields.Select(values, "series", "index", "unknown")
+		helpers.AssertEqual(t, map[string]interface{}{"series": "", "series_index": 0.0}, got)
+	})
+
+	t.Run("NotEmpty", func(t *testing.T) {
+		got := fields.Select(values)
+		helpers.AssertEqual(t, map[string]interface{}{"title": "Dune", "author_id": 3}, got)
+	})
+}
+
+func TestAssignments(t *testing.T) {
+	got := patch.Assignments(map[string]interface{}{"title": "L'Étranger", "series_index": 1.5, "num_pages": 0})
+	helpers.AssertEqual(t, "num_pages = 0, series_index = 1.5, title = 'L''Étranger'", got)
+}
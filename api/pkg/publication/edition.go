@@ -0,0 +1,69 @@
+package publication
+
+import (
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// An Edition is a publication of a work, compared with the work's other publications.
+type Edition struct {
+	Publication
+
+	// FirstEdition is set for the publications of the work that were published first, e.g. both the hardcover and
+	// the ebook if they came out on the same day.
+	FirstEdition bool `json:"firstEdition"`
+
+	// Translation is set for publications in a language other than the work's original language.
+	Translation bool `json:"translation"`
+
+	// PageDifference is how many more pages the publication has than the first edition, or fewer if negative. It is
+	// 0 where the page count of either is unknown.
+	PageDifference int `json:"pageDifference"`
+}
+
+// Editions represents a list of editions.
+type Editions []Edition
+
+// GetEditions retrieves the publications of the work matching f.WorkID that match f, as editions compared with every
+// publication of the work, whether it matches f or not.
+func (s *Service) GetEditions(f *Filter) (*status.Status, Editions) {
+	stat, wk := s.WorkService.GetWork(f.WorkID)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+
+	stat, all := s.FilterPublications(&Filter{WorkID: f.WorkID, ByDate: true})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	var firstDate string
+	var firstPages int
+	if len(all) > 0 {
+		firstDate = all[0].EditionPubDate
+	}
+	for _, pub := range all {
+		if pub.EditionPubDate == firstDate && pub.NumPages > 0 {
+			firstPages = pub.NumPages
+			break
+		}
+	}
+
+	stat, pubs := s.FilterPublications(f)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	editions := make(Editions, len(pubs))
+	for i, pub := range pubs {
+		editions[i] = Edition{
+			Publication:  pub,
+			FirstEdition: pub.EditionPubDate == firstDate,
+			Translation: pub.Language != "" && wk.OriginalLanguage != "" &&
+				!strings.EqualFold(pub.Language, wk.OriginalLanguage),
+		}
+		if pub.NumPages > 0 && firstPages > 0 {
+			editions[i].PageDifference = pub.NumPages - firstPages
+		}
+	}
+	return status.New(status.OK, ""), editions
+}
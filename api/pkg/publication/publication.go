@@ -5,25 +5,51 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/andrewzulaybar/books/api/internal/postgres"
 	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/history"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/andrewzulaybar/books/api/pkg/work"
 	"github.com/lib/pq"
 )
 
+// Entity is the name that the changes made to publications are recorded under in the history.
+const Entity string = "publication"
+
 // Columns is the comma-separated list of columns found in the publication table.
-const Columns string = "edition_pub_date, format, image_url, isbn, isbn13, language, num_pages, publisher, work_id"
+const Columns string = "edition_pub_date, format, image_url, isbn, isbn13, language, num_pages, publisher, edition, work_id"
+
+// Fields describes the fields of a publication that can be patched.
+var Fields = patch.Fields{
+	"editionPubDate": {Column: "edition_pub_date", Required: true},
+	"format":         {Column: "format"},
+	"imageUrl":       {Column: "image_url"},
+	"isbn":           {Column: "isbn"},
+	"isbn13":         {Column: "isbn13"},
+	"language":       {Column: "language"},
+	"numPages":       {Column: "num_pages"},
+	"publisher":      {Column: "publisher"},
+	"edition":        {Column: "edition"},
+	"work":           {Column: "work_id", Required: true, Embedded: true},
+}
 
 // Enum constants representing types of SQL statements.
 const (
 	Unknown postgres.Query = iota
 	DeletePublication
+	FilterPublications
+	FindPublication
 	GetPublication
 	GetPublications
+	LastModified
+	LockPublication
 	PatchPublication
 	PostPublication
+	PurgePublications
+	RestorePublication
 )
 
 // Publication represents a specific edition of a work.
@@ -37,12 +63,100 @@ type Publication struct {
 	Language       string    `json:"language"`
 	NumPages       int       `json:"numPages"`
 	Publisher      string    `json:"publisher"`
+	Edition        string    `json:"edition"`
 	Work           work.Work `json:"work"`
 }
 
 // Publications represents a list of publications.
 type Publications []Publication
 
+// Filter restricts the publications retrieved by FilterPublications. Zero-valued fields are ignored.
+// Search matches publications whose title or author's name or alias contains it, ignoring case. Publications
+// are ordered by id, or by edition date if ByDate is set, newest first if Newest is set, and Limit and Offset
+// select a page of them.
+type Filter struct {
+	IDs       []int
+	AuthorID  int
+	WorkID    int
+	WorkIDs   []int
+	Format    string
+	Language  string
+	Publisher string
+	Series    string
+	Genre     string
+	Search    string
+
+	ByDate bool
+	Newest bool
+	Limit  int
+	Offset int
+}
+
+// Where returns the SQL condition matching the receiver's fields, along with its arguments.
+// Deleted publications never match.
+func (f *Filter) Where() (string, []interface{}) {
+	conditions := []string{"publication.deleted_at IS NULL"}
+	args := []interface{}{}
+	add := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(f.IDs) > 0 {
+		add("publication.id = ANY($%d)", pq.Array(f.IDs))
+	}
+	if f.AuthorID != 0 {
+		add("work.author_id = $%d", f.AuthorID)
+	}
+	if f.WorkID != 0 {
+		add("publication.work_id = $%d", f.WorkID)
+	}
+	if len(f.WorkIDs) > 0 {
+		add("publication.work_id = ANY($%d)", pq.Array(f.WorkIDs))
+	}
+	if f.Format != "" {
+		add("publication.format = $%d", f.Format)
+	}
+	if f.Language != "" {
+		add("publication.language = $%d", f.Language)
+	}
+	if f.Publisher != "" {
+		add("publication.publisher = $%d", f.Publisher)
+	}
+	if f.Series != "" {
+		add("work.series = $%d", f.Series)
+	}
+	if f.Genre != "" {
+		add("EXISTS (SELECT 1 FROM genre WHERE genre.work_id = work.id AND genre.name = $%d)", f.Genre)
+	}
+	if f.Search != "" {
+		add(`(work.title ILIKE $%[1]d OR author.first_name || ' ' || author.last_name ILIKE $%[1]d
+                        OR EXISTS (SELECT 1 FROM author_alias WHERE author_alias.author_id = author.id
+                        AND author_alias.first_name || ' ' || author_alias.last_name ILIKE $%[1]d))`,
+			"%"+strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(f.Search)+"%")
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// Page returns the ORDER BY, LIMIT and OFFSET clauses of the receiver.
+func (f *Filter) Page() string {
+	page := "ORDER BY publication.id"
+	if f.ByDate && f.Newest {
+		page = "ORDER BY publication.edition_pub_date DESC, publication.id DESC"
+	} else if f.ByDate {
+		page = "ORDER BY publication.edition_pub_date, publication.id"
+	} else if f.Newest {
+		page += " DESC"
+	}
+	if f.Limit > 0 {
+		page += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	if f.Offset > 0 {
+		page += fmt.Sprintf(" OFFSET %d", f.Offset)
+	}
+	return page
+}
+
 // Service wraps the database and other dependencies.
 type Service struct {
 	DB postgres.DB
@@ -50,18 +164,55 @@ type Service struct {
 	WorkService work.Service
 }
 
+// WithTx returns a copy of the receiver whose statements, and those of its dependencies,
+// are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx), WorkService: *s.WorkService.WithTx(tx)}
+}
+
+// WithActor returns a copy of the receiver whose changes, and those of its dependencies,
+// are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{DB: s.DB.WithActor(actor), WorkService: *s.WorkService.WithActor(actor)}
+}
+
 // Query returns a SQL statement based on the postgres.Query value passed in.
 func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 	switch query {
 	case DeletePublication:
-		return "DELETE FROM publication WHERE id = $1"
+		return "UPDATE publication SET deleted_at = now(), updated_at = now() WHERE id = $1 AND deleted_at IS NULL"
+	case FilterPublications:
+		return fmt.Sprintf(
+			`SELECT publication.id, %s, %s, %s
+                        FROM publication
+                        JOIN work ON publication.work_id=work.id
+                        JOIN author ON work.author_id=author.id
+                        WHERE %s
+                        %s`,
+			Columns,
+			work.Columns,
+			author.Columns,
+			args[0].(string),
+			args[1].(string),
+		)
+	case FindPublication:
+		return fmt.Sprintf(
+			`SELECT publication.id, %s, %s, %s
+                        FROM publication
+                        JOIN work ON publication.work_id=work.id
+                        JOIN author ON work.author_id=author.id
+                        WHERE publication.isbn13 = $1 AND publication.deleted_at IS NULL`,
+			Columns,
+			work.Columns,
+			author.Columns,
+		)
 	case GetPublication:
 		return fmt.Sprintf(
 			`SELECT publication.id, %s, %s, %s
                         FROM publication
                         JOIN work ON publication.work_id=work.id
                         JOIN author ON work.author_id=author.id
-                        WHERE publication.id = $1`,
+                        WHERE publication.id = $1 AND publication.deleted_at IS NULL`,
 			Columns,
 			work.Columns,
 			author.Columns,
@@ -72,68 +223,74 @@ func (s *Service) Query(query postgres.Query, args ...interface{}) string {
                         FROM publication
                         JOIN work ON publication.work_id=work.id
                         JOIN author ON work.author_id=author.id
+                        WHERE publication.deleted_at IS NULL
                         ORDER BY publication.id`,
 			Columns,
 			work.Columns,
 			author.Columns,
 		)
+	case LastModified:
+		return `SELECT max(greatest(publication.updated_at, work.updated_at, author.updated_at))
+                        FROM publication
+                        JOIN work ON publication.work_id=work.id
+                        JOIN author ON work.author_id=author.id
+                        WHERE cardinality($1::integer[]) = 0 OR publication.id = ANY($1)`
+	case LockPublication:
+		return "SELECT id FROM publication WHERE id = $1 FOR UPDATE"
 	case PatchPublication:
-		var hasUpdate bool
-		query := "UPDATE publication SET "
-		for column, value := range args[0].(map[string]interface{}) {
-			switch value.(type) {
-			case string:
-				if value != "" {
-					query += fmt.Sprintf("%s = '%s',", column, value)
-					hasUpdate = true
-				}
-			case int:
-				if value != 0 {
-					query += fmt.Sprintf("%s = %d,", column, value)
-					hasUpdate = true
-				}
-			}
-		}
-		if hasUpdate {
-			return strings.TrimSuffix(query, ",") + fmt.Sprintf(" WHERE id = $1 RETURNING id, %s", Columns)
+		values := args[0].(map[string]interface{})
+		if len(values) == 0 {
+			return ""
 		}
-		return ""
+		return fmt.Sprintf(
+			"UPDATE publication SET %s, updated_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id, %s",
+			patch.Assignments(values),
+			Columns,
+		)
 	case PostPublication:
 		return fmt.Sprintf(
 			`INSERT INTO publication (%s)
-                        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+                        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
                         RETURNING id, %s`,
 			Columns,
 			Columns,
 		)
+	case PurgePublications:
+		return "DELETE FROM publication WHERE deleted_at < $1"
+	case RestorePublication:
+		// The publication's work, and the work's author, are restored with it if they have been deleted since,
+		// so that it is never left without them.
+		return `WITH restored AS (
+                            UPDATE publication SET deleted_at = NULL, updated_at = now()
+                            WHERE id = $1 AND deleted_at IS NOT NULL
+                            RETURNING work_id
+                        ), works AS (
+                            UPDATE work SET deleted_at = NULL, updated_at = now()
+                            FROM restored
+                            WHERE work.id = restored.work_id AND work.deleted_at IS NOT NULL
+                        ), authors AS (
+                            UPDATE author SET deleted_at = NULL, updated_at = now()
+                            FROM restored, work
+                            WHERE work.id = restored.work_id AND author.id = work.author_id
+                            AND author.deleted_at IS NOT NULL
+                        )
+                        SELECT count(*) FROM restored`
 	default:
 		return ""
 	}
 }
 
-// DeletePublication removes the entry in the publication table matching the given id.
+// DeletePublication marks the entry in the publication table matching the given id as deleted.
+// It is kept until it is purged, and can be restored in the meantime.
 func (s *Service) DeletePublication(id int) *status.Status {
-	db := s.DB
-	deletePublication := s.Query(DeletePublication)
-
-	res, err := db.Exec(deletePublication, id)
-	if err != nil {
-		log.Printf("[DeletePublication] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-
-	numDeleted, err := res.RowsAffected()
-	if err != nil {
-		log.Printf("[DeletePublication] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-	if numDeleted == 0 {
-		msg := fmt.Sprintf("Publication with id = %d does not exist", id)
-		log.Printf("[DeletePublication] %s", msg)
-		return status.New(status.OK, msg)
-	}
-
-	return status.New(status.NoContent, "")
+	return s.Locked(id, func(s *Service) *status.Status {
+		_, before := s.GetPublication(id)
+		stat := s.deletePublication(id)
+		if stat.Code() != status.NoContent {
+			return stat
+		}
+		return s.record(history.Delete, id, before, nil, stat)
+	})
 }
 
 // DeletePublications removes the entries in the publication table matching the given ids.
@@ -153,6 +310,69 @@ func (s *Service) DeletePublications(ids []int) (*status.Status, []int) {
 	return status.New(status.NoContent, ""), nil
 }
 
+// FilterPublications retrieves the publications matching the given filter from the database.
+func (s *Service) FilterPublications(f *Filter) (*status.Status, Publications) {
+	publications := Publications{}
+	stat := s.StreamPublications(f, func(pub *Publication) error {
+		publications = append(publications, *pub)
+		return nil
+	})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	return stat, publications
+}
+
+// FindPublication retrieves the publication from the database matching the given isbn13.
+func (s *Service) FindPublication(isbn13 string) (*status.Status, *Publication) {
+	db := s.DB
+	findPublication := s.Query(FindPublication)
+
+	row := db.QueryRow(findPublication, isbn13)
+	pub, err := s.getPublication(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("Publication ('%s') does not exist", isbn13)
+			log.Printf("[FindPublication] %s", msg)
+			return status.Newf(status.NotFound, msg), nil
+		}
+		log.Printf("[FindPublication] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), pub
+}
+
+// StreamPublications calls fn with each publication matching the given filter, in order of id,
+// scanning one row at a time instead of loading the whole result set. It stops at the first error fn returns.
+func (s *Service) StreamPublications(f *Filter, fn func(*Publication) error) *status.Status {
+	db := s.DB
+	where, args := f.Where()
+	filterPublications := s.Query(FilterPublications, where, f.Page())
+
+	rows, err := db.Query(filterPublications, args...)
+	if err != nil {
+		log.Printf("[StreamPublications] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pub, err := s.getPublication(rows)
+		if err == nil {
+			err = fn(pub)
+		}
+		if err != nil {
+			log.Printf("[StreamPublications] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[StreamPublications] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.OK, "")
+}
+
 // GetPublication retrieves the publication from the database matching the given ID.
 func (s *Service) GetPublication(id int) (*status.Status, *Publication) {
 	db := s.DB
@@ -195,8 +415,197 @@ func (s *Service) GetPublications() (*status.Status, Publications) {
 	return status.New(status.OK, ""), publications
 }
 
-// PatchPublication updates the entry in the database matching pub.id with the given attributes.
-func (s *Service) PatchPublication(pub *Publication) (*status.Status, *Publication) {
+// LastModified returns when the publications matching the given ids, or every publication if none are given,
+// or their works or authors were last modified.
+func (s *Service) LastModified(ids []int) (*status.Status, time.Time) {
+	db := s.DB
+	lastModified := s.Query(LastModified)
+
+	if ids == nil {
+		ids = []int{}
+	}
+	var modified sql.NullTime
+	if err := db.QueryRow(lastModified, pq.Array(ids)).Scan(&modified); err != nil {
+		log.Printf("[LastModified] %s", err)
+		return status.New(status.InternalServerError, err.Error()), time.Time{}
+	}
+	return status.New(status.OK, ""), modified.Time
+}
+
+// Locked calls fn with a copy of the receiver whose statements are executed inside a transaction in which the
+// publication matching the given id is locked, so that it cannot be changed by anyone else until fn returns. The
+// transaction is committed if fn succeeds and rolled back otherwise.
+func (s *Service) Locked(id int, fn func(*Service) *status.Status) *status.Status {
+	return s.Transaction(func(s *Service) *status.Status {
+		if _, err := s.DB.Exec(s.Query(LockPublication), id); err != nil {
+			log.Printf("[Locked] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+		return fn(s)
+	})
+}
+
+// PatchPublication updates the entry in the database matching pub.id with the given attributes. If the names of
+// fields are given, exactly those fields are updated, even to empty values; otherwise, only the attributes that are
+// not empty are.
+func (s *Service) PatchPublication(pub *Publication, fields ...string) (*status.Status, *Publication) {
+	var patched *Publication
+	stat := s.Locked(pub.ID, func(s *Service) *status.Status {
+		var stat *status.Status
+		_, before := s.GetPublication(pub.ID)
+		if stat, patched = s.patchPublication(pub, fields); patched == nil || stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Patch, pub.ID, before, patched, stat)
+	})
+	return stat, patched
+}
+
+// PostPublication creates an entry in the publication table with the given attributes.
+func (s *Service) PostPublication(pub *Publication) (*status.Status, *Publication) {
+	var posted *Publication
+	stat := s.Transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, posted = s.postPublication(pub); stat.Code() != status.Created {
+			return stat
+		}
+		return s.record(history.Post, posted.ID, nil, posted, stat)
+	})
+	return stat, posted
+}
+
+// PurgePublications permanently removes the publications deleted before the given time, along with the shelf
+// entries referring to them, and returns how many publications were removed.
+func (s *Service) PurgePublications(before time.Time) (*status.Status, int) {
+	db := s.DB
+	purgePublications := s.Query(PurgePublications)
+
+	res, err := db.Exec(purgePublications, before)
+	if err != nil {
+		log.Printf("[PurgePublications] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+
+	numPurged, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[PurgePublications] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+	return status.New(status.OK, ""), int(numPurged)
+}
+
+// RestorePublication restores the deleted publication matching the given id.
+func (s *Service) RestorePublication(id int) (*status.Status, *Publication) {
+	var restored *Publication
+	stat := s.Transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, restored = s.restorePublication(id); stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Restore, id, nil, restored, stat)
+	})
+	return stat, restored
+}
+
+// Transaction calls fn with a copy of the receiver whose statements are executed inside a transaction, which is
+// committed if fn succeeds and rolled back otherwise, as described by postgres.DB.Transaction. Changes are recorded
+// in the history in the same transaction.
+func (s *Service) Transaction(fn func(*Service) *status.Status) *status.Status {
+	var stat *status.Status
+	if err := s.DB.Transaction(func(tx *sql.Tx) error {
+		stat = fn(s.WithTx(tx))
+		return stat.Err()
+	}); err != nil && (stat == nil || stat.Err() == nil) {
+		log.Printf("[Transaction] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return stat
+}
+
+func (s *Service) deletePublication(id int) *status.Status {
+	db := s.DB
+	deletePublication := s.Query(DeletePublication)
+
+	res, err := db.Exec(deletePublication, id)
+	if err != nil {
+		log.Printf("[DeletePublication] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	numDeleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[DeletePublication] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if numDeleted == 0 {
+		msg := fmt.Sprintf("Publication with id = %d does not exist", id)
+		log.Printf("[DeletePublication] %s", msg)
+		return status.New(status.OK, msg)
+	}
+
+	return status.New(status.NoContent, "")
+}
+
+func (s *Service) getPublication(row interface {
+	Scan(dest ...interface{}) error
+}) (*Publication, error) {
+	var p Publication
+	var w *work.Work = &p.Work
+	var a *author.Author = &w.Author
+	err := row.Scan(
+		&p.ID,
+		&p.EditionPubDate,
+		&p.Format,
+		&p.ImageURL,
+		&p.ISBN,
+		&p.ISBN13,
+		&p.Language,
+		&p.NumPages,
+		&p.Publisher,
+		&p.Edition,
+		&w.ID,
+		&w.Description,
+		&w.InitialPubDate,
+		&w.OriginalLanguage,
+		&w.Title,
+		&w.Series,
+		&w.SeriesIndex,
+		&a.ID,
+		&a.FirstName,
+		&a.LastName,
+		&a.Gender,
+		&a.DateOfBirth,
+		&a.PlaceOfBirth.ID,
+	)
+	return &p, err
+}
+
+func (s *Service) handleWork(pub *Publication) *status.Status {
+	wk := &pub.Work
+
+	if wk.ID != 0 {
+		return status.New(status.OK, "")
+	}
+
+	stat, work := s.WorkService.PostWork(wk)
+	if stat.Err() != nil {
+		if stat.Code() != status.Conflict {
+			return status.New(stat.Code(), stat.Message())
+		}
+
+		if stat, work = s.WorkService.GetWork(wk.ID); stat.Err() != nil {
+			stat, work = s.WorkService.FindWork(wk.Title, wk.Author.ID)
+			if stat.Err() != nil {
+				return status.New(stat.Code(), stat.Message())
+			}
+		}
+	}
+
+	wk.ID = work.ID
+	return status.New(status.OK, "")
+}
+
+func (s *Service) patchPublication(pub *Publication, fields []string) (*status.Status, *Publication) {
 	if pub.Work != (work.Work{}) {
 		if s := s.handleWork(pub); s.Err() != nil {
 			log.Printf("[PatchPublication] %s", s.Err())
@@ -214,20 +623,24 @@ func (s *Service) PatchPublication(pub *Publication) (*status.Status, *Publicati
 		"language":         pub.Language,
 		"num_pages":        pub.NumPages,
 		"publisher":        pub.Publisher,
+		"edition":          pub.Edition,
 		"work_id":          pub.Work.ID,
 	}
 
-	patchPublication := s.Query(PatchPublication, p)
+	patchPublication := s.Query(PatchPublication, Fields.Select(p, fields...))
 	if patchPublication != "" {
 		var pb Publication
 		row := db.QueryRow(patchPublication, pub.ID)
 		if err := row.Scan(
 			&pb.ID, &pb.EditionPubDate, &pb.Format, &pb.ImageURL, &pb.ISBN, &pb.ISBN13,
-			&pb.Language, &pb.NumPages, &pb.Publisher, &pb.Work.ID,
+			&pb.Language, &pb.NumPages, &pb.Publisher, &pb.Edition, &pb.Work.ID,
 		); err != nil {
 			if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
 				log.Printf("[PatchPublication] %s", err)
 				return status.New(status.Conflict, err.Error()), nil
+			} else if ok && (err.Code.Class() == "22" || err.Code == "23503") {
+				log.Printf("[PatchPublication] %s", err)
+				return status.New(status.UnprocessableEntity, err.Error()), nil
 			}
 			log.Printf("[PatchPublication] %s", err)
 			return status.New(status.InternalServerError, err.Error()), nil
@@ -237,8 +650,7 @@ func (s *Service) PatchPublication(pub *Publication) (*status.Status, *Publicati
 	return status.New(status.OK, "No fields in publication to update"), nil
 }
 
-// PostPublication creates an entry in the publication table with the given attributes.
-func (s *Service) PostPublication(pub *Publication) (*status.Status, *Publication) {
+func (s *Service) postPublication(pub *Publication) (*status.Status, *Publication) {
 	if pub.ID != 0 {
 		if s, l := s.GetPublication(pub.ID); s.Code() == status.OK {
 			msg := fmt.Sprintf("Publication with id = %d already exists", pub.ID)
@@ -268,6 +680,7 @@ func (s *Service) PostPublication(pub *Publication) (*status.Status, *Publicatio
 		pub.Language,
 		pub.NumPages,
 		pub.Publisher,
+		pub.Edition,
 		pub.Work.ID,
 	)
 	if err := row.Scan(
@@ -280,6 +693,7 @@ func (s *Service) PostPublication(pub *Publication) (*status.Status, *Publicatio
 		&pb.Language,
 		&pb.NumPages,
 		&pb.Publisher,
+		&pb.Edition,
 		&pb.Work.ID,
 	); err != nil {
 		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
@@ -292,58 +706,33 @@ func (s *Service) PostPublication(pub *Publication) (*status.Status, *Publicatio
 	return status.New(status.Created, ""), &pb
 }
 
-func (s *Service) getPublication(row interface {
-	Scan(dest ...interface{}) error
-}) (*Publication, error) {
-	var p Publication
-	var w *work.Work = &p.Work
-	var a *author.Author = &w.Author
-	err := row.Scan(
-		&p.ID,
-		&p.EditionPubDate,
-		&p.Format,
-		&p.ImageURL,
-		&p.ISBN,
-		&p.ISBN13,
-		&p.Language,
-		&p.NumPages,
-		&p.Publisher,
-		&w.ID,
-		&w.Description,
-		&w.InitialPubDate,
-		&w.OriginalLanguage,
-		&w.Title,
-		&a.ID,
-		&a.FirstName,
-		&a.LastName,
-		&a.Gender,
-		&a.DateOfBirth,
-		&a.PlaceOfBirth.ID,
-	)
-	return &p, err
-}
-
-func (s *Service) handleWork(pub *Publication) *status.Status {
-	wk := &pub.Work
-
-	if wk.ID != 0 {
-		return status.New(status.OK, "")
+// record records a change made to the publication matching the given id in the history, and returns stat, the status
+// of the change, if it succeeds.
+func (s *Service) record(action string, id int, before *Publication, after *Publication, stat *status.Status) *status.Status {
+	hs := &history.Service{DB: s.DB}
+	if s := hs.PostRevision(Entity, id, action, before, after); s.Err() != nil {
+		return s
 	}
+	return stat
+}
 
-	stat, work := s.WorkService.PostWork(wk)
-	if stat.Err() != nil {
-		if stat.Code() != status.Conflict {
-			return status.New(stat.Code(), stat.Message())
-		}
+func (s *Service) restorePublication(id int) (*status.Status, *Publication) {
+	db := s.DB
+	restorePublication := s.Query(RestorePublication)
 
-		if stat, work = s.WorkService.GetWork(wk.ID); stat.Err() != nil {
-			stat, work = s.WorkService.FindWork(wk.Title, wk.Author.ID)
-			if stat.Err() != nil {
-				return status.New(stat.Code(), stat.Message())
-			}
+	var numRestored int
+	if err := db.QueryRow(restorePublication, id).Scan(&numRestored); err != nil {
+		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+			log.Printf("[RestorePublication] %s", err)
+			return status.New(status.Conflict, err.Error()), nil
 		}
+		log.Printf("[RestorePublication] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
 	}
-
-	wk.ID = work.ID
-	return status.New(status.OK, "")
+	if numRestored == 0 {
+		msg := fmt.Sprintf("Deleted publication with id = %d does not exist", id)
+		log.Printf("[RestorePublication] %s", msg)
+		return status.Newf(status.NotFound, msg), nil
+	}
+	return s.GetPublication(id)
 }
@@ -92,6 +92,101 @@ func TestDeletePublications(t *testing.T) {
 	cleanup()
 }
 
+func TestFilterPublications(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	publications := data.GetPublications(ps)
+	helpers.PostPublications(t, ps, publications)
+
+	t.Run("Publisher", func(t *testing.T) {
+		gotStatus, gotPublications := ps.FilterPublications(&publication.Filter{Publisher: "Scribner"})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 1, len(gotPublications))
+		helpers.AssertEqual(t, publications[3], gotPublications[0])
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		gotStatus, gotPublications := ps.FilterPublications(&publication.Filter{Search: "gAtSbY"})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 1, len(gotPublications))
+		helpers.AssertEqual(t, publications[3], gotPublications[0])
+	})
+
+	t.Run("WorkIDs", func(t *testing.T) {
+		filter := &publication.Filter{WorkIDs: []int{publications[4].Work.ID, publications[1].Work.ID}}
+		gotStatus, gotPublications := ps.FilterPublications(filter)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 2, len(gotPublications))
+		helpers.AssertEqual(t, publications[1], gotPublications[0])
+		helpers.AssertEqual(t, publications[4], gotPublications[1])
+	})
+
+	t.Run("NewestPage", func(t *testing.T) {
+		filter := &publication.Filter{Newest: true, Limit: 2, Offset: 1}
+		gotStatus, gotPublications := ps.FilterPublications(filter)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 2, len(gotPublications))
+		helpers.AssertEqual(t, publications[len(publications)-2], gotPublications[0])
+		helpers.AssertEqual(t, publications[len(publications)-3], gotPublications[1])
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		gotStatus, gotPublications := ps.FilterPublications(&publication.Filter{Series: "Discworld"})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 0, len(gotPublications))
+	})
+
+	cleanup()
+}
+
+func TestGetEditions(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	posted := helpers.PostPublications(t, ps, data.GetPublications(ps))
+
+	first := posted[0]
+	translation := publication.Publication{
+		EditionPubDate: "2020-03-05T00:00:00Z",
+		Format:         "Paperback",
+		ISBN:           "2823616447",
+		ISBN13:         "9782823616447",
+		Language:       "French",
+		NumPages:       first.NumPages + 32,
+		Publisher:      "Editions de l'Olivier",
+		Work:           work.Work{ID: first.Work.ID},
+	}
+	helpers.PostPublications(t, ps, publication.Publications{translation})
+
+	t.Run("Compared", func(t *testing.T) {
+		gotStatus, gotEditions := ps.GetEditions(&publication.Filter{WorkID: first.Work.ID, ByDate: true})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 2, len(gotEditions))
+		helpers.AssertEqual(t, first.ID, gotEditions[0].ID)
+		helpers.AssertEqual(t, true, gotEditions[0].FirstEdition)
+		helpers.AssertEqual(t, false, gotEditions[0].Translation)
+		helpers.AssertEqual(t, false, gotEditions[1].FirstEdition)
+		helpers.AssertEqual(t, true, gotEditions[1].Translation)
+		helpers.AssertEqual(t, 32, gotEditions[1].PageDifference)
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		filter := &publication.Filter{WorkID: first.Work.ID, Language: "French", ByDate: true, Newest: true}
+		gotStatus, gotEditions := ps.GetEditions(filter)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 1, len(gotEditions))
+		helpers.AssertEqual(t, false, gotEditions[0].FirstEdition)
+		helpers.AssertEqual(t, 32, gotEditions[0].PageDifference)
+	})
+
+	t.Run("NonExistentWork", func(t *testing.T) {
+		gotStatus, gotEditions := ps.GetEditions(&publication.Filter{WorkID: -1})
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+		helpers.AssertNil(t, gotEditions)
+	})
+
+	cleanup()
+}
+
 func TestGetPublication(t *testing.T) {
 	services, cleanup := helpers.Setup(t)
 	ps := services.PublicationService
@@ -255,6 +350,19 @@ func TestPatchPublication(t *testing.T) {
 		helpers.AssertEqual(t, tp, gotWork)
 	})
 
+	t.Run("Edition", func(t *testing.T) {
+		tp := helpers.PostPublication(t, ps, &publications[0])
+		defer helpers.DeletePublication(t, ps, tp.ID)
+
+		updates := &publication.Publication{ID: tp.ID, Edition: "Second edition"}
+
+		gotStatus, gotWork := ps.PatchPublication(updates)
+		wantStatus := status.New(status.OK, "")
+		tp.Edition = "Second edition"
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, tp, gotWork)
+	})
+
 	t.Run("Work", func(t *testing.T) {
 		t.Run("ExistingID", func(t *testing.T) {
 			tp := helpers.PostPublication(t, ps, &publications[0])
@@ -457,3 +565,42 @@ func TestPostPublication(t *testing.T) {
 
 	cleanup()
 }
+
+func TestRestorePublication(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	ws := services.WorkService
+	publications := helpers.PostPublications(t, ps, data.GetPublications(ps))
+
+	t.Run("Deleted", func(t *testing.T) {
+		helpers.DeletePublication(t, ps, publications[0].ID)
+		gotStatus, _ := ps.GetPublication(publications[0].ID)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+
+		gotStatus, gotPublication := ps.RestorePublication(publications[0].ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, &publications[0], gotPublication)
+	})
+
+	t.Run("DeletedWork", func(t *testing.T) {
+		helpers.DeleteWork(t, ws, publications[1].Work.ID)
+
+		gotStatus, gotPublication := ps.RestorePublication(publications[1].ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, &publications[1], gotPublication)
+
+		gotStatus, _ = ws.GetWork(publications[1].Work.ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+	})
+
+	t.Run("NotDeleted", func(t *testing.T) {
+		gotStatus, gotPublication := ps.RestorePublication(publications[2].ID)
+		wantStatus := status.Newf(status.NotFound, "Deleted publication with id = %d does not exist", publications[2].ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotPublication)
+	})
+
+	cleanup()
+}
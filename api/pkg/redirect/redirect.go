@@ -0,0 +1,87 @@
+package redirect
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// Columns is the comma-separated list of columns found in the redirect table.
+const Columns string = "entity, old_id, new_id, created_at"
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	DeleteRedirect
+	GetRedirect
+	PostRedirect
+	RepointRedirects
+)
+
+// Service wraps the database and other dependencies.
+type Service struct {
+	DB postgres.DB
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case DeleteRedirect:
+		return "DELETE FROM redirect WHERE entity = $1 AND old_id = $2"
+	case GetRedirect:
+		return "SELECT new_id FROM redirect WHERE entity = $1 AND old_id = $2"
+	case PostRedirect:
+		return fmt.Sprintf(
+			`INSERT INTO redirect (%s)
+                        VALUES ($1, $2, $3, now())
+                        ON CONFLICT (entity, old_id) DO UPDATE
+                        SET new_id = EXCLUDED.new_id, created_at = EXCLUDED.created_at`,
+			Columns,
+		)
+	case RepointRedirects:
+		return "UPDATE redirect SET new_id = $3 WHERE entity = $1 AND new_id = $2"
+	default:
+		return ""
+	}
+}
+
+// GetRedirect retrieves the id of the entry that the entry of the given entity matching id has been merged into.
+func (s *Service) GetRedirect(entity string, id int) (*status.Status, int) {
+	db := s.DB
+	getRedirect := s.Query(GetRedirect)
+
+	var newID int
+	if err := db.QueryRow(getRedirect, entity, id).Scan(&newID); err != nil {
+		if err == sql.ErrNoRows {
+			msg := fmt.Sprintf("There is no redirect for the %s with id = %d", entity, id)
+			return status.New(status.NotFound, msg), 0
+		}
+		log.Printf("[GetRedirect] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+	return status.New(status.OK, ""), newID
+}
+
+// PostRedirect records that the entry of the given entity matching oldID has been merged into the one matching
+// newID. Redirects to oldID are repointed at newID, so that they never need to be followed more than once, and a
+// redirect from newID, left by an earlier merge that has since been undone, is removed.
+func (s *Service) PostRedirect(entity string, oldID int, newID int) *status.Status {
+	db := s.DB
+
+	if _, err := db.Exec(s.Query(DeleteRedirect), entity, newID); err != nil {
+		log.Printf("[PostRedirect] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if _, err := db.Exec(s.Query(RepointRedirects), entity, oldID, newID); err != nil {
+		log.Printf("[PostRedirect] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if _, err := db.Exec(s.Query(PostRedirect), entity, oldID, newID); err != nil {
+		log.Printf("[PostRedirect] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.Created, "")
+}
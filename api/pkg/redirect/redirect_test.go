@@ -0,0 +1,51 @@
+package redirect_test
+
+import (
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+func TestPostRedirect(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	rd := &redirect.Service{DB: services.AuthorService.DB}
+
+	t.Run("Redirect", func(t *testing.T) {
+		helpers.AssertEqual(t, status.New(status.Created, ""), rd.PostRedirect("author", 1, 2))
+
+		gotStatus, gotID := rd.GetRedirect("author", 1)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, 2, gotID)
+	})
+
+	t.Run("PerEntity", func(t *testing.T) {
+		gotStatus, gotID := rd.GetRedirect("work", 1)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+		helpers.AssertEqual(t, 0, gotID)
+	})
+
+	t.Run("Repointed", func(t *testing.T) {
+		helpers.AssertEqual(t, status.New(status.Created, ""), rd.PostRedirect("author", 2, 3))
+
+		for _, id := range []int{1, 2} {
+			_, gotID := rd.GetRedirect("author", id)
+			helpers.AssertEqual(t, 3, gotID)
+		}
+	})
+
+	t.Run("Undone", func(t *testing.T) {
+		// Author 2 has been brought back, and author 3 merged into it.
+		helpers.AssertEqual(t, status.New(status.Created, ""), rd.PostRedirect("author", 3, 2))
+
+		gotStatus, _ := rd.GetRedirect("author", 2)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+		for _, id := range []int{1, 3} {
+			_, gotID := rd.GetRedirect("author", id)
+			helpers.AssertEqual(t, 2, gotID)
+		}
+	})
+
+	cleanup()
+}
@@ -0,0 +1,245 @@
+package shelf
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/lib/pq"
+)
+
+// Columns is the comma-separated list of columns found in the shelf table.
+const Columns string = "user_id, publication_id, exclusive_shelf, rating, date_read, bookshelves"
+
+// Exclusive shelves. Every entry is on exactly one of them.
+const (
+	Read             string = "read"
+	CurrentlyReading string = "currently-reading"
+	ToRead           string = "to-read"
+)
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	DeleteEntry
+	GetShelf
+	PutEntry
+)
+
+// Entry represents a publication on a user's shelves, along with their rating and the date they read it.
+type Entry struct {
+	ID             int                     `json:"id"`
+	UserID         int                     `json:"userId"`
+	Publication    publication.Publication `json:"publication"`
+	ExclusiveShelf string                  `json:"exclusiveShelf"`
+	Rating         int                     `json:"rating"`
+	DateRead       string                  `json:"dateRead"`
+	Bookshelves    []string                `json:"bookshelves"`
+}
+
+// Entries represents a list of shelf entries.
+type Entries []Entry
+
+// Service wraps the database.
+type Service struct {
+	DB postgres.DB
+}
+
+// WithTx returns a copy of the receiver whose statements are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx)}
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case DeleteEntry:
+		return "DELETE FROM shelf WHERE user_id = $1 AND publication_id = $2"
+	case GetShelf:
+		return fmt.Sprintf(
+			`SELECT shelf.id, %s, %s, %s, %s
+                        FROM shelf
+                        JOIN publication ON shelf.publication_id=publication.id
+                        JOIN work ON publication.work_id=work.id
+                        JOIN author ON work.author_id=author.id
+                        WHERE shelf.user_id = $1 AND publication.deleted_at IS NULL
+                        ORDER BY shelf.id`,
+			Columns,
+			publication.Columns,
+			work.Columns,
+			author.Columns,
+		)
+	case PutEntry:
+		return fmt.Sprintf(
+			`INSERT INTO shelf (%s)
+                        VALUES ($1, $2, $3, $4, $5, $6)
+                        ON CONFLICT (user_id, publication_id) DO UPDATE
+                        SET exclusive_shelf = EXCLUDED.exclusive_shelf,
+                            rating = EXCLUDED.rating,
+                            date_read = EXCLUDED.date_read,
+                            bookshelves = EXCLUDED.bookshelves
+                        RETURNING id, %s`,
+			Columns,
+			Columns,
+		)
+	default:
+		return ""
+	}
+}
+
+// DeleteEntry removes the publication matching pubID from the shelves of the user matching userID.
+func (s *Service) DeleteEntry(userID int, pubID int) *status.Status {
+	db := s.DB
+	deleteEntry := s.Query(DeleteEntry)
+
+	res, err := db.Exec(deleteEntry, userID, pubID)
+	if err != nil {
+		log.Printf("[DeleteEntry] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+
+	numDeleted, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[DeleteEntry] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	if numDeleted == 0 {
+		msg := fmt.Sprintf("Publication with id = %d is not on the shelves of user with id = %d", pubID, userID)
+		log.Printf("[DeleteEntry] %s", msg)
+		return status.New(status.OK, msg)
+	}
+	return status.New(status.NoContent, "")
+}
+
+// GetShelf retrieves every entry on the shelves of the user matching the given id, except those of deleted publications.
+func (s *Service) GetShelf(userID int) (*status.Status, Entries) {
+	entries := Entries{}
+	stat := s.StreamShelf(userID, func(entry *Entry) error {
+		entries = append(entries, *entry)
+		return nil
+	})
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	return stat, entries
+}
+
+// StreamShelf calls fn with each entry on the shelves of the user matching the given id, except those of deleted
+// publications, scanning one row at a time instead of loading the whole result set. It stops at the first error fn
+// returns.
+func (s *Service) StreamShelf(userID int, fn func(*Entry) error) *status.Status {
+	db := s.DB
+	getShelf := s.Query(GetShelf)
+
+	rows, err := db.Query(getShelf, userID)
+	if err != nil {
+		log.Printf("[StreamShelf] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := s.getEntry(rows)
+		if err == nil {
+			err = fn(entry)
+		}
+		if err != nil {
+			log.Printf("[StreamShelf] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[StreamShelf] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return status.New(status.OK, "")
+}
+
+// PutEntry creates the given entry, or replaces the user's existing entry for the same publication.
+func (s *Service) PutEntry(entry *Entry) (*status.Status, *Entry) {
+	db := s.DB
+	putEntry := s.Query(PutEntry)
+
+	var dateRead interface{}
+	if entry.DateRead != "" {
+		dateRead = entry.DateRead
+	}
+	bookshelves := entry.Bookshelves
+	if bookshelves == nil {
+		bookshelves = []string{}
+	}
+
+	var e Entry
+	var dr sql.NullString
+	row := db.QueryRow(
+		putEntry,
+		entry.UserID,
+		entry.Publication.ID,
+		entry.ExclusiveShelf,
+		entry.Rating,
+		dateRead,
+		pq.Array(bookshelves),
+	)
+	if err := row.Scan(
+		&e.ID,
+		&e.UserID,
+		&e.Publication.ID,
+		&e.ExclusiveShelf,
+		&e.Rating,
+		&dr,
+		pq.Array(&e.Bookshelves),
+	); err != nil {
+		log.Printf("[PutEntry] %s", err)
+		return status.New(status.UnprocessableEntity, err.Error()), nil
+	}
+	e.DateRead = dr.String
+	return status.New(status.OK, ""), &e
+}
+
+func (s *Service) getEntry(row interface {
+	Scan(dest ...interface{}) error
+}) (*Entry, error) {
+	var e Entry
+	var dr sql.NullString
+	var p *publication.Publication = &e.Publication
+	var w *work.Work = &p.Work
+	var a *author.Author = &w.Author
+	err := row.Scan(
+		&e.ID,
+		&e.UserID,
+		&p.ID,
+		&e.ExclusiveShelf,
+		&e.Rating,
+		&dr,
+		pq.Array(&e.Bookshelves),
+		&p.EditionPubDate,
+		&p.Format,
+		&p.ImageURL,
+		&p.ISBN,
+		&p.ISBN13,
+		&p.Language,
+		&p.NumPages,
+		&p.Publisher,
+		&p.Edition,
+		&w.ID,
+		&w.Description,
+		&w.InitialPubDate,
+		&w.OriginalLanguage,
+		&w.Title,
+		&w.Series,
+		&w.SeriesIndex,
+		&a.ID,
+		&a.FirstName,
+		&a.LastName,
+		&a.Gender,
+		&a.DateOfBirth,
+		&a.PlaceOfBirth.ID,
+	)
+	e.DateRead = dr.String
+	return &e, err
+}
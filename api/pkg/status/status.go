@@ -7,20 +7,30 @@ import (
 
 // List of HTTP status codes.
 const (
-	OK        int = 200
-	Created   int = 201
-	NoContent int = 204
+	OK          int = 200
+	Created     int = 201
+	NoContent   int = 204
+	MultiStatus int = 207
+
+	MovedPermanently int = 301
+	NotModified      int = 304
 
 	BadRequest          int = 400
 	Unauthorized        int = 401
+	Forbidden           int = 403
 	NotFound            int = 404
 	MethodNotAllowed    int = 405
 	Conflict            int = 409
+	Gone                int = 410
+	PreconditionFailed  int = 412
+	PayloadTooLarge     int = 413
 	UnprocessableEntity int = 422
+	FailedDependency    int = 424
 	TooManyRequests     int = 429
 
 	InternalServerError int = 500
 	NotImplemented      int = 501
+	BadGateway          int = 502
 )
 
 // Status contains information about a response, namely the status code and a message.
@@ -0,0 +1,119 @@
+package user
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"log"
+	"strings"
+
+	"github.com/andrewzulaybar/books/api/internal/postgres"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+)
+
+// Enum constants representing types of SQL statements.
+const (
+	Unknown postgres.Query = iota
+	GetCredentials
+)
+
+// A User represents an account that requests are made with.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// AdminActor is who the requests authenticated with the admin token are recorded as made by.
+const AdminActor = "admin"
+
+// An Identity is who a request has been authenticated as: either a user or, with the admin token, an admin.
+type Identity struct {
+	User  *User
+	Admin bool
+}
+
+// Actor returns who the changes made by the receiver are recorded as made by.
+func (id *Identity) Actor() string {
+	if id.Admin {
+		return AdminActor
+	}
+	return id.User.Username
+}
+
+// Service wraps the database.
+type Service struct {
+	DB postgres.DB
+}
+
+// Query returns a SQL statement based on the postgres.Query value passed in.
+func (s *Service) Query(query postgres.Query, args ...interface{}) string {
+	switch query {
+	case GetCredentials:
+		return "SELECT id, username, password FROM account_user WHERE username = $1"
+	default:
+		return ""
+	}
+}
+
+// Authenticate retrieves the user matching the given username, provided that password is theirs. Unknown users
+// and wrong passwords are both reported as unauthorized, so as not to tell which of the two it was.
+func (s *Service) Authenticate(username string, password string) (*status.Status, *User) {
+	db := s.DB
+	getCredentials := s.Query(GetCredentials)
+
+	var u User
+	var want string
+	row := db.QueryRow(getCredentials, username)
+	if err := row.Scan(&u.ID, &u.Username, &want); err != nil {
+		if err == sql.ErrNoRows {
+			return status.New(status.Unauthorized, "Invalid username or password"), nil
+		}
+		log.Printf("[Authenticate] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	if subtle.ConstantTimeCompare([]byte(password), []byte(want)) != 1 {
+		return status.New(status.Unauthorized, "Invalid username or password"), nil
+	}
+	return status.New(status.OK, ""), &u
+}
+
+// Identify authenticates the given credentials, as found in an Authorization header: the username and password of
+// a user, with the Basic scheme, or the admin token, with the Bearer scheme. The admin token is never accepted when
+// adminToken is empty.
+func (s *Service) Identify(credentials string, adminToken string) (*status.Status, *Identity) {
+	if strings.HasPrefix(credentials, "Bearer ") {
+		token := strings.TrimPrefix(credentials, "Bearer ")
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			return status.New(status.Unauthorized, "Invalid admin token"), nil
+		}
+		return status.New(status.OK, ""), &Identity{Admin: true}
+	}
+
+	username, password, ok := basicAuth(credentials)
+	if !ok {
+		return status.New(status.Unauthorized, "The Authorization header must use the Basic or Bearer scheme"), nil
+	}
+	stat, u := s.Authenticate(username, password)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	return stat, &Identity{User: u}
+}
+
+// basicAuth returns the username and password in the given credentials of the Basic scheme, as
+// (*http.Request).BasicAuth does.
+func basicAuth(credentials string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(credentials) < len(prefix) || !strings.EqualFold(credentials[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(credentials[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	i := strings.IndexByte(string(decoded), ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return string(decoded[:i]), string(decoded[i+1:]), true
+}
@@ -0,0 +1,36 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+	"github.com/andrewzulaybar/books/api/pkg/status"
+	"github.com/andrewzulaybar/books/api/pkg/user"
+)
+
+func TestIdentify(t *testing.T) {
+	us := &user.Service{}
+
+	t.Run("AdminToken", func(t *testing.T) {
+		gotStatus, gotIdentity := us.Identify("Bearer token", "token")
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+		helpers.AssertEqual(t, &user.Identity{Admin: true}, gotIdentity)
+		helpers.AssertEqual(t, user.AdminActor, gotIdentity.Actor())
+	})
+
+	t.Run("InvalidAdminToken", func(t *testing.T) {
+		for _, adminToken := range []string{"other", ""} {
+			gotStatus, gotIdentity := us.Identify("Bearer token", adminToken)
+			helpers.AssertEqual(t, status.New(status.Unauthorized, "Invalid admin token"), gotStatus)
+			helpers.AssertNil(t, gotIdentity)
+		}
+	})
+
+	t.Run("UnknownScheme", func(t *testing.T) {
+		for _, credentials := range []string{"Digest username=x", "Basic !!!", "Basic YWRtaW4="} {
+			gotStatus, gotIdentity := us.Identify(credentials, "token")
+			helpers.AssertEqual(t, status.Unauthorized, gotStatus.Code())
+			helpers.AssertNil(t, gotIdentity)
+		}
+	})
+}
@@ -2,29 +2,57 @@ package work
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/andrewzulaybar/books/api/internal/postgres"
 	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/history"
 	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/patch"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/lib/pq"
 )
 
+// Entity is the name that the changes made to works are recorded under in the history.
+const Entity string = "work"
+
+// publicationEntity is publication.Entity, which cannot be used here since the publication package depends on this
+// one.
+const publicationEntity string = "publication"
+
 // Columns is the comma-separated list of columns found in the work table.
-const Columns string = "description, initial_pub_date, original_language, title, author_id"
+const Columns string = "description, initial_pub_date, original_language, title, series, series_index, author_id"
+
+// Fields describes the fields of a work that can be patched.
+var Fields = patch.Fields{
+	"description":      {Column: "description"},
+	"initialPubDate":   {Column: "initial_pub_date", Required: true},
+	"originalLanguage": {Column: "original_language"},
+	"title":            {Column: "title", Required: true},
+	"series":           {Column: "series"},
+	"seriesIndex":      {Column: "series_index"},
+	"author":           {Column: "author_id", Required: true, Embedded: true},
+}
 
 // Enum constants representing types of SQL statements.
 const (
 	Unknown postgres.Query = iota
 	DeleteWork
 	FindWork
+	GetSeries
 	GetWork
 	GetWorks
+	GetWorksByAuthors
+	GetWorksPage
+	LastModified
+	LockWork
 	PatchWork
 	PostWork
+	PurgeWorks
+	RestoreWork
 )
 
 // A Work represents a literary work.
@@ -34,6 +62,8 @@ type Work struct {
 	InitialPubDate   string        `json:"initialPubDate"`
 	OriginalLanguage string        `json:"originalLanguage"`
 	Title            string        `json:"title"`
+	Series           string        `json:"series"`
+	SeriesIndex      float64       `json:"seriesIndex"`
 	Author           author.Author `json:"author"`
 }
 
@@ -47,27 +77,50 @@ type Service struct {
 	AuthorService author.Service
 }
 
+// WithTx returns a copy of the receiver whose statements, and those of its dependencies,
+// are executed inside the given transaction.
+func (s *Service) WithTx(tx *sql.Tx) *Service {
+	return &Service{DB: s.DB.WithTx(tx), AuthorService: *s.AuthorService.WithTx(tx)}
+}
+
+// WithActor returns a copy of the receiver whose changes, and those of its dependencies,
+// are recorded in the history as made by actor.
+func (s *Service) WithActor(actor string) *Service {
+	return &Service{DB: s.DB.WithActor(actor), AuthorService: *s.AuthorService.WithActor(actor)}
+}
+
 // Query returns a SQL statement based on the postgres.Query value passed in.
 func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 	switch query {
 	case DeleteWork:
-		return "DELETE FROM work WHERE id = $1"
+		// The work's publications are deleted along with it, at the same time, so that they can be restored with it.
+		return `WITH deleted AS (
+                            UPDATE work SET deleted_at = now(), updated_at = now()
+                            WHERE id = $1 AND deleted_at IS NULL
+                            RETURNING id
+                        ), publications AS (
+                            UPDATE publication SET deleted_at = now(), updated_at = now()
+                            FROM deleted
+                            WHERE publication.work_id = deleted.id AND publication.deleted_at IS NULL
+                            RETURNING publication.id
+                        )
+                        SELECT (SELECT count(*) FROM deleted), ARRAY(SELECT id FROM publications ORDER BY id)`
 	case FindWork:
-		title := args[0].(string)
-		authorID := args[1].(int)
 		return fmt.Sprintf(
 			`SELECT id, %s
                         FROM work
-                        WHERE title = '%s' AND author_id = %d`,
-			Columns, title, authorID,
+                        WHERE title = $1 AND author_id = $2 AND deleted_at IS NULL`,
+			Columns,
 		)
+	case GetSeries:
+		return "SELECT DISTINCT series FROM work WHERE series <> '' AND deleted_at IS NULL ORDER BY series"
 	case GetWork:
 		return fmt.Sprintf(
 			`SELECT work.id, %s, %s, %s
                         FROM work
                         JOIN author ON work.author_id=author.id
                         JOIN location ON author.place_of_birth=location.id
-                        WHERE work.id = $1`,
+                        WHERE work.id = $1 AND work.deleted_at IS NULL`,
 			Columns,
 			author.Columns,
 			location.Columns,
@@ -77,68 +130,97 @@ func (s *Service) Query(query postgres.Query, args ...interface{}) string {
 			`SELECT work.id, %s, %s, %s
                         FROM work
                         JOIN author ON work.author_id=author.id
-                        JOIN location ON author.place_of_birth=location.id`,
+                        JOIN location ON author.place_of_birth=location.id
+                        WHERE work.deleted_at IS NULL`,
 			Columns,
 			author.Columns,
 			location.Columns,
 		)
+	case GetWorksPage:
+		return postgres.Page(s.Query(GetWorks)+" ORDER BY work.id", args[0].(int), args[1].(int))
+	case GetWorksByAuthors:
+		return fmt.Sprintf(
+			`SELECT work.id, %s, %s, %s
+                        FROM work
+                        JOIN author ON work.author_id=author.id
+                        JOIN location ON author.place_of_birth=location.id
+                        WHERE work.author_id = ANY($1) AND work.deleted_at IS NULL
+                        ORDER BY work.id`,
+			Columns,
+			author.Columns,
+			location.Columns,
+		)
+	case LastModified:
+		return `SELECT max(greatest(work.updated_at, author.updated_at))
+                        FROM work
+                        JOIN author ON work.author_id=author.id
+                        WHERE cardinality($1::integer[]) = 0 OR work.id = ANY($1)`
+	case LockWork:
+		return "SELECT id FROM work WHERE id = $1 FOR UPDATE"
 	case PatchWork:
-		var hasUpdate bool
-		query := "UPDATE work SET"
-		for column, value := range args[0].(map[string]interface{}) {
-			switch value.(type) {
-			case string:
-				if value != "" {
-					query += fmt.Sprintf(" %s = '%s',", column, value)
-					hasUpdate = true
-				}
-			case int:
-				if value != 0 {
-					query += fmt.Sprintf(" %s = %d,", column, value)
-					hasUpdate = true
-				}
-			}
+		values := args[0].(map[string]interface{})
+		if len(values) == 0 {
+			return ""
 		}
-		if hasUpdate {
-			return strings.TrimSuffix(query, ",") + fmt.Sprintf(" WHERE id = $1 RETURNING id, %s", Columns)
-		}
-		return ""
+		return fmt.Sprintf(
+			"UPDATE work SET %s, updated_at = now() WHERE id = $1 AND deleted_at IS NULL RETURNING id, %s",
+			patch.Assignments(values),
+			Columns,
+		)
 	case PostWork:
 		return fmt.Sprintf(
 			`INSERT INTO work (%s)
-                        VALUES ($1, $2, $3, $4, $5)
+                        VALUES ($1, $2, $3, $4, $5, $6, $7)
                         RETURNING id, %s`,
 			Columns,
 			Columns,
 		)
+	case PurgeWorks:
+		return "DELETE FROM work WHERE deleted_at < $1"
+	case RestoreWork:
+		// The publications deleted along with the work are restored with it, and so is its author if it has
+		// been deleted since, so that the work is never left without one.
+		return `WITH deleted AS (
+                            SELECT id, author_id, deleted_at FROM work WHERE id = $1 AND deleted_at IS NOT NULL
+                        ), restored AS (
+                            UPDATE work SET deleted_at = NULL, updated_at = now()
+                            FROM deleted
+                            WHERE work.id = deleted.id
+                            RETURNING work.id
+                        ), publications AS (
+                            UPDATE publication SET deleted_at = NULL, updated_at = now()
+                            FROM deleted
+                            WHERE publication.work_id = deleted.id AND publication.deleted_at = deleted.deleted_at
+                            RETURNING publication.id
+                        ), authors AS (
+                            UPDATE author SET deleted_at = NULL, updated_at = now()
+                            FROM deleted
+                            WHERE author.id = deleted.author_id AND author.deleted_at IS NOT NULL
+                            RETURNING author.id
+                        )
+                        SELECT
+                            (SELECT count(*) FROM restored),
+                            ARRAY(SELECT id FROM publications ORDER BY id),
+                            ARRAY(SELECT id FROM authors)`
 	default:
 		return ""
 	}
 }
 
-// DeleteWork removes the entry in the work table matching the given id.
+// DeleteWork marks the entry in the work table matching the given id, and its publications, as deleted.
+// They are kept until they are purged, and can be restored in the meantime.
 func (s *Service) DeleteWork(id int) *status.Status {
-	db := s.DB
-	deleteWork := s.Query(DeleteWork)
-
-	res, err := db.Exec(deleteWork, id)
-	if err != nil {
-		log.Printf("[DeleteWork] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-
-	numDeleted, err := res.RowsAffected()
-	if err != nil {
-		log.Printf("[DeleteWork] %s", err)
-		return status.New(status.InternalServerError, err.Error())
-	}
-	if numDeleted == 0 {
-		msg := fmt.Sprintf("Work with id = %d does not exist", id)
-		log.Printf("[DeleteWork] %s", msg)
-		return status.Newf(status.OK, msg)
-	}
-
-	return status.New(status.NoContent, "")
+	return s.Locked(id, func(s *Service) *status.Status {
+		_, before := s.GetWork(id)
+		stat, pubIDs := s.deleteWork(id)
+		if stat.Code() != status.NoContent {
+			return stat
+		}
+		if stat := s.recordCascaded(publicationEntity, history.Delete, pubIDs); stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Delete, id, before, nil, stat)
+	})
 }
 
 // DeleteWorks removes the entries in the work table matching the given ids.
@@ -161,12 +243,13 @@ func (s *Service) DeleteWorks(ids []int) (*status.Status, []int) {
 // FindWork retrieves the work from the database matching the given title and authorID.
 func (s *Service) FindWork(title string, authorID int) (*status.Status, *Work) {
 	db := s.DB
-	findWork := s.Query(FindWork, title, authorID)
+	findWork := s.Query(FindWork)
 
 	var wk Work
-	row := db.QueryRow(findWork)
+	row := db.QueryRow(findWork, title, authorID)
 	if err := row.Scan(
-		&wk.ID, &wk.Description, &wk.InitialPubDate, &wk.OriginalLanguage, &wk.Title, &wk.Author.ID,
+		&wk.ID, &wk.Description, &wk.InitialPubDate, &wk.OriginalLanguage, &wk.Title,
+		&wk.Series, &wk.SeriesIndex, &wk.Author.ID,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			msg := fmt.Sprintf("Work ('%s', %d) does not exist", title, authorID)
@@ -179,6 +262,71 @@ func (s *Service) FindWork(title string, authorID int) (*status.Status, *Work) {
 	return status.New(status.OK, ""), &wk
 }
 
+// GetHistory retrieves the revisions of the work matching the given id, oldest first.
+func (s *Service) GetHistory(id int) (*status.Status, history.Revisions) {
+	hs := &history.Service{DB: s.DB}
+	stat, revisions := hs.GetRevisions(Entity, id)
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	if len(revisions) == 0 {
+		if stat, _ := s.GetWork(id); stat.Err() != nil {
+			return stat, nil
+		}
+	}
+	return status.New(status.OK, ""), revisions
+}
+
+// GetRevision retrieves the work matching the given id as it was after the revision matching revisionID.
+func (s *Service) GetRevision(id int, revisionID int) (*status.Status, *Work) {
+	hs := &history.Service{DB: s.DB}
+	stat, revision := hs.GetRevision(revisionID)
+	if stat.Code() == status.NotFound || (revision != nil && (revision.Entity != Entity || revision.EntityID != id)) {
+		msg := fmt.Sprintf("Revision %d of work with id = %d does not exist", revisionID, id)
+		log.Printf("[GetRevision] %s", msg)
+		return status.Newf(status.NotFound, msg), nil
+	}
+	if stat.Err() != nil {
+		return stat, nil
+	}
+	if revision.After == nil {
+		msg := fmt.Sprintf("Revision %d deleted work with id = %d", revisionID, id)
+		log.Printf("[GetRevision] %s", msg)
+		return status.Newf(status.UnprocessableEntity, msg), nil
+	}
+
+	var wo Work
+	if err := json.Unmarshal(revision.After, &wo); err != nil {
+		log.Printf("[GetRevision] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	return status.New(status.OK, ""), &wo
+}
+
+// GetSeries retrieves the names of every series that a work belongs to, in alphabetical order.
+func (s *Service) GetSeries() (*status.Status, []string) {
+	db := s.DB
+	getSeries := s.Query(GetSeries)
+
+	rows, err := db.Query(getSeries)
+	if err != nil {
+		log.Printf("[GetSeries] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	series := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			log.Printf("[GetSeries] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		series = append(series, name)
+	}
+	return status.New(status.OK, ""), series
+}
+
 // GetWork retrieves the work from the database matching the given id.
 func (s *Service) GetWork(id int) (*status.Status, *Work) {
 	db := s.DB
@@ -220,8 +368,246 @@ func (s *Service) GetWorks() (*status.Status, Works) {
 	return status.New(status.OK, ""), works
 }
 
-// PatchWork updates the entry in the database matching work.id with the given attributes.
-func (s *Service) PatchWork(work *Work) (*status.Status, *Work) {
+// GetWorksPage retrieves the works ordered by id, skipping the first offset of them and returning at most limit, or
+// every remaining one if limit is negative.
+func (s *Service) GetWorksPage(limit int, offset int) (*status.Status, Works) {
+	db := s.DB
+	getWorksPage := s.Query(GetWorksPage, limit, offset)
+
+	rows, err := db.Query(getWorksPage)
+	if err != nil {
+		log.Printf("[GetWorksPage] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	works := Works{}
+	for rows.Next() {
+		work, err := s.getWork(rows)
+		if err != nil {
+			log.Printf("[GetWorksPage] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		works = append(works, *work)
+	}
+	return status.New(status.OK, ""), works
+}
+
+// GetWorksByAuthors retrieves the works written by any of the authors matching the given ids, ordered by id.
+func (s *Service) GetWorksByAuthors(authorIDs []int) (*status.Status, Works) {
+	db := s.DB
+	getWorksByAuthors := s.Query(GetWorksByAuthors)
+
+	rows, err := db.Query(getWorksByAuthors, pq.Array(authorIDs))
+	if err != nil {
+		log.Printf("[GetWorksByAuthors] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	defer rows.Close()
+
+	works := Works{}
+	for rows.Next() {
+		work, err := s.getWork(rows)
+		if err != nil {
+			log.Printf("[GetWorksByAuthors] %s", err)
+			return status.New(status.InternalServerError, err.Error()), nil
+		}
+		works = append(works, *work)
+	}
+	return status.New(status.OK, ""), works
+}
+
+// LastModified returns when the works matching the given ids, or every work if none are given, or their
+// authors were last modified.
+func (s *Service) LastModified(ids []int) (*status.Status, time.Time) {
+	db := s.DB
+	lastModified := s.Query(LastModified)
+
+	if ids == nil {
+		ids = []int{}
+	}
+	var modified sql.NullTime
+	if err := db.QueryRow(lastModified, pq.Array(ids)).Scan(&modified); err != nil {
+		log.Printf("[LastModified] %s", err)
+		return status.New(status.InternalServerError, err.Error()), time.Time{}
+	}
+	return status.New(status.OK, ""), modified.Time
+}
+
+// Locked calls fn with a copy of the receiver whose statements are executed inside a transaction in which the
+// work matching the given id is locked, so that it cannot be changed by anyone else until fn returns. The
+// transaction is committed if fn succeeds and rolled back otherwise.
+func (s *Service) Locked(id int, fn func(*Service) *status.Status) *status.Status {
+	return s.Transaction(func(s *Service) *status.Status {
+		if _, err := s.DB.Exec(s.Query(LockWork), id); err != nil {
+			log.Printf("[Locked] %s", err)
+			return status.New(status.InternalServerError, err.Error())
+		}
+		return fn(s)
+	})
+}
+
+// PatchWork updates the entry in the database matching work.id with the given attributes. If the names of fields
+// are given, exactly those fields are updated, even to empty values; otherwise, only the attributes that are not
+// empty are.
+func (s *Service) PatchWork(work *Work, fields ...string) (*status.Status, *Work) {
+	var patched *Work
+	stat := s.Locked(work.ID, func(s *Service) *status.Status {
+		var stat *status.Status
+		_, before := s.GetWork(work.ID)
+		if stat, patched = s.patchWork(work, fields); patched == nil || stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Patch, work.ID, before, patched, stat)
+	})
+	return stat, patched
+}
+
+// PostWork creates an entry in the work table with the given attributes.
+func (s *Service) PostWork(work *Work) (*status.Status, *Work) {
+	var posted *Work
+	stat := s.Transaction(func(s *Service) *status.Status {
+		var stat *status.Status
+		if stat, posted = s.postWork(work); stat.Code() != status.Created {
+			return stat
+		}
+		return s.record(history.Post, posted.ID, nil, posted, stat)
+	})
+	return stat, posted
+}
+
+// PurgeWorks permanently removes the works deleted before the given time, along with their publications and genres,
+// and returns how many works were removed.
+func (s *Service) PurgeWorks(before time.Time) (*status.Status, int) {
+	db := s.DB
+	purgeWorks := s.Query(PurgeWorks)
+
+	res, err := db.Exec(purgeWorks, before)
+	if err != nil {
+		log.Printf("[PurgeWorks] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+
+	numPurged, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("[PurgeWorks] %s", err)
+		return status.New(status.InternalServerError, err.Error()), 0
+	}
+	return status.New(status.OK, ""), int(numPurged)
+}
+
+// RestoreWork restores the deleted work matching the given id, along with the publications deleted with it.
+func (s *Service) RestoreWork(id int) (*status.Status, *Work) {
+	var restored *Work
+	stat := s.Transaction(func(s *Service) *status.Status {
+		stat, pubIDs, authorIDs := s.restoreWork(id)
+		if stat.Err() != nil {
+			return stat
+		}
+		if stat, restored = s.GetWork(id); stat.Err() != nil {
+			return stat
+		}
+		if stat := s.recordCascaded(publicationEntity, history.Restore, pubIDs); stat.Err() != nil {
+			return stat
+		}
+		if stat := s.recordCascaded(author.Entity, history.Restore, authorIDs); stat.Err() != nil {
+			return stat
+		}
+		return s.record(history.Restore, id, nil, restored, stat)
+	})
+	return stat, restored
+}
+
+// Transaction calls fn with a copy of the receiver whose statements are executed inside a transaction, which is
+// committed if fn succeeds and rolled back otherwise, as described by postgres.DB.Transaction. Changes are recorded
+// in the history in the same transaction.
+func (s *Service) Transaction(fn func(*Service) *status.Status) *status.Status {
+	var stat *status.Status
+	if err := s.DB.Transaction(func(tx *sql.Tx) error {
+		stat = fn(s.WithTx(tx))
+		return stat.Err()
+	}); err != nil && (stat == nil || stat.Err() == nil) {
+		log.Printf("[Transaction] %s", err)
+		return status.New(status.InternalServerError, err.Error())
+	}
+	return stat
+}
+
+// deleteWork deletes the work matching the given id along with its publications, and returns their ids.
+func (s *Service) deleteWork(id int) (*status.Status, []int) {
+	db := s.DB
+	deleteWork := s.Query(DeleteWork)
+
+	var numDeleted int
+	var pubIDs pq.Int64Array
+	if err := db.QueryRow(deleteWork, id).Scan(&numDeleted, &pubIDs); err != nil {
+		log.Printf("[DeleteWork] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil
+	}
+	if numDeleted == 0 {
+		msg := fmt.Sprintf("Work with id = %d does not exist", id)
+		log.Printf("[DeleteWork] %s", msg)
+		return status.Newf(status.OK, msg), nil
+	}
+
+	return status.New(status.NoContent, ""), ints(pubIDs)
+}
+
+func (s *Service) getWork(row interface {
+	Scan(dest ...interface{}) error
+}) (*Work, error) {
+	var work Work
+	author := &work.Author
+	location := &author.PlaceOfBirth
+	if err := row.Scan(
+		&work.ID,
+		&work.Description,
+		&work.InitialPubDate,
+		&work.OriginalLanguage,
+		&work.Title,
+		&work.Series,
+		&work.SeriesIndex,
+		&author.ID,
+		&author.FirstName,
+		&author.LastName,
+		&author.Gender,
+		&author.DateOfBirth,
+		&location.ID,
+		&location.City,
+		&location.Country,
+		&location.Region,
+	); err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+func (s *Service) handleAuthor(work *Work) *status.Status {
+	au := &work.Author
+
+	if au.ID != 0 {
+		return status.New(status.OK, "")
+	}
+
+	stat, author := s.AuthorService.PostAuthor(au)
+	if stat.Err() != nil {
+		if stat.Code() != status.Conflict {
+			return status.New(stat.Code(), stat.Message())
+		}
+
+		if stat, author = s.AuthorService.GetAuthor(au.ID); stat.Err() != nil {
+			stat, author = s.AuthorService.FindAuthor(au.FirstName, au.LastName, au.DateOfBirth)
+			if stat.Err() != nil {
+				return status.New(stat.Code(), stat.Message())
+			}
+		}
+	}
+
+	au.ID = author.ID
+	return status.New(status.OK, "")
+}
+
+func (s *Service) patchWork(work *Work, fields []string) (*status.Status, *Work) {
 	if work.Author != (author.Author{}) {
 		if s := s.handleAuthor(work); s.Err() != nil {
 			log.Printf("[PatchWork] %s", s.Err())
@@ -235,19 +621,25 @@ func (s *Service) PatchWork(work *Work) (*status.Status, *Work) {
 		"initial_pub_date":  work.InitialPubDate,
 		"original_language": work.OriginalLanguage,
 		"title":             work.Title,
+		"series":            work.Series,
+		"series_index":      work.SeriesIndex,
 		"author_id":         work.Author.ID,
 	}
 
-	patchWork := s.Query(PatchWork, w)
+	patchWork := s.Query(PatchWork, Fields.Select(w, fields...))
 	if patchWork != "" {
 		var wk Work
 		row := db.QueryRow(patchWork, work.ID)
 		if err := row.Scan(
-			&wk.ID, &wk.Description, &wk.InitialPubDate, &wk.OriginalLanguage, &wk.Title, &wk.Author.ID,
+			&wk.ID, &wk.Description, &wk.InitialPubDate, &wk.OriginalLanguage, &wk.Title,
+			&wk.Series, &wk.SeriesIndex, &wk.Author.ID,
 		); err != nil {
 			if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
 				log.Printf("[PatchWork] %s", err)
 				return status.New(status.Conflict, err.Error()), nil
+			} else if ok && (err.Code.Class() == "22" || err.Code == "23503") {
+				log.Printf("[PatchWork] %s", err)
+				return status.New(status.UnprocessableEntity, err.Error()), nil
 			}
 			log.Printf("[PatchWork] %s", err)
 			return status.New(status.InternalServerError, err.Error()), nil
@@ -257,8 +649,7 @@ func (s *Service) PatchWork(work *Work) (*status.Status, *Work) {
 	return status.New(status.OK, "No fields in work to update"), nil
 }
 
-// PostWork creates an entry in the work table with the given attributes.
-func (s *Service) PostWork(work *Work) (*status.Status, *Work) {
+func (s *Service) postWork(work *Work) (*status.Status, *Work) {
 	if work.ID != 0 {
 		if s, l := s.GetWork(work.ID); s.Code() == status.OK {
 			msg := fmt.Sprintf("Work with id = %d already exists", work.ID)
@@ -284,6 +675,8 @@ func (s *Service) PostWork(work *Work) (*status.Status, *Work) {
 		work.InitialPubDate,
 		work.OriginalLanguage,
 		work.Title,
+		work.Series,
+		work.SeriesIndex,
 		work.Author.ID,
 	)
 	if err := row.Scan(
@@ -292,6 +685,8 @@ func (s *Service) PostWork(work *Work) (*status.Status, *Work) {
 		&wk.InitialPubDate,
 		&wk.OriginalLanguage,
 		&wk.Title,
+		&wk.Series,
+		&wk.SeriesIndex,
 		&wk.Author.ID,
 	); err != nil {
 		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
@@ -304,54 +699,52 @@ func (s *Service) PostWork(work *Work) (*status.Status, *Work) {
 	return status.New(status.Created, ""), &wk
 }
 
-func (s *Service) getWork(row interface {
-	Scan(dest ...interface{}) error
-}) (*Work, error) {
-	var work Work
-	author := &work.Author
-	location := &author.PlaceOfBirth
-	if err := row.Scan(
-		&work.ID,
-		&work.Description,
-		&work.InitialPubDate,
-		&work.OriginalLanguage,
-		&work.Title,
-		&author.ID,
-		&author.FirstName,
-		&author.LastName,
-		&author.Gender,
-		&author.DateOfBirth,
-		&location.ID,
-		&location.City,
-		&location.Country,
-		&location.Region,
-	); err != nil {
-		return nil, err
-	}
-	return &work, nil
+// recordCascaded records the deletion or restoration of the entries of the given entity matching the given ids
+// along with a work, such as its publications.
+func (s *Service) recordCascaded(entity string, action string, ids []int) *status.Status {
+	hs := &history.Service{DB: s.DB}
+	return hs.PostRevisions(entity, ids, action)
 }
 
-func (s *Service) handleAuthor(work *Work) *status.Status {
-	au := &work.Author
-
-	if au.ID != 0 {
-		return status.New(status.OK, "")
+// record records a change made to the work matching the given id in the history, and returns stat, the status
+// of the change, if it succeeds.
+func (s *Service) record(action string, id int, before *Work, after *Work, stat *status.Status) *status.Status {
+	hs := &history.Service{DB: s.DB}
+	if s := hs.PostRevision(Entity, id, action, before, after); s.Err() != nil {
+		return s
 	}
+	return stat
+}
 
-	stat, author := s.AuthorService.PostAuthor(au)
-	if stat.Err() != nil {
-		if stat.Code() != status.Conflict {
-			return status.New(stat.Code(), stat.Message())
-		}
+// restoreWork restores the work matching the given id along with the publications deleted with it and its author,
+// and returns the ids of the publications and of the author if it was restored.
+func (s *Service) restoreWork(id int) (*status.Status, []int, []int) {
+	db := s.DB
+	restoreWork := s.Query(RestoreWork)
 
-		if stat, author = s.AuthorService.GetAuthor(au.ID); stat.Err() != nil {
-			stat, author = s.AuthorService.FindAuthor(au.FirstName, au.LastName, au.DateOfBirth)
-			if stat.Err() != nil {
-				return status.New(stat.Code(), stat.Message())
-			}
+	var numRestored int
+	var pubIDs, authorIDs pq.Int64Array
+	if err := db.QueryRow(restoreWork, id).Scan(&numRestored, &pubIDs, &authorIDs); err != nil {
+		if err, ok := err.(*pq.Error); ok && err.Code == "23505" {
+			log.Printf("[RestoreWork] %s", err)
+			return status.New(status.Conflict, err.Error()), nil, nil
 		}
+		log.Printf("[RestoreWork] %s", err)
+		return status.New(status.InternalServerError, err.Error()), nil, nil
+	}
+	if numRestored == 0 {
+		msg := fmt.Sprintf("Deleted work with id = %d does not exist", id)
+		log.Printf("[RestoreWork] %s", msg)
+		return status.Newf(status.NotFound, msg), nil, nil
 	}
+	return status.New(status.OK, ""), ints(pubIDs), ints(authorIDs)
+}
 
-	au.ID = author.ID
-	return status.New(status.OK, "")
+// ints converts the given ids scanned from an integer array into ints.
+func ints(ids pq.Int64Array) []int {
+	is := make([]int, len(ids))
+	for i, id := range ids {
+		is[i] = int(id)
+	}
+	return is
 }
@@ -2,10 +2,13 @@ package work_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/andrewzulaybar/books/api/internal/test/helpers"
 	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/history"
 	"github.com/andrewzulaybar/books/api/pkg/location"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
 	"github.com/andrewzulaybar/books/api/pkg/status"
 	"github.com/andrewzulaybar/books/api/pkg/work"
 	"github.com/andrewzulaybar/books/api/test/data"
@@ -116,6 +119,83 @@ func TestFindWork(t *testing.T) {
 	cleanup()
 }
 
+func TestGetHistory(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService.WithActor("editor")
+	works := data.GetWorks(ws)
+
+	t.Run("PostPatchDelete", func(t *testing.T) {
+		tw := helpers.PostWork(t, ws, &works[0])
+		description := tw.Description
+		if gotStatus, _ := ws.PatchWork(&work.Work{ID: tw.ID, Description: "Revised"}); gotStatus.Err() != nil {
+			t.Fatalf("err: %s", gotStatus.Err())
+		}
+		helpers.DeleteWork(t, ws, tw.ID)
+
+		gotStatus, gotRevisions := ws.GetHistory(tw.ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, 3, len(gotRevisions))
+		for i, action := range []string{history.Post, history.Patch, history.Delete} {
+			helpers.AssertEqual(t, action, gotRevisions[i].Action)
+			helpers.AssertEqual(t, "editor", gotRevisions[i].Actor)
+		}
+		wantDiff := map[string]history.Change{"description": {Before: description, After: "Revised"}}
+		helpers.AssertEqual(t, wantDiff, gotRevisions[1].Diff)
+	})
+
+	t.Run("NonExistentID", func(t *testing.T) {
+		gotStatus, gotRevisions := ws.GetHistory(-1)
+		wantStatus := status.New(status.NotFound, "Work with id = -1 does not exist")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotRevisions)
+	})
+
+	cleanup()
+}
+
+func TestGetRevision(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	works := data.GetWorks(ws)
+	tw := helpers.PostWork(t, ws, &works[0])
+	if gotStatus, _ := ws.PatchWork(&work.Work{ID: tw.ID, Description: "Revised"}); gotStatus.Err() != nil {
+		t.Fatalf("err: %s", gotStatus.Err())
+	}
+	_, revisions := ws.GetHistory(tw.ID)
+
+	t.Run("Revert", func(t *testing.T) {
+		gotStatus, gotWork := ws.GetRevision(tw.ID, revisions[0].ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, tw, gotWork)
+
+		gotStatus, gotWork = ws.PatchWork(gotWork)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, tw, gotWork)
+	})
+
+	t.Run("OtherWork", func(t *testing.T) {
+		gotStatus, gotWork := ws.GetRevision(-1, revisions[0].ID)
+		wantStatus := status.Newf(status.NotFound, "Revision %d of work with id = -1 does not exist", revisions[0].ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotWork)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		helpers.DeleteWork(t, ws, tw.ID)
+		_, revisions := ws.GetHistory(tw.ID)
+		deleted := revisions[len(revisions)-1].ID
+
+		gotStatus, gotWork := ws.GetRevision(tw.ID, deleted)
+		wantStatus := status.Newf(status.UnprocessableEntity, "Revision %d deleted work with id = %d", deleted, tw.ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotWork)
+	})
+
+	cleanup()
+}
+
 func TestGetWork(t *testing.T) {
 	services, cleanup := helpers.Setup(t)
 	ws := services.WorkService
@@ -157,6 +237,114 @@ func TestGetWorks(t *testing.T) {
 	cleanup()
 }
 
+func TestGetSeries(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	tws := data.GetWorks(ws)
+	tws[0].Series, tws[0].SeriesIndex = "Trilogy", 1
+	tws[1].Series, tws[1].SeriesIndex = "Trilogy", 2
+	tws[2].Series, tws[2].SeriesIndex = "Duology", 1
+	helpers.PostWorks(t, ws, tws)
+
+	t.Run("DistinctNames", func(t *testing.T) {
+		gotStatus, gotSeries := ws.GetSeries()
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, []string{"Duology", "Trilogy"}, gotSeries)
+	})
+
+	cleanup()
+}
+
+func TestGetWorksByAuthors(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	tws := helpers.PostWorks(t, ws, data.GetWorks(ws))
+
+	t.Run("ManyAuthors", func(t *testing.T) {
+		gotStatus, gotWorks := ws.GetWorksByAuthors([]int{tws[3].Author.ID, tws[0].Author.ID})
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, 2, len(gotWorks))
+		helpers.AssertEqual(t, tws[0], gotWorks[0])
+		helpers.AssertEqual(t, tws[3], gotWorks[1])
+	})
+
+	t.Run("NoWorks", func(t *testing.T) {
+		gotStatus, gotWorks := ws.GetWorksByAuthors([]int{-1})
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, work.Works{}, gotWorks)
+	})
+
+	cleanup()
+}
+
+func TestLastModified(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	tws := helpers.PostWorks(t, ws, data.GetWorks(ws))
+
+	_, before := ws.LastModified([]int{tws[0].ID})
+
+	t.Run("Patched", func(t *testing.T) {
+		helpers.AssertEqual(t, false, before.IsZero())
+		ws.PatchWork(&work.Work{ID: tws[0].ID, Description: "This is a test description..."})
+
+		gotStatus, gotModified := ws.LastModified([]int{tws[0].ID})
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, true, gotModified.After(before))
+	})
+
+	t.Run("AllWorks", func(t *testing.T) {
+		_, one := ws.LastModified([]int{tws[0].ID})
+		gotStatus, gotModified := ws.LastModified(nil)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, one, gotModified)
+	})
+
+	t.Run("NonExistentID", func(t *testing.T) {
+		gotStatus, gotModified := ws.LastModified([]int{-1})
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, true, gotModified.IsZero())
+	})
+
+	cleanup()
+}
+
+func TestLocked(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	tws := helpers.PostWorks(t, ws, data.GetWorks(ws))
+
+	t.Run("Committed", func(t *testing.T) {
+		gotStatus := ws.Locked(tws[0].ID, func(ws *work.Service) *status.Status {
+			s, _ := ws.PatchWork(&work.Work{ID: tws[0].ID, Description: "Committed"})
+			return s
+		})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+
+		_, gotWork := ws.GetWork(tws[0].ID)
+		helpers.AssertEqual(t, "Committed", gotWork.Description)
+	})
+
+	t.Run("RolledBack", func(t *testing.T) {
+		gotStatus := ws.Locked(tws[1].ID, func(ws *work.Service) *status.Status {
+			ws.PatchWork(&work.Work{ID: tws[1].ID, Description: "Rolled back"})
+			return status.New(status.PreconditionFailed, "")
+		})
+		helpers.AssertEqual(t, status.New(status.PreconditionFailed, ""), gotStatus)
+
+		_, gotWork := ws.GetWork(tws[1].ID)
+		helpers.AssertEqual(t, tws[1].Description, gotWork.Description)
+	})
+
+	cleanup()
+}
+
 func TestPatchWork(t *testing.T) {
 	services, cleanup := helpers.Setup(t)
 	ws := services.WorkService
@@ -277,6 +465,41 @@ func TestPatchWork(t *testing.T) {
 		})
 	})
 
+	t.Run("Fields", func(t *testing.T) {
+		tw := helpers.PostWork(t, ws, &works[0])
+		defer helpers.DeleteWork(t, ws, tw.ID)
+		ws.PatchWork(&work.Work{ID: tw.ID, Series: "Testing", SeriesIndex: 2})
+
+		t.Run("ZeroValues", func(t *testing.T) {
+			updates := *tw
+			updates.Series, updates.SeriesIndex = "", 0
+
+			gotStatus, gotWork := ws.PatchWork(&updates, "series", "seriesIndex")
+			wantStatus := status.New(status.OK, "")
+			tw.Series, tw.SeriesIndex = "", 0
+			helpers.AssertEqual(t, wantStatus, gotStatus)
+			helpers.AssertEqual(t, tw, gotWork)
+		})
+
+		t.Run("OnlyNamed", func(t *testing.T) {
+			updates := work.Work{ID: tw.ID, Title: "Testing"}
+
+			gotStatus, gotWork := ws.PatchWork(&updates, "title")
+			wantStatus := status.New(status.OK, "")
+			tw.Title = "Testing"
+			helpers.AssertEqual(t, wantStatus, gotStatus)
+			helpers.AssertEqual(t, tw, gotWork)
+		})
+
+		t.Run("InvalidDate", func(t *testing.T) {
+			updates := work.Work{ID: tw.ID}
+
+			gotStatus, gotWork := ws.PatchWork(&updates, "initialPubDate")
+			helpers.AssertEqual(t, status.UnprocessableEntity, gotStatus.Code())
+			helpers.AssertNil(t, gotWork)
+		})
+	})
+
 	t.Run("DuplicateAuthorTitle", func(t *testing.T) {
 		tw := helpers.PostWork(t, ws, &works[0])
 		defer helpers.DeleteWork(t, ws, tw.ID)
@@ -371,3 +594,133 @@ func TestPostWork(t *testing.T) {
 
 	cleanup()
 }
+
+func TestPurgeWorks(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	ws := services.WorkService
+	pubs := helpers.PostPublications(t, ps, data.GetPublications(ps))
+	helpers.DeleteWork(t, ws, pubs[0].Work.ID)
+
+	t.Run("WithinRetention", func(t *testing.T) {
+		gotStatus, gotPurged := ws.PurgeWorks(time.Now().Add(-time.Hour))
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, 0, gotPurged)
+	})
+
+	t.Run("PastRetention", func(t *testing.T) {
+		gotStatus, gotPurged := ws.PurgeWorks(time.Now().Add(time.Hour))
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, 1, gotPurged)
+
+		gotStatus, _ = ws.RestoreWork(pubs[0].Work.ID)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+		gotStatus, _ = ps.RestorePublication(pubs[0].ID)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+	})
+
+	cleanup()
+}
+
+func TestRestoreWork(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ps := services.PublicationService
+	ws := services.WorkService
+	pubs := helpers.PostPublications(t, ps, data.GetPublications(ps))
+
+	t.Run("WithPublications", func(t *testing.T) {
+		_, wk := ws.GetWork(pubs[0].Work.ID)
+		helpers.DeleteWork(t, ws, wk.ID)
+		gotStatus, _ := ps.GetPublication(pubs[0].ID)
+		helpers.AssertEqual(t, status.NotFound, gotStatus.Code())
+
+		gotStatus, gotWork := ws.RestoreWork(wk.ID)
+		wantStatus := status.New(status.OK, "")
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, wk, gotWork)
+
+		gotStatus, gotPublication := ps.GetPublication(pubs[0].ID)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertEqual(t, &pubs[0], gotPublication)
+
+		hs := &history.Service{DB: ps.DB}
+		_, revisions := hs.GetRevisions(publication.Entity, pubs[0].ID)
+		n := len(revisions)
+		helpers.AssertEqual(t, history.Delete, revisions[n-2].Action)
+		helpers.AssertEqual(t, history.Restore, revisions[n-1].Action)
+		helpers.AssertEqual(t, string(revisions[n-2].Before), string(revisions[n-1].After))
+	})
+
+	t.Run("NotDeleted", func(t *testing.T) {
+		id := pubs[0].Work.ID
+		gotStatus, gotWork := ws.RestoreWork(id)
+		wantStatus := status.Newf(status.NotFound, "Deleted work with id = %d does not exist", id)
+		helpers.AssertEqual(t, wantStatus, gotStatus)
+		helpers.AssertNil(t, gotWork)
+	})
+
+	t.Run("DeletedAuthor", func(t *testing.T) {
+		_, wk := ws.GetWork(pubs[2].Work.ID)
+		helpers.DeleteWork(t, ws, wk.ID)
+		helpers.DeleteAuthor(t, &ws.AuthorService, wk.Author.ID)
+
+		gotStatus, _ := ws.RestoreWork(wk.ID)
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+
+		_, revisions := ws.AuthorService.GetHistory(wk.Author.ID)
+		n := len(revisions)
+		helpers.AssertEqual(t, history.Restore, revisions[n-1].Action)
+		helpers.AssertEqual(t, string(revisions[n-2].Before), string(revisions[n-1].After))
+	})
+
+	t.Run("TakenTitle", func(t *testing.T) {
+		_, wk := ws.GetWork(pubs[1].Work.ID)
+		helpers.DeleteWork(t, ws, wk.ID)
+		dup := *wk
+		dup.ID = 0
+		helpers.PostWork(t, ws, &dup)
+
+		gotStatus, gotWork := ws.RestoreWork(wk.ID)
+		helpers.AssertEqual(t, status.Conflict, gotStatus.Code())
+		helpers.AssertNil(t, gotWork)
+	})
+
+	cleanup()
+}
+
+func TestTransaction(t *testing.T) {
+	services, cleanup := helpers.Setup(t)
+	ws := services.WorkService
+	tws := helpers.PostWorks(t, ws, data.GetWorks(ws))
+
+	t.Run("Committed", func(t *testing.T) {
+		gotStatus := ws.Transaction(func(ws *work.Service) *status.Status {
+			ws.PatchWork(&work.Work{ID: tws[0].ID, Description: "Committed"})
+			s, _ := ws.PatchWork(&work.Work{ID: tws[1].ID, Description: "Committed"})
+			return s
+		})
+		helpers.AssertEqual(t, status.New(status.OK, ""), gotStatus)
+
+		for _, tw := range tws[:2] {
+			_, gotWork := ws.GetWork(tw.ID)
+			helpers.AssertEqual(t, "Committed", gotWork.Description)
+		}
+	})
+
+	t.Run("RolledBack", func(t *testing.T) {
+		gotStatus := ws.Transaction(func(ws *work.Service) *status.Status {
+			ws.PatchWork(&work.Work{ID: tws[2].ID, Description: "Rolled back"})
+			// The second patch conflicts with the title and author of the third work.
+			s, _ := ws.PatchWork(&work.Work{ID: tws[3].ID, Title: tws[2].Title, Author: tws[2].Author})
+			return s
+		})
+		helpers.AssertEqual(t, status.Conflict, gotStatus.Code())
+
+		_, gotWork := ws.GetWork(tws[2].ID)
+		helpers.AssertEqual(t, tws[2].Description, gotWork.Description)
+	})
+
+	cleanup()
+}
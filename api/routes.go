@@ -0,0 +1,171 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/andrewzulaybar/books/api/pkg/audit"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/pkg/handlers"
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/user"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/gorilla/mux"
+)
+
+// services holds the services that requests are handled with.
+type services struct {
+	author       *author.Service
+	work         *work.Service
+	publication  *publication.Service
+	genre        *genre.Service
+	shelf        *shelf.Service
+	importer     *importer.Service
+	graph        *graph.Service
+	metadata     *metadata.Service
+	audit        *audit.Service
+	idempotency  *idempotency.Service
+	dedup        *dedup.Service
+	redirect     *redirect.Service
+	bibliography *bibliography.Service
+	user         *user.Service
+
+	// adminToken is the bearer token that admins authenticate with; admin routes are disabled when it is empty.
+	adminToken string
+}
+
+// Versions of the REST API. v1 is deprecated in favour of v2, whose errors are JSON objects.
+var (
+	v2 = &handlers.Version{Name: "v2", StructuredErrors: true}
+	v1 = &handlers.Version{
+		Name:        "v1",
+		Deprecation: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset:      time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC),
+		Successor:   v2,
+	}
+)
+
+// newRouter returns a router with every route of the API. Each route must also be described in handlers.Spec.
+func newRouter(s *services) *mux.Router {
+	a, w, p, g, gq := s.author, s.work, s.publication, s.genre, s.graph
+
+	r := mux.NewRouter()
+	// The versioned prefixes come first, since /api would match them too. /api is an alias of v1,
+	// kept for clients from before the API was versioned.
+	for _, mount := range []struct {
+		prefix  string
+		version *handlers.Version
+	}{{v1.Prefix(), v1}, {v2.Prefix(), v2}, {"/api", v1}} {
+		API := r.PathPrefix(mount.prefix).Subrouter()
+		API.Use(mount.version.Middleware, handlers.Authenticated(s.user, s.adminToken), handlers.Audited(s.audit),
+			handlers.Idempotent(s.idempotency))
+		apiRoutes(API, s, mount.version)
+	}
+
+	r.HandleFunc("/graphql", handlers.GraphQL(gq)).
+		Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/opds", handlers.OPDSRoot()).
+		Methods(http.MethodGet)
+	r.HandleFunc("/opds/authors", handlers.OPDSAuthors(a)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/opds/series", handlers.OPDSSeries(w)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/opds/genres", handlers.OPDSGenres(g)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/opds/publications", handlers.OPDSPublications(p)).
+		Methods(http.MethodGet)
+	r.HandleFunc("/opds/opensearch.xml", handlers.OPDSSearch()).
+		Methods(http.MethodGet)
+
+	return r
+}
+
+// apiRoutes registers the routes of the given version of the REST API on API, the subrouter it is served under.
+func apiRoutes(API *mux.Router, s *services, v *handlers.Version) {
+	a, w, p := s.author, s.work, s.publication
+	sh, i, m, d, rd, b := s.shelf, s.importer, s.metadata, s.dedup, s.redirect, s.bibliography
+
+	API.HandleFunc("/publication", handlers.Publications(p)).
+		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	API.HandleFunc("/publication/enrich", handlers.Enrich(m)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/publication/cite", handlers.CiteBatch(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/publication/batch", handlers.PublicationBatch(p)).
+		Methods(http.MethodPost, http.MethodPatch)
+	API.HandleFunc("/publication/{id:[0-9]+}", handlers.Publication(p, rd)).
+		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
+	API.HandleFunc("/publication/{id:[0-9]+}/restore", handlers.RestorePublication(p)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/publication/{id:[0-9]+}/marc", handlers.PublicationMARC(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/publication/{id:[0-9]+}/cite", handlers.Cite(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/work", handlers.Works(w)).
+		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	API.HandleFunc("/work/batch", handlers.WorkBatch(w)).
+		Methods(http.MethodPost, http.MethodPatch)
+	API.HandleFunc("/work/duplicates", handlers.AdminOnly(s.adminToken, handlers.WorkDuplicates(d))).
+		Methods(http.MethodGet)
+	API.HandleFunc("/work/{id:[0-9]+}", handlers.Work(w, p, rd)).
+		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
+	API.HandleFunc("/work/{id:[0-9]+}/restore", handlers.RestoreWork(w)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/work/{id:[0-9]+}/history", handlers.WorkHistory(w)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/work/{id:[0-9]+}/merge", handlers.AdminOnly(s.adminToken, handlers.MergeWork(d))).
+		Methods(http.MethodPost)
+	API.HandleFunc("/work/{id:[0-9]+}/publications", handlers.WorkPublications(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/author", handlers.Authors(a)).
+		Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
+	API.HandleFunc("/author/batch", handlers.AuthorBatch(a)).
+		Methods(http.MethodPost, http.MethodPatch)
+	API.HandleFunc("/author/duplicates", handlers.AdminOnly(s.adminToken, handlers.AuthorDuplicates(d))).
+		Methods(http.MethodGet)
+	API.HandleFunc("/author/{id:[0-9]+}", handlers.Author(a, rd)).
+		Methods(http.MethodGet, http.MethodPatch, http.MethodDelete)
+	API.HandleFunc("/author/{id:[0-9]+}/restore", handlers.RestoreAuthor(a)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/author/{id:[0-9]+}/history", handlers.AuthorHistory(a)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/author/{id:[0-9]+}/merge", handlers.AdminOnly(s.adminToken, handlers.MergeAuthor(d))).
+		Methods(http.MethodPost)
+	API.HandleFunc("/author/{id:[0-9]+}/bibliography", handlers.AuthorBibliography(b)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/author/{id:[0-9]+}/aliases", handlers.AuthorAliases(a)).
+		Methods(http.MethodGet, http.MethodPost)
+	API.HandleFunc("/author/{id:[0-9]+}/aliases/{aliasId:[0-9]+}", handlers.AuthorAlias(a)).
+		Methods(http.MethodDelete)
+	API.HandleFunc("/user/{id:[0-9]+}/shelf", handlers.Shelf(sh)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/import/csv", handlers.ImportCSV(i)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/import/goodreads", handlers.ImportGoodreads(i)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/import/marc", handlers.ImportMARC(i)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/import/onix", handlers.ImportONIX(i)).
+		Methods(http.MethodPost)
+	API.HandleFunc("/export/marc", handlers.ExportMARC(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/export/publications", handlers.ExportPublications(p)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/export/shelf", handlers.ExportShelf(sh)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/admin/audit", handlers.AdminOnly(s.adminToken, handlers.Audit(s.audit))).
+		Methods(http.MethodGet)
+	API.HandleFunc("/openapi.json", handlers.OpenAPI(v)).
+		Methods(http.MethodGet)
+	API.HandleFunc("/docs", handlers.SwaggerUI(v)).
+		Methods(http.MethodGet)
+}
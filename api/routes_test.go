@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/andrewzulaybar/books/api/internal/test/helpers"
+
+	"github.com/andrewzulaybar/books/api/pkg/audit"
+	"github.com/andrewzulaybar/books/api/pkg/author"
+	"github.com/andrewzulaybar/books/api/pkg/bibliography"
+	"github.com/andrewzulaybar/books/api/pkg/dedup"
+	"github.com/andrewzulaybar/books/api/pkg/genre"
+	"github.com/andrewzulaybar/books/api/pkg/graph"
+	"github.com/andrewzulaybar/books/api/pkg/handlers"
+	"github.com/andrewzulaybar/books/api/pkg/idempotency"
+	"github.com/andrewzulaybar/books/api/pkg/importer"
+	"github.com/andrewzulaybar/books/api/pkg/metadata"
+	"github.com/andrewzulaybar/books/api/pkg/openapi"
+	"github.com/andrewzulaybar/books/api/pkg/publication"
+	"github.com/andrewzulaybar/books/api/pkg/redirect"
+	"github.com/andrewzulaybar/books/api/pkg/shelf"
+	"github.com/andrewzulaybar/books/api/pkg/user"
+	"github.com/andrewzulaybar/books/api/pkg/work"
+	"github.com/gorilla/mux"
+)
+
+// variable matches a path variable with a pattern, e.g. {id:[0-9]+}, which OpenAPI writes {id}.
+var variable = regexp.MustCompile(`\{(\w+):[^}]*\}`)
+
+// TestRoutesInSpec fails when a route is registered without being described in the OpenAPI document.
+func TestRoutesInSpec(t *testing.T) {
+	r := newTestRouter()
+	specs := map[*handlers.Version]*openapi.Document{v1: handlers.Spec(v1), v2: handlers.Spec(v2)}
+
+	var n int
+	err := r.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Routes without methods, such as the /api prefix, only group other routes.
+			return nil
+		}
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+
+		path := variable.ReplaceAllString(template, "{$1}")
+		spec := specs[v1]
+		switch {
+		case strings.HasPrefix(path, v2.Prefix()+"/"):
+			spec = specs[v2]
+		case strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, v1.Prefix()+"/"):
+			// The /api alias is described by the paths of v1.
+			path = v1.Prefix() + strings.TrimPrefix(path, "/api")
+		}
+		for _, method := range methods {
+			n++
+			if spec.Operation(method, path) == nil {
+				t.Errorf("%s %s is not in the OpenAPI document", method, template)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n == 0 {
+		t.Fatal("no routes were registered")
+	}
+}
+
+func TestVersions(t *testing.T) {
+	r := newTestRouter()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("Deprecated", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/openapi.json", "/api/openapi.json"} {
+			w := get(path)
+			helpers.AssertEqual(t, http.StatusOK, w.Code)
+			helpers.AssertEqual(t, "@1792368000", w.Header().Get("Deprecation"))
+			helpers.AssertEqual(t, "Fri, 30 Apr 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+			helpers.AssertEqual(t, `</api/v2/openapi.json>; rel="successor-version"`, w.Header().Get("Link"))
+		}
+	})
+
+	t.Run("Current", func(t *testing.T) {
+		w := get("/api/v2/openapi.json")
+		helpers.AssertEqual(t, http.StatusOK, w.Code)
+		helpers.AssertEqual(t, "", w.Header().Get("Deprecation"))
+		helpers.AssertEqual(t, "", w.Header().Get("Sunset"))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		// The filter is rejected before the database would be queried.
+		w := get("/api/v1/publication/cite?author=x")
+		helpers.AssertEqual(t, http.StatusBadRequest, w.Code)
+		helpers.AssertEqual(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+
+		w = get("/api/v2/publication/cite?author=x")
+		helpers.AssertEqual(t, http.StatusBadRequest, w.Code)
+		helpers.AssertEqual(t, "application/json", w.Header().Get("Content-Type"))
+
+		var body handlers.Error
+		helpers.AssertEqual(t, nil, json.Unmarshal(w.Body.Bytes(), &body))
+		helpers.AssertEqual(t, http.StatusBadRequest, body.Status)
+		helpers.AssertEqual(t, "Bad Request", body.Error)
+		helpers.AssertEqual(t, true, body.Message != "")
+	})
+
+	t.Run("Spec", func(t *testing.T) {
+		var doc openapi.Document
+		helpers.AssertEqual(t, nil, json.Unmarshal(get("/api/openapi.json").Body.Bytes(), &doc))
+		helpers.AssertEqual(t, "1.0.0", doc.Info.Version)
+		helpers.AssertEqual(t, true, doc.Operation(http.MethodGet, "/api/v1/work").Deprecated)
+	})
+}
+
+func TestAdmin(t *testing.T) {
+	r := newTestRouter()
+	get := func(path string, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		for _, path := range []string{"/api/v2/admin/audit", "/api/v2/work/duplicates", "/api/v2/author/duplicates"} {
+			for _, authorization := range []string{"", "Bearer wrong", "token"} {
+				w := get(path, authorization)
+				helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+				helpers.AssertEqual(t, true, challenges(w, `Bearer realm="admin"`))
+			}
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v2/work/1/merge", strings.NewReader(`{"ids":[2]}`)))
+		helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+		req.Header.Set("Authorization", "Bearer ")
+		handlers.AdminOnly("", handlers.Audit(&audit.Service{}))(w, req)
+		helpers.AssertEqual(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestAuthenticated(t *testing.T) {
+	r := newTestRouter()
+	do := func(method string, path string, authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Anonymous", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v2/openapi.json", "")
+		helpers.AssertEqual(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		for method, path := range map[string]string{
+			http.MethodPost:   "/api/v2/publication",
+			http.MethodPatch:  "/api/v2/publication/1",
+			http.MethodDelete: "/api/v2/work/1",
+		} {
+			w := do(method, path, "")
+			helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+			helpers.AssertEqual(t, true, challenges(w, `Basic realm="books"`))
+		}
+		for _, authorization := range []string{"Bearer wrong", "Digest username=x", "Basic !!!"} {
+			w := do(http.MethodGet, "/api/v2/openapi.json", authorization)
+			helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+		}
+	})
+
+	t.Run("Shelves", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v2/export/shelf?user=1", "")
+		helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+
+		w = do(http.MethodGet, "/api/v2/user/1/shelf", "")
+		helpers.AssertEqual(t, http.StatusUnauthorized, w.Code)
+
+		w = do(http.MethodGet, "/api/v2/export/shelf", "Bearer token")
+		helpers.AssertEqual(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		w := do(http.MethodGet, "/api/v2/openapi.json", "Bearer token")
+		helpers.AssertEqual(t, http.StatusOK, w.Code)
+	})
+}
+
+// challenges returns whether the response asks for credentials with the given challenge.
+func challenges(w *httptest.ResponseRecorder, challenge string) bool {
+	for _, v := range w.Header().Values("WWW-Authenticate") {
+		if v == challenge {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestRouter returns the router with services that are never called, since no requests reach the database.
+func newTestRouter() *mux.Router {
+	return newRouter(&services{
+		author:       &author.Service{},
+		work:         &work.Service{},
+		publication:  &publication.Service{},
+		genre:        &genre.Service{},
+		shelf:        &shelf.Service{},
+		importer:     &importer.Service{},
+		graph:        &graph.Service{},
+		metadata:     &metadata.Service{},
+		audit:        &audit.Service{},
+		idempotency:  &idempotency.Service{},
+		dedup:        &dedup.Service{},
+		redirect:     &redirect.Service{},
+		bibliography: &bibliography.Service{},
+		user:         &user.Service{},
+		adminToken:   "token",
+	})
+}